@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// AgentKeyHeader carries a print agent's key on its requests
+const AgentKeyHeader = "X-Print-Agent-Key"
+
+const AgentKeyContextKey contextKey = "print_agent_key"
+
+// NewAgentKey returns a random key for a print agent
+func NewAgentKey() (string, error) {
+	key, err := randomKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate agent key: %w", err)
+	}
+
+	return key, nil
+}
+
+// HashAgentKey returns the form an agent key is stored and looked up in
+func HashAgentKey(key string) string {
+	return hashKey(key)
+}
+
+func withAgentKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, AgentKeyContextKey, key)
+}
+
+// GetAgentKeyFromContext retrieves the print agent key sent with the request
+func GetAgentKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(AgentKeyContextKey).(string)
+	return key
+}
@@ -21,26 +21,56 @@ var rolePermissions = map[string]RolePermissions{
 			"categories":  {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"products":    {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"orders":      {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"cancel":      {Methods: map[string]bool{"POST": true}},
 			"staff":       {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"settings":    {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"ratings":     {Methods: map[string]bool{"GET": true}},
+			"taxes":       {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"tables":      {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"tabs":        {Methods: map[string]bool{"GET": true, "POST": true}},
+			"audit":       {Methods: map[string]bool{"GET": true}},
+			"payments":    {Methods: map[string]bool{"GET": true, "POST": true}},
+			"stations":    {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"duty":        {Methods: map[string]bool{"GET": true, "POST": true}},
+			"timesheet":   {Methods: map[string]bool{"GET": true}},
+			"reports":     {Methods: map[string]bool{"GET": true}},
+			"print-jobs":  {Methods: map[string]bool{"GET": true, "POST": true}},
+			"agents":      {Methods: map[string]bool{"GET": true, "POST": true, "DELETE": true}},
+			"outbox":      {Methods: map[string]bool{"GET": true, "POST": true}},
 		},
 	},
 	"chef": {
 		Resources: map[string]Permission{
-			"orders": {Methods: map[string]bool{"GET": true, "PUT": true}},
+			"orders":   {Methods: map[string]bool{"GET": true, "PUT": true}},
+			"tabs":     {Methods: map[string]bool{"GET": true}},
+			"stations": {Methods: map[string]bool{"GET": true}},
+			"duty":     {Methods: map[string]bool{"GET": true, "POST": true}},
 		},
 	},
 	"superuser": {
 		Resources: map[string]Permission{
 			"restaurants-global": {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true}},
+			"platform-users":     {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"audit-global":       {Methods: map[string]bool{"GET": true}},
 			"restaurants":        {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"categories":         {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"products":           {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"orders":             {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"cancel":             {Methods: map[string]bool{"POST": true}},
 			"staff":              {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"settings":           {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
 			"ratings":            {Methods: map[string]bool{"GET": true}},
+			"taxes":              {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"tables":             {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"tabs":               {Methods: map[string]bool{"GET": true, "POST": true}},
+			"audit":              {Methods: map[string]bool{"GET": true}},
+			"payments":           {Methods: map[string]bool{"GET": true, "POST": true}},
+			"stations":           {Methods: map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true}},
+			"timesheet":          {Methods: map[string]bool{"GET": true}},
+			"reports":            {Methods: map[string]bool{"GET": true}},
+			"print-jobs":         {Methods: map[string]bool{"GET": true, "POST": true}},
+			"agents":             {Methods: map[string]bool{"GET": true, "POST": true, "DELETE": true}},
+			"outbox":             {Methods: map[string]bool{"GET": true, "POST": true}},
 		},
 	},
 }
@@ -52,15 +82,31 @@ var resourcePatterns = []struct {
 	restIDIdx int // capture group index for restaurant ID (0 means no restaurant scope)
 }{
 	{regexp.MustCompile(`^/restaurants/(\d+)/categories(?:/\d+)?$`), "categories", 1},
-	{regexp.MustCompile(`^/restaurants/(\d+)/products(?:/\d+)?$`), "products", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/products(?:/\d+(?:/option-groups(?:/\d+)?)?)?$`), "products", 1},
 	{regexp.MustCompile(`^/restaurants/(\d+)/orders/\d+/rating$`), "ratings", 1},
-	{regexp.MustCompile(`^/restaurants/(\d+)/orders(?:/\d+)?$`), "orders", 1},
-	{regexp.MustCompile(`^/restaurants/(\d+)/ratings$`), "ratings", 1},
-	{regexp.MustCompile(`^/restaurants/(\d+)/staff(?:/\d+)?$`), "staff", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/orders/\d+/payments(?:/refund)?$`), "payments", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/orders/\d+/cancel$`), "cancel", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/orders(?:/\d+(?:/items/\d+|/ticket)?)?$`), "orders", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/events$`), "orders", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/ratings(?:/summary)?$`), "ratings", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/staff(?:/\d+(?:/unlock|/shifts)?)?$`), "staff", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/duty(?:/clock-in|/clock-out|/break/start|/break/end)?$`), "duty", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/timesheet$`), "timesheet", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/reports/eta$`), "reports", 1},
 	{regexp.MustCompile(`^/restaurants/(\d+)/settings(?:/[^/]+)?$`), "settings", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/taxes(?:/\d+)?$`), "taxes", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/tables(?:/\d+(?:/qr)?)?$`), "tables", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/tabs(?:/\d+(?:/bill|/split|/close)?)?$`), "tabs", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/print-jobs(?:/\d+/retry)?$`), "print-jobs", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/print-agents(?:/\d+)?$`), "agents", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/notifications(?:/\d+/retry)?$`), "outbox", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/stations(?:/\d+(?:/chefs|/tickets)?)?$`), "stations", 1},
+	{regexp.MustCompile(`^/restaurants/(\d+)/audit$`), "audit", 1},
 	{regexp.MustCompile(`^/restaurants/(\d+)$`), "restaurants", 1},
 	{regexp.MustCompile(`^/restaurants$`), "restaurants-global", 0},
 	{regexp.MustCompile(`^/auth/`), "auth", 0},
+	{regexp.MustCompile(`^/superuser/users(?:/\d+(?:/totp(?:/verify)?)?)?$`), "platform-users", 0},
+	{regexp.MustCompile(`^/superuser/audit$`), "audit-global", 0},
 	{regexp.MustCompile(`^/superuser/`), "superuser-auth", 0},
 }
 
@@ -123,6 +169,8 @@ func checkAuthorization(claims *Claims, method, path string) (bool, string) {
 
 func (m *Middleware) HandlerWithAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = m.withRequestValues(r)
+
 		// Skip auth for public paths
 		if m.isPublicPath(r.Method, r.URL.Path) {
 			next.ServeHTTP(w, r)
@@ -130,7 +178,7 @@ func (m *Middleware) HandlerWithAuth(next http.Handler) http.Handler {
 		}
 
 		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
+		authHeader := authorizationHeader(r)
 		if authHeader == "" {
 			http.Error(w, `{"error":"missing authorization header"}`, http.StatusUnauthorized)
 			return
@@ -155,6 +203,11 @@ func (m *Middleware) HandlerWithAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		// Reject logged-out or revoked sessions
+		if !m.checkNotRevoked(w, r, claims) {
+			return
+		}
+
 		// Check authorization
 		allowed, reason := checkAuthorization(claims, r.Method, r.URL.Path)
 		if !allowed {
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const ClientIPContextKey contextKey = "client_ip"
+
+// clientIP returns the originating address. Clients can send any
+// X-Forwarded-For they like, and each proxy appends the address it was
+// reached from, so only the hops added by the trustedProxies proxies in
+// front of the app are believed: the one the outermost of them saw is the
+// client. With no trusted proxies it is the connection's address.
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		if hops := forwardedHops(r); len(hops) > 0 {
+			return hops[max(len(hops)-trustedProxies, 0)]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// forwardedHops returns the X-Forwarded-For addresses, across repeated headers
+func forwardedHops(r *http.Request) []string {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	return hops
+}
+
+func withClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ClientIPContextKey, ip)
+}
+
+// GetClientIPFromContext retrieves the caller's IP address from the request context
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPContextKey).(string)
+	return ip
+}
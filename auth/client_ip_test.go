@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		forwarded      []string
+		trustedProxies int
+		want           string
+	}{
+		{"no proxies ignores the header", []string{"1.1.1.1"}, 0, "10.0.0.1"},
+		{"no header", nil, 1, "10.0.0.1"},
+		{"one proxy", []string{"203.0.113.7"}, 1, "203.0.113.7"},
+		{"spoofed hops before the proxy", []string{"1.1.1.1, 2.2.2.2, 203.0.113.7"}, 1, "203.0.113.7"},
+		{"two proxies", []string{"1.1.1.1, 203.0.113.7, 10.1.0.5"}, 2, "203.0.113.7"},
+		{"repeated headers", []string{"1.1.1.1", "203.0.113.7"}, 1, "203.0.113.7"},
+		{"fewer hops than proxies", []string{"203.0.113.7"}, 2, "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/auth/login", nil)
+			r.RemoteAddr = "10.0.0.1:51234"
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if got := clientIP(r, tt.trustedProxies); got != tt.want {
+				t.Errorf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,25 +1,36 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
-	StaffID      int    `json:"staffId"`
-	RestaurantID int    `json:"restaurantId"`
-	Role         string `json:"role"`
-	Username     string `json:"username"`
+	StaffID        int    `json:"staffId"`
+	PlatformUserID int    `json:"platformUserId,omitempty"`
+	RestaurantID   int    `json:"restaurantId"`
+	Role           string `json:"role"`
+	Username       string `json:"username"`
 	jwt.RegisteredClaims
 }
 
+// Issue times are written to the millisecond so a token issued just after
+// an account's sessions were revoked is not mistaken for one from before
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
 type JWTManager struct {
-	secret      []byte
-	expiryHours int
+	secret        []byte
+	accessExpiry  time.Duration
+	refreshExpiry time.Duration
 }
 
 func NewJWTManager() (*JWTManager, error) {
@@ -31,31 +42,58 @@ func NewJWTManager() (*JWTManager, error) {
 		return nil, fmt.Errorf("JWT_SECRET must be at least 32 characters")
 	}
 
-	expiryHours := 24
-	if e := os.Getenv("JWT_EXPIRY_HOURS"); e != "" {
+	accessMinutes := 15
+	if e := os.Getenv("JWT_ACCESS_EXPIRY_MINUTES"); e != "" {
+		if parsed, err := strconv.Atoi(e); err == nil && parsed > 0 {
+			accessMinutes = parsed
+		}
+	}
+
+	refreshHours := 24 * 7
+	if e := os.Getenv("JWT_REFRESH_EXPIRY_HOURS"); e != "" {
 		if parsed, err := strconv.Atoi(e); err == nil && parsed > 0 {
-			expiryHours = parsed
+			refreshHours = parsed
 		}
 	}
 
 	return &JWTManager{
-		secret:      []byte(secret),
-		expiryHours: expiryHours,
+		secret:        []byte(secret),
+		accessExpiry:  time.Duration(accessMinutes) * time.Minute,
+		refreshExpiry: time.Duration(refreshHours) * time.Hour,
 	}, nil
 }
 
-func (m *JWTManager) GenerateToken(staffID, restaurantID int, role, username string) (string, int64, error) {
-	expiresAt := time.Now().Add(time.Duration(m.expiryHours) * time.Hour)
+// RefreshExpiry is how long a refresh token stays valid after it is issued
+func (m *JWTManager) RefreshExpiry() time.Duration {
+	return m.refreshExpiry
+}
 
-	claims := &Claims{
+// GenerateToken issues a short-lived access token with a unique token ID
+func (m *JWTManager) GenerateToken(staffID, restaurantID int, role, username string) (string, int64, error) {
+	return m.sign(&Claims{
 		StaffID:      staffID,
 		RestaurantID: restaurantID,
 		Role:         role,
 		Username:     username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	})
+}
+
+// GeneratePlatformToken issues a superuser access token for a platform user account
+func (m *JWTManager) GeneratePlatformToken(platformUserID int, username string) (string, int64, error) {
+	return m.sign(&Claims{
+		PlatformUserID: platformUserID,
+		Role:           "superuser",
+		Username:       username,
+	})
+}
+
+func (m *JWTManager) sign(claims *Claims) (string, int64, error) {
+	expiresAt := time.Now().Add(m.accessExpiry)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -86,3 +124,13 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// NewRefreshToken returns an opaque random refresh token
+func NewRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
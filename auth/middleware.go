@@ -11,13 +11,17 @@ type contextKey string
 const ClaimsContextKey contextKey = "claims"
 
 type Middleware struct {
-	jwtManager  *JWTManager
-	publicPaths map[string]map[string]bool // path -> method -> bool
+	jwtManager     *JWTManager
+	revocations    *Revocations
+	publicPaths    map[string]map[string]bool // path -> method -> bool
+	trustedProxies int
+	streamTickets  *StreamTickets
 }
 
-func NewMiddleware(jwtManager *JWTManager) *Middleware {
+func NewMiddleware(jwtManager *JWTManager, revocations *Revocations) *Middleware {
 	return &Middleware{
 		jwtManager:  jwtManager,
+		revocations: revocations,
 		publicPaths: make(map[string]map[string]bool),
 	}
 }
@@ -29,6 +33,18 @@ func (m *Middleware) AddPublicPath(method, path string) {
 	m.publicPaths[path][method] = true
 }
 
+// SetTrustedProxies sets how many proxies in front of the app append to
+// X-Forwarded-For, so client addresses can be read from it
+func (m *Middleware) SetTrustedProxies(n int) {
+	m.trustedProxies = n
+}
+
+// SetStreamTickets lets customer WebSocket handshakes sign in with a stream
+// ticket in place of the customer token header
+func (m *Middleware) SetStreamTickets(t *StreamTickets) {
+	m.streamTickets = t
+}
+
 func (m *Middleware) isPublicPath(method, path string) bool {
 	// Check exact match first
 	if methods, ok := m.publicPaths[path]; ok {
@@ -69,6 +85,23 @@ func matchPath(pattern, path string) bool {
 }
 
 // Helper functions used by both middleware handlers
+
+// authorizationHeader returns the Authorization header. Browsers cannot set
+// headers on WebSocket handshakes, so upgrades may pass the token as ?token=.
+func authorizationHeader(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return header
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		if token := r.URL.Query().Get("token"); token != "" {
+			return "Bearer " + token
+		}
+	}
+
+	return ""
+}
+
 func splitAuthHeader(header string) []string {
 	parts := strings.SplitN(header, " ", 2)
 	if len(parts) == 2 {
@@ -77,6 +110,23 @@ func splitAuthHeader(header string) []string {
 	return parts
 }
 
+// checkNotRevoked writes an error response and returns false when the
+// token has been revoked or revocation status cannot be determined
+func (m *Middleware) checkNotRevoked(w http.ResponseWriter, r *http.Request, claims *Claims) bool {
+	revoked, err := m.revocations.IsRevoked(r.Context(), claims)
+	if err != nil {
+		http.Error(w, `{"error":"unable to verify session"}`, http.StatusServiceUnavailable)
+		return false
+	}
+
+	if revoked {
+		http.Error(w, `{"error":"token revoked"}`, http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
 func containsExpired(s string) bool {
 	return strings.Contains(s, "expired")
 }
@@ -85,9 +135,27 @@ func withClaims(ctx context.Context, claims *Claims) context.Context {
 	return context.WithValue(ctx, ClaimsContextKey, claims)
 }
 
+// withRequestValues copies what handlers need from the request headers
+// into its context, since GoFr handlers cannot read headers themselves
+func (m *Middleware) withRequestValues(r *http.Request) *http.Request {
+	ctx := withClientIP(r.Context(), clientIP(r, m.trustedProxies))
+
+	if key := r.Header.Get(AgentKeyHeader); key != "" {
+		ctx = withAgentKey(ctx, key)
+	}
+
+	if token := m.customerToken(r); token != "" {
+		ctx = withCustomerToken(ctx, token)
+	}
+
+	return r.WithContext(ctx)
+}
+
 // Handler provides authentication only (no authorization checks)
 func (m *Middleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = m.withRequestValues(r)
+
 		// Skip auth for public paths
 		if m.isPublicPath(r.Method, r.URL.Path) {
 			next.ServeHTTP(w, r)
@@ -95,7 +163,7 @@ func (m *Middleware) Handler(next http.Handler) http.Handler {
 		}
 
 		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
+		authHeader := authorizationHeader(r)
 		if authHeader == "" {
 			http.Error(w, `{"error":"missing authorization header"}`, http.StatusUnauthorized)
 			return
@@ -120,6 +188,11 @@ func (m *Middleware) Handler(next http.Handler) http.Handler {
 			return
 		}
 
+		// Reject logged-out or revoked sessions
+		if !m.checkNotRevoked(w, r, claims) {
+			return
+		}
+
 		// Attach claims to context
 		ctx := withClaims(r.Context(), claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPinHash is compared against when the username does not exist so that
+// failed logins take the same time whether or not the account exists.
+var dummyPinHash, _ = bcrypt.GenerateFromPassword([]byte("000000"), bcrypt.DefaultCost)
+
+// HashPin returns the bcrypt hash of a staff PIN
+func HashPin(pin string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash pin: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// IsPinHashed reports whether a stored PIN is already a bcrypt hash.
+// Rows created before hashing was introduced still hold the raw PIN.
+func IsPinHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// VerifyPin checks pin against the stored value, accepting legacy plaintext
+// rows. needsRehash is true when the match came from a plaintext row.
+func VerifyPin(stored, pin string) (ok, needsRehash bool) {
+	if IsPinHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(pin)) == nil, false
+	}
+
+	ok = subtle.ConstantTimeCompare([]byte(stored), []byte(pin)) == 1
+
+	return ok, ok
+}
+
+// BurnPinCheck spends the same time as a real PIN comparison
+func BurnPinCheck(pin string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPinHash, []byte(pin))
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key prefixes
+const (
+	keyPrefixRevokedToken        = "revoked_token:"
+	keyPrefixStaffRevoked        = "staff_revoked_before:"
+	keyPrefixPlatformUserRevoked = "platform_user_revoked_before:"
+)
+
+// Revocations tracks sessions that must no longer be accepted even though
+// their JWT has not expired: individual tokens (logout) and every token
+// issued to an account before a given moment (deactivation, credential change).
+type Revocations struct {
+	rdb        redis.Cmdable
+	accountTTL time.Duration
+}
+
+// NewRevocations keeps account-wide revocations for accountTTL, which should be
+// at least the refresh token lifetime so no older session can outlive it.
+func NewRevocations(rdb redis.Cmdable, accountTTL time.Duration) *Revocations {
+	return &Revocations{rdb: rdb, accountTTL: accountTTL}
+}
+
+// RevokeToken rejects a single access token until it would have expired anyway
+func (r *Revocations) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+
+	return r.rdb.Set(ctx, keyPrefixRevokedToken+tokenID, "1", ttl).Err()
+}
+
+// RevokeStaff rejects every token issued to the staff member up to now
+func (r *Revocations) RevokeStaff(ctx context.Context, staffID int) error {
+	return r.revokeBefore(ctx, keyPrefixStaffRevoked+strconv.Itoa(staffID))
+}
+
+// RevokePlatformUser rejects every token issued to the platform user up to now
+func (r *Revocations) RevokePlatformUser(ctx context.Context, platformUserID int) error {
+	return r.revokeBefore(ctx, keyPrefixPlatformUserRevoked+strconv.Itoa(platformUserID))
+}
+
+// IsStaffRevokedAt reports whether a session issued at issuedAt was revoked for the staff member
+func (r *Revocations) IsStaffRevokedAt(ctx context.Context, staffID int, issuedAt time.Time) (bool, error) {
+	if staffID == 0 {
+		return false, nil
+	}
+
+	return r.isRevokedBefore(ctx, keyPrefixStaffRevoked+strconv.Itoa(staffID), issuedAt)
+}
+
+// IsPlatformUserRevokedAt reports whether a session issued at issuedAt was revoked for the platform user
+func (r *Revocations) IsPlatformUserRevokedAt(ctx context.Context, platformUserID int, issuedAt time.Time) (bool, error) {
+	if platformUserID == 0 {
+		return false, nil
+	}
+
+	return r.isRevokedBefore(ctx, keyPrefixPlatformUserRevoked+strconv.Itoa(platformUserID), issuedAt)
+}
+
+// IsRevoked reports whether the token has been revoked by ID or by account
+func (r *Revocations) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if claims.ID != "" {
+		n, err := r.rdb.Exists(ctx, keyPrefixRevokedToken+claims.ID).Result()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	if claims.IssuedAt == nil {
+		return false, nil
+	}
+
+	if claims.PlatformUserID != 0 {
+		return r.IsPlatformUserRevokedAt(ctx, claims.PlatformUserID, claims.IssuedAt.Time)
+	}
+
+	return r.IsStaffRevokedAt(ctx, claims.StaffID, claims.IssuedAt.Time)
+}
+
+// revokeBefore stores the moment of revocation in Unix milliseconds, so a
+// session issued later in the same second is still accepted
+func (r *Revocations) revokeBefore(ctx context.Context, key string) error {
+	return r.rdb.Set(ctx, key, time.Now().UnixMilli(), r.accountTTL).Err()
+}
+
+func (r *Revocations) isRevokedBefore(ctx context.Context, key string, issuedAt time.Time) (bool, error) {
+	revokedBefore, err := r.rdb.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt.UnixMilli() <= revokedBefore, nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRevocations(t *testing.T) (*Revocations, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return NewRevocations(rdb, time.Hour), rdb
+}
+
+func TestRevokeStaffWithinASecond(t *testing.T) {
+	r, _ := newTestRevocations(t)
+	ctx := context.Background()
+
+	before := time.Now()
+	time.Sleep(2 * time.Millisecond)
+
+	if err := r.RevokeStaff(ctx, 1); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	after := time.Now()
+
+	if revoked, err := r.IsStaffRevokedAt(ctx, 1, before); err != nil || !revoked {
+		t.Errorf("session issued before the revocation: revoked = %v, %v", revoked, err)
+	}
+
+	if revoked, err := r.IsStaffRevokedAt(ctx, 1, after); err != nil || revoked {
+		t.Errorf("session issued after the revocation: revoked = %v, %v", revoked, err)
+	}
+
+	if revoked, err := r.IsStaffRevokedAt(ctx, 2, before); err != nil || revoked {
+		t.Errorf("another staff member's session: revoked = %v, %v", revoked, err)
+	}
+}
+
+func TestTokenIssuedAtKeepsMilliseconds(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-that-is-long-enough-for-hs256")
+
+	m, err := NewJWTManager()
+	if err != nil {
+		t.Fatalf("jwt manager: %v", err)
+	}
+
+	start := time.Now()
+
+	token, _, err := m.GenerateToken(1, 1, "admin", "admin")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	claims, err := m.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	// Truncated to the second it would read as well before start; parsing
+	// the fractional seconds may round down a millisecond
+	if issued := claims.IssuedAt.Time; issued.UnixMilli() < start.UnixMilli()-1 || time.Since(issued) > time.Second {
+		t.Errorf("issued at %v, want the time it was generated (%v)", issued, start)
+	}
+}
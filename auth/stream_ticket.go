@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamTicketTTL is how long a stream ticket can wait to be used
+const StreamTicketTTL = 30 * time.Second
+
+const keyPrefixStreamTicket = "stream_ticket:"
+
+// StreamTickets stand in for a customer token on WebSocket handshakes.
+// Browsers cannot set headers on the handshake, so the ticket goes in the
+// URL instead; it is used up by the handshake and expires soon after being
+// issued, so one that ends up in a log opens nothing.
+type StreamTickets struct {
+	rdb redis.Cmdable
+}
+
+func NewStreamTickets(rdb redis.Cmdable) *StreamTickets {
+	return &StreamTickets{rdb: rdb}
+}
+
+// Issue returns a ticket for the customer token
+func (t *StreamTickets) Issue(ctx context.Context, customerToken string) (string, error) {
+	ticket, err := randomKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate stream ticket: %w", err)
+	}
+
+	if err := t.rdb.Set(ctx, keyPrefixStreamTicket+hashKey(ticket), customerToken, StreamTicketTTL).Err(); err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+// Redeem uses up a ticket and returns the customer token it was issued for,
+// or "" when the ticket is unknown, used or expired
+func (t *StreamTickets) Redeem(ctx context.Context, ticket string) (string, error) {
+	token, err := t.rdb.GetDel(ctx, keyPrefixStreamTicket+hashKey(ticket)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return token, err
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStreamTicketSignsInOneHandshake(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	tickets := NewStreamTickets(rdb)
+	m := NewMiddleware(nil, nil)
+	m.SetStreamTickets(tickets)
+
+	ticket, err := tickets.Issue(context.Background(), "customer-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := func() string {
+		r := httptest.NewRequest("GET", "/restaurants/1/orders/21/events?ticket="+ticket, nil)
+		r.Header.Set("Upgrade", "websocket")
+
+		return GetCustomerTokenFromContext(m.withRequestValues(r).Context())
+	}
+
+	if got := handshake(); got != "customer-token" {
+		t.Fatalf("first handshake: got token %q, want customer-token", got)
+	}
+
+	if got := handshake(); got != "" {
+		t.Errorf("second handshake: got token %q, want none", got)
+	}
+}
+
+func TestStreamTicketExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	tickets := NewStreamTickets(rdb)
+	ctx := context.Background()
+
+	ticket, err := tickets.Issue(ctx, "customer-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr.FastForward(StreamTicketTTL)
+
+	if token, err := tickets.Redeem(ctx, ticket); err != nil || token != "" {
+		t.Errorf("expired ticket: got %q, %v, want none", token, err)
+	}
+}
@@ -0,0 +1,242 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"qr-dinein-backend/model"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TypeOrderCreated  = "order.created"
+	TypeStatusChanged = "order.status_changed"
+	TypeChefAssigned  = "order.chef_assigned"
+	TypeETAChanged    = "order.eta_changed"
+
+	TypeItemStatusChanged = "order.item_status_changed"
+
+	// TypeOrderAlert flags stuck or orphaned orders to admins
+	TypeOrderAlert = "order.alert"
+
+	// TypePing is sent to idle subscribers so dropped connections are detected
+	TypePing = "ping"
+)
+
+const (
+	streamMaxLen     = 1000
+	readBlockTimeout = 15 * time.Second
+	readBatchSize    = 100
+
+	// subscriberBuffer is how many batches a subscriber may fall behind
+	// before it is dropped
+	subscriberBuffer = 16
+)
+
+// errSlowSubscriber closes subscriptions that stop taking their events
+var errSlowSubscriber = errors.New("subscriber fell behind the event stream")
+
+// Broker fans out order events across app instances using one Redis stream
+// per restaurant. Every instance reads each stream it has subscribers for
+// with a single reader and hands the events to all of them, so each
+// connected screen sees every event regardless of which instance emitted it.
+type Broker struct {
+	rdb redis.Cmdable
+
+	mu   sync.Mutex
+	hubs map[int]*hub
+}
+
+// hub is the reader of a restaurant's stream and the subscribers it feeds
+type hub struct {
+	restaurantID int
+	cancel       context.CancelFunc
+	subs         map[*Subscription]struct{}
+}
+
+// Subscription receives a restaurant's events in batches on C. An empty
+// batch means the stream was idle for the block timeout. C is closed when
+// the subscription ends; Err then tells why.
+type Subscription struct {
+	C <-chan []model.OrderEvent
+
+	c   chan []model.OrderEvent
+	hub *hub
+	b   *Broker
+	err error
+}
+
+// NewBroker returns a broker that reads streams over rdb. Reads block for up
+// to readBlockTimeout, so rdb should be a client of its own rather than the
+// pool request handlers share.
+func NewBroker(rdb redis.Cmdable) *Broker {
+	return &Broker{rdb: rdb, hubs: map[int]*hub{}}
+}
+
+func streamKey(restaurantID int) string {
+	return "order_events:" + strconv.Itoa(restaurantID)
+}
+
+// Publish appends an event to the restaurant's stream
+func (b *Broker) Publish(ctx context.Context, rdb redis.Cmdable, evt *model.OrderEvent) error {
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey(evt.RestaurantID),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": string(data)},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	evt.ID = id
+
+	return nil
+}
+
+// StartID returns a stream position that only yields events published from now on
+func (b *Broker) StartID() string {
+	return strconv.FormatInt(time.Now().UnixMilli(), 10) + "-0"
+}
+
+// Subscribe starts receiving the restaurant's events published from now on,
+// starting the stream's reader if this is its first subscriber. The
+// subscription must be closed when done.
+func (b *Broker) Subscribe(restaurantID int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h, ok := b.hubs[restaurantID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		h = &hub{restaurantID: restaurantID, cancel: cancel, subs: map[*Subscription]struct{}{}}
+		b.hubs[restaurantID] = h
+
+		go b.run(ctx, h, b.StartID())
+	}
+
+	c := make(chan []model.OrderEvent, subscriberBuffer)
+	s := &Subscription{C: c, c: c, hub: h, b: b}
+	h.subs[s] = struct{}{}
+
+	return s
+}
+
+// Close ends the subscription. The last one to leave a stream stops its reader.
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	s.b.drop(s.hub, s, nil)
+}
+
+// Err returns why the subscription ended, or nil if it was closed by its owner
+func (s *Subscription) Err() error {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	return s.err
+}
+
+// drop ends a subscription with err. It must be called with b.mu held.
+func (b *Broker) drop(h *hub, s *Subscription, err error) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+
+	delete(h.subs, s)
+	s.err = err
+	close(s.c)
+
+	if len(h.subs) == 0 {
+		h.cancel()
+		if b.hubs[h.restaurantID] == h {
+			delete(b.hubs, h.restaurantID)
+		}
+	}
+}
+
+// run reads a restaurant's stream for its subscribers until the last one
+// leaves. A read error ends every subscription, as each would have failed
+// reading on its own.
+func (b *Broker) run(ctx context.Context, h *hub, lastID string) {
+	for {
+		events, next, err := b.Read(ctx, b.rdb, h.restaurantID, lastID)
+		if ctx.Err() != nil {
+			return
+		}
+
+		lastID = next
+
+		b.mu.Lock()
+		for s := range h.subs {
+			if err != nil {
+				b.drop(h, s, err)
+				continue
+			}
+
+			select {
+			case s.c <- events:
+			default:
+				b.drop(h, s, errSlowSubscriber)
+			}
+		}
+		b.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read blocks until events after lastID are available or the block timeout
+// elapses. It returns the events and the position to resume reading from.
+func (b *Broker) Read(ctx context.Context, rdb redis.Cmdable, restaurantID int, lastID string) ([]model.OrderEvent, string, error) {
+	streams, err := rdb.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{streamKey(restaurantID), lastID},
+		Count:   readBatchSize,
+		Block:   readBlockTimeout,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, lastID, nil
+	}
+	if err != nil {
+		return nil, lastID, err
+	}
+
+	var events []model.OrderEvent
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			lastID = msg.ID
+
+			raw, ok := msg.Values["data"].(string)
+			if !ok {
+				continue
+			}
+
+			var evt model.OrderEvent
+			if err := json.Unmarshal([]byte(raw), &evt); err != nil {
+				continue
+			}
+
+			evt.ID = msg.ID
+			events = append(events, evt)
+		}
+	}
+
+	return events, lastID, nil
+}
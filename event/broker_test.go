@@ -0,0 +1,169 @@
+package event
+
+import (
+	"context"
+	"qr-dinein-backend/model"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestBroker(t *testing.T) (*Broker, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return NewBroker(rdb), rdb
+}
+
+// receive waits for the next non-empty batch on a subscription
+func receive(t *testing.T, sub *Subscription) []model.OrderEvent {
+	t.Helper()
+
+	for {
+		select {
+		case events, ok := <-sub.C:
+			if !ok {
+				t.Fatalf("subscription closed: %v", sub.Err())
+			}
+			if len(events) > 0 {
+				return events
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
+
+func TestPublishAndRead(t *testing.T) {
+	b, rdb := newTestBroker(t)
+	ctx := context.Background()
+
+	evt := &model.OrderEvent{Type: TypeOrderCreated, RestaurantID: 1, OrderID: 7, Status: "pending"}
+	if err := b.Publish(ctx, rdb, evt); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	if evt.ID == "" || evt.Timestamp.IsZero() {
+		t.Fatalf("publish did not set the id and timestamp: %+v", evt)
+	}
+
+	events, next, err := b.Read(ctx, rdb, 1, "0-0")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if len(events) != 1 || events[0].OrderID != 7 || events[0].ID != evt.ID {
+		t.Fatalf("read %+v, want the published event", events)
+	}
+
+	if next != evt.ID {
+		t.Errorf("next position %s, want %s", next, evt.ID)
+	}
+}
+
+func TestSubscribersShareOneReader(t *testing.T) {
+	b, rdb := newTestBroker(t)
+
+	first := b.Subscribe(1)
+	defer first.Close()
+	second := b.Subscribe(1)
+	defer second.Close()
+	other := b.Subscribe(2)
+	defer other.Close()
+
+	b.mu.Lock()
+	readers := len(b.hubs)
+	b.mu.Unlock()
+
+	if readers != 2 {
+		t.Fatalf("%d stream readers, want one per restaurant", readers)
+	}
+
+	// Positions are taken from the clock, so publish in a later millisecond
+	time.Sleep(5 * time.Millisecond)
+
+	if err := b.Publish(context.Background(), rdb, &model.OrderEvent{Type: TypeStatusChanged, RestaurantID: 1, OrderID: 3}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for _, sub := range []*Subscription{first, second} {
+		events := receive(t, sub)
+		if len(events) != 1 || events[0].OrderID != 3 {
+			t.Errorf("received %+v, want order 3", events)
+		}
+	}
+
+	select {
+	case events := <-other.C:
+		if len(events) > 0 {
+			t.Errorf("restaurant 2 received restaurant 1's events: %+v", events)
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLastCloseStopsReader(t *testing.T) {
+	b, _ := newTestBroker(t)
+
+	first := b.Subscribe(1)
+	second := b.Subscribe(1)
+
+	first.Close()
+	first.Close()
+
+	if _, ok := <-first.C; ok {
+		t.Fatal("closed subscription still open")
+	}
+
+	if err := first.Err(); err != nil {
+		t.Errorf("closed subscription reported %v", err)
+	}
+
+	b.mu.Lock()
+	running := len(b.hubs)
+	b.mu.Unlock()
+
+	if running != 1 {
+		t.Fatalf("reader stopped while a subscriber remains")
+	}
+
+	second.Close()
+
+	b.mu.Lock()
+	running = len(b.hubs)
+	b.mu.Unlock()
+
+	if running != 0 {
+		t.Fatalf("reader still running after the last subscriber left")
+	}
+}
+
+func TestReadErrorEndsSubscriptions(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+
+	b := NewBroker(rdb)
+	sub := b.Subscribe(1)
+	defer sub.Close()
+
+	mr.Close()
+
+	select {
+	case _, ok := <-sub.C:
+		for ok {
+			_, ok = <-sub.C
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription still open after the stream failed")
+	}
+
+	if sub.Err() == nil {
+		t.Error("failed subscription reported no error")
+	}
+}
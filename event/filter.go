@@ -0,0 +1,41 @@
+package event
+
+import "qr-dinein-backend/model"
+
+// Filter narrows a restaurant's stream down to what a subscriber may see.
+// A zero Filter matches every event.
+type Filter struct {
+	ChefID  *int // only orders assigned to this chef or with a ticket for them
+	OrderID int  // only this order
+}
+
+func (f Filter) Match(evt *model.OrderEvent) bool {
+	if f.OrderID != 0 && evt.OrderID != f.OrderID {
+		return false
+	}
+
+	if f.ChefID != nil && !servedBy(evt, *f.ChefID) {
+		return false
+	}
+
+	// Alerts are meant for admins, not chef or customer screens
+	if evt.Type == TypeOrderAlert && (f.ChefID != nil || f.OrderID != 0) {
+		return false
+	}
+
+	return true
+}
+
+func servedBy(evt *model.OrderEvent, chefID int) bool {
+	if evt.AssignedChefID != nil && *evt.AssignedChefID == chefID {
+		return true
+	}
+
+	for _, id := range evt.ChefIDs {
+		if id == chefID {
+			return true
+		}
+	}
+
+	return false
+}
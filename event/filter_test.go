@@ -0,0 +1,36 @@
+package event
+
+import (
+	"qr-dinein-backend/model"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	chef := 4
+	other := 9
+
+	tests := []struct {
+		name   string
+		filter Filter
+		evt    model.OrderEvent
+		want   bool
+	}{
+		{"all events", Filter{}, model.OrderEvent{OrderID: 1}, true},
+		{"alerts for admins", Filter{}, model.OrderEvent{Type: TypeOrderAlert}, true},
+		{"own order", Filter{OrderID: 1}, model.OrderEvent{OrderID: 1}, true},
+		{"another order", Filter{OrderID: 1}, model.OrderEvent{OrderID: 2}, false},
+		{"no alerts for customers", Filter{OrderID: 1}, model.OrderEvent{Type: TypeOrderAlert, OrderID: 1}, false},
+		{"assigned chef", Filter{ChefID: &chef}, model.OrderEvent{AssignedChefID: &chef}, true},
+		{"chef with a ticket", Filter{ChefID: &chef}, model.OrderEvent{AssignedChefID: &other, ChefIDs: []int{other, chef}}, true},
+		{"another chef's order", Filter{ChefID: &chef}, model.OrderEvent{AssignedChefID: &other}, false},
+		{"no alerts for chefs", Filter{ChefID: &chef}, model.OrderEvent{Type: TypeOrderAlert, AssignedChefID: &chef}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(&tt.evt); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
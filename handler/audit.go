@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Audit struct {
+	service *service.Audit
+}
+
+func NewAudit(svc *service.Audit) *Audit {
+	return &Audit{service: svc}
+}
+
+// GetByRestaurant returns the audit log of a single restaurant
+func (h *Audit) GetByRestaurant(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	filter, err := parseAuditFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.RestaurantID = &restaurantID
+
+	return h.service.Query(ctx, filter)
+}
+
+// GetAll returns the audit log across all restaurants, optionally narrowed by ?restaurantId=
+func (h *Audit) GetAll(ctx *gofr.Context) (interface{}, error) {
+	filter, err := parseAuditFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := ctx.Param("restaurantId"); v != "" {
+		restaurantID, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restaurant id")
+		}
+		filter.RestaurantID = &restaurantID
+	}
+
+	return h.service.Query(ctx, filter)
+}
+
+// parseAuditFilter reads ?actor=&resource=&from=&to=&limit= (times in RFC 3339)
+func parseAuditFilter(ctx *gofr.Context) (*model.AuditFilter, error) {
+	filter := &model.AuditFilter{
+		Actor:    ctx.Param("actor"),
+		Resource: ctx.Param("resource"),
+	}
+
+	if v := ctx.Param("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from time, expected RFC 3339")
+		}
+		filter.From = &from
+	}
+
+	if v := ctx.Param("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to time, expected RFC 3339")
+		}
+		filter.To = &to
+	}
+
+	if v := ctx.Param("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
@@ -34,6 +34,28 @@ func (h *Auth) SuperuserLogin(ctx *gofr.Context) (interface{}, error) {
 	return h.service.SuperuserLogin(ctx, &req)
 }
 
+func (h *Auth) Refresh(ctx *gofr.Context) (interface{}, error) {
+	var req model.RefreshRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Refresh(ctx, &req)
+}
+
+func (h *Auth) Logout(ctx *gofr.Context) (interface{}, error) {
+	var req model.LogoutRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Logout(ctx, auth.GetClaimsFromContext(ctx), &req); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "logged out"}, nil
+}
+
 func (h *Auth) Me(ctx *gofr.Context) (interface{}, error) {
 	claims := auth.GetClaimsFromContext(ctx)
 	if claims == nil {
@@ -41,9 +63,10 @@ func (h *Auth) Me(ctx *gofr.Context) (interface{}, error) {
 	}
 
 	return map[string]interface{}{
-		"staffId":      claims.StaffID,
-		"restaurantId": claims.RestaurantID,
-		"role":         claims.Role,
-		"username":     claims.Username,
+		"staffId":        claims.StaffID,
+		"platformUserId": claims.PlatformUserID,
+		"restaurantId":   claims.RestaurantID,
+		"role":           claims.Role,
+		"username":       claims.Username,
 	}, nil
 }
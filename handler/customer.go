@@ -5,6 +5,7 @@ import (
 
 	"gofr.dev/pkg/gofr"
 
+	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
 	"qr-dinein-backend/service"
 )
@@ -41,12 +42,42 @@ func (h *Customer) VerifyOTP(ctx *gofr.Context) (interface{}, error) {
 	return h.service.VerifyOTP(ctx, &req)
 }
 
-// GetSession handles GET /api/v1/customer/session?token={sessionToken}
+// GetSession handles GET /api/v1/customer/session. The token is only read
+// from the X-Customer-Token header so it stays out of URLs and access logs.
 func (h *Customer) GetSession(ctx *gofr.Context) (interface{}, error) {
-	sessionToken := ctx.Param("token")
+	sessionToken := auth.GetCustomerTokenFromContext(ctx)
 	if sessionToken == "" {
 		return nil, fmt.Errorf("session token is required")
 	}
 
 	return h.service.GetSession(ctx, sessionToken)
 }
+
+// StreamTicket handles POST /api/v1/customer/stream-ticket
+func (h *Customer) StreamTicket(ctx *gofr.Context) (interface{}, error) {
+	return h.service.StreamTicket(ctx, auth.GetCustomerTokenFromContext(ctx))
+}
+
+// Logout handles POST /api/v1/customer/logout
+func (h *Customer) Logout(ctx *gofr.Context) (interface{}, error) {
+	if err := h.service.InvalidateSession(ctx, auth.GetCustomerTokenFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "logged out"}, nil
+}
+
+// GetProfile handles GET /api/v1/customer/profile
+func (h *Customer) GetProfile(ctx *gofr.Context) (interface{}, error) {
+	return h.service.GetProfile(ctx, auth.GetCustomerTokenFromContext(ctx))
+}
+
+// UpdateProfile handles PUT /api/v1/customer/profile
+func (h *Customer) UpdateProfile(ctx *gofr.Context) (interface{}, error) {
+	var c model.Customer
+	if err := ctx.Bind(&c); err != nil {
+		return nil, err
+	}
+
+	return h.service.UpdateProfile(ctx, auth.GetCustomerTokenFromContext(ctx), &c)
+}
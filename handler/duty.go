@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Duty struct {
+	service *service.Duty
+}
+
+func NewDuty(svc *service.Duty) *Duty {
+	return &Duty{service: svc}
+}
+
+// GetShifts handles GET /restaurants/{restaurantId}/staff/{id}/shifts
+func (h *Duty) GetShifts(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := staffPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetShifts(ctx, restaurantID, staffID)
+}
+
+// SetShifts handles PUT /restaurants/{restaurantId}/staff/{id}/shifts
+func (h *Duty) SetShifts(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := staffPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.ShiftsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.SetShifts(ctx, restaurantID, staffID, req.Shifts)
+}
+
+// Status returns the calling staff member's duty status
+func (h *Duty) Status(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := dutyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Status(ctx, restaurantID, staffID)
+}
+
+func (h *Duty) ClockIn(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := dutyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.ClockIn(ctx, restaurantID, staffID)
+}
+
+func (h *Duty) ClockOut(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := dutyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.ClockOut(ctx, restaurantID, staffID)
+}
+
+func (h *Duty) StartBreak(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := dutyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.StartBreak(ctx, restaurantID, staffID)
+}
+
+func (h *Duty) EndBreak(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, staffID, err := dutyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.EndBreak(ctx, restaurantID, staffID)
+}
+
+// Timesheet handles GET /restaurants/{restaurantId}/timesheet?from=&to= (dates as
+// YYYY-MM-DD, defaulting to the last 7 days)
+func (h *Duty) Timesheet(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	from, to, err := dateRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Timesheet(ctx, restaurantID, from, to)
+}
+
+// dateRange reads the from and to query parameters (YYYY-MM-DD), defaulting
+// to the last 7 days
+func dateRange(ctx *gofr.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -6)
+
+	var err error
+	if v := ctx.Param("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+	}
+
+	if v := ctx.Param("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+	}
+
+	return from, to, nil
+}
+
+// dutyCaller identifies the staff member clocking in or out from their token
+func dutyCaller(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	claims := auth.GetClaimsFromContext(ctx)
+	if claims == nil || claims.StaffID == 0 {
+		return 0, 0, fmt.Errorf("only staff members can track duty")
+	}
+
+	return restaurantID, claims.StaffID, nil
+}
+
+func staffPathIDs(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	staffID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid staff id")
+	}
+
+	return restaurantID, staffID, nil
+}
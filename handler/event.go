@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/event"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Event struct {
+	broker *event.Broker
+	orders *service.Order
+}
+
+func NewEvent(broker *event.Broker, orders *service.Order) *Event {
+	return &Event{broker: broker, orders: orders}
+}
+
+// Stream handles the WebSocket at /restaurants/{restaurantId}/events.
+// Chefs only receive events for orders assigned to them.
+func (h *Event) Stream(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var filter event.Filter
+
+	claims := auth.GetClaimsFromContext(ctx)
+	if claims != nil && claims.Role == "chef" {
+		chefID := claims.StaffID
+		filter.ChefID = &chefID
+	}
+
+	return nil, h.stream(ctx, restaurantID, filter)
+}
+
+// OrderStream handles the public WebSocket at /restaurants/{restaurantId}/orders/{id}/events
+// used by customer screens to follow a single order. It needs the token of
+// the customer who placed the order.
+func (h *Event) OrderStream(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	orderID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id")
+	}
+
+	if _, err := h.orders.GetCustomerOrder(ctx, restaurantID, orderID, auth.GetCustomerTokenFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	return nil, h.stream(ctx, restaurantID, event.Filter{OrderID: orderID})
+}
+
+// stream relays matching events to the socket until the client goes away
+func (h *Event) stream(ctx *gofr.Context, restaurantID int, filter event.Filter) error {
+	sub := h.broker.Subscribe(restaurantID)
+	defer sub.Close()
+
+	for {
+		var events []model.OrderEvent
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case batch, ok := <-sub.C:
+			if !ok {
+				if err := sub.Err(); err != nil {
+					return fmt.Errorf("failed to read events: %w", err)
+				}
+				return nil
+			}
+
+			events = batch
+		}
+
+		// Heartbeat on idle so a closed socket surfaces as a write error
+		if len(events) == 0 {
+			if err := ctx.WriteMessageToSocket(model.OrderEvent{Type: event.TypePing, RestaurantID: restaurantID}); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		for i := range events {
+			if !filter.Match(&events[i]) {
+				continue
+			}
+
+			if err := ctx.WriteMessageToSocket(events[i]); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Notification struct {
+	service *service.Notification
+}
+
+func NewNotification(svc *service.Notification) *Notification {
+	return &Notification{service: svc}
+}
+
+// GetOutbox handles GET /restaurants/{restaurantId}/notifications?status=dead
+func (h *Notification) GetOutbox(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetOutbox(ctx, restaurantID, ctx.Param("status"))
+}
+
+// Retry handles POST /restaurants/{restaurantId}/notifications/{id}/retry
+func (h *Notification) Retry(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification id")
+	}
+
+	return h.service.Retry(ctx, restaurantID, id)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type OptionGroup struct {
+	service *service.OptionGroup
+}
+
+func NewOptionGroup(svc *service.OptionGroup) *OptionGroup {
+	return &OptionGroup{service: svc}
+}
+
+func (h *OptionGroup) GetAll(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, productID, err := productPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetAll(ctx, restaurantID, productID)
+}
+
+func (h *OptionGroup) GetByID(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, productID, err := productPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid option group id")
+	}
+
+	return h.service.GetByID(ctx, restaurantID, productID, id)
+}
+
+func (h *OptionGroup) Create(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, productID, err := productPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var g model.OptionGroup
+	if err := ctx.Bind(&g); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Create(ctx, restaurantID, productID, &g)
+}
+
+func (h *OptionGroup) Update(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, productID, err := productPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid option group id")
+	}
+
+	var g model.OptionGroup
+	if err := ctx.Bind(&g); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Update(ctx, restaurantID, productID, id, &g)
+}
+
+func (h *OptionGroup) Delete(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, productID, err := productPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid option group id")
+	}
+
+	if err := h.service.Delete(ctx, restaurantID, productID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "option group deleted"}, nil
+}
+
+func productPathIDs(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	productID, err := strconv.Atoi(ctx.PathParam("productId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid product id")
+	}
+
+	return restaurantID, productID, nil
+}
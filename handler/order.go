@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
 	"qr-dinein-backend/service"
 	"strconv"
@@ -46,18 +47,20 @@ func (h *Order) GetByID(ctx *gofr.Context) (interface{}, error) {
 	return h.service.GetByID(ctx, restaurantID, id)
 }
 
-func (h *Order) GetByPhone(ctx *gofr.Context) (interface{}, error) {
+// GetCustomerOrders handles GET /restaurants/{restaurantId}/customer/orders
+// for the customer signed in with the X-Customer-Token header
+func (h *Order) GetCustomerOrders(ctx *gofr.Context) (interface{}, error) {
 	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid restaurant id")
 	}
 
-	phone := ctx.Param("phone")
-	if phone == "" {
-		return nil, fmt.Errorf("phone query parameter is required")
-	}
+	return h.service.GetCustomerOrders(ctx, restaurantID, auth.GetCustomerTokenFromContext(ctx))
+}
 
-	return h.service.GetByPhone(ctx, restaurantID, phone)
+// GetCustomerHistory handles GET /customer/orders
+func (h *Order) GetCustomerHistory(ctx *gofr.Context) (interface{}, error) {
+	return h.service.GetCustomerHistory(ctx, auth.GetCustomerTokenFromContext(ctx))
 }
 
 func (h *Order) Create(ctx *gofr.Context) (interface{}, error) {
@@ -71,6 +74,10 @@ func (h *Order) Create(ctx *gofr.Context) (interface{}, error) {
 		return nil, fmt.Errorf("invalid request body: %w", err)
 	}
 
+	if o.SessionToken == "" {
+		o.SessionToken = auth.GetCustomerTokenFromContext(ctx)
+	}
+
 	return h.service.Create(ctx, restaurantID, &o)
 }
 
@@ -110,3 +117,71 @@ func (h *Order) Delete(ctx *gofr.Context) (interface{}, error) {
 
 	return map[string]string{"message": "order deleted"}, nil
 }
+
+func (h *Order) UpdateItemStatus(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id")
+	}
+
+	lineID, err := strconv.Atoi(ctx.PathParam("lineId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid item id")
+	}
+
+	var req model.OrderItemStatusUpdate
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.UpdateItemStatus(ctx, restaurantID, id, lineID, req.Status)
+}
+
+// Cancel handles POST /restaurants/{restaurantId}/orders/{id}/cancel
+func (h *Order) Cancel(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id")
+	}
+
+	var req model.CancelRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Cancel(ctx, restaurantID, id, &req)
+}
+
+// CustomerCancel handles POST /restaurants/{restaurantId}/customer/orders/{id}/cancel
+func (h *Order) CustomerCancel(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id")
+	}
+
+	var req model.CancelRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if req.SessionToken == "" {
+		req.SessionToken = auth.GetCustomerTokenFromContext(ctx)
+	}
+
+	return h.service.CancelByCustomer(ctx, restaurantID, id, &req)
+}
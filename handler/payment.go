@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/payment"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Payment struct {
+	service *service.Payment
+}
+
+func NewPayment(svc *service.Payment) *Payment {
+	return &Payment{service: svc}
+}
+
+func (h *Payment) GetByOrder(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, orderID, err := orderPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetByOrder(ctx, restaurantID, orderID)
+}
+
+func (h *Payment) CreateIntent(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, orderID, err := orderPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.CreateIntent(ctx, restaurantID, orderID)
+}
+
+func (h *Payment) Refund(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, orderID, err := orderPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.RefundRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Refund(ctx, restaurantID, orderID, &req)
+}
+
+func (h *Payment) Webhook(ctx *gofr.Context) (interface{}, error) {
+	payload, signature, ok := payment.WebhookFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("webhook payload not captured")
+	}
+
+	if err := h.service.HandleWebhook(ctx, payload, signature); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "ok"}, nil
+}
+
+func orderPathIDs(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	orderID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid order id")
+	}
+
+	return restaurantID, orderID, nil
+}
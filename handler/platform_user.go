@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type PlatformUser struct {
+	service *service.PlatformUser
+}
+
+func NewPlatformUser(svc *service.PlatformUser) *PlatformUser {
+	return &PlatformUser{service: svc}
+}
+
+func (h *PlatformUser) GetAll(ctx *gofr.Context) (interface{}, error) {
+	return h.service.GetAll(ctx)
+}
+
+func (h *PlatformUser) GetByID(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	return h.service.GetByID(ctx, id)
+}
+
+func (h *PlatformUser) Create(ctx *gofr.Context) (interface{}, error) {
+	var u model.PlatformUser
+	if err := ctx.Bind(&u); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Create(ctx, &u)
+}
+
+func (h *PlatformUser) Update(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	var u model.PlatformUser
+	if err := ctx.Bind(&u); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Update(ctx, callerPlatformUserID(ctx), id, &u)
+}
+
+func (h *PlatformUser) Delete(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	if err := h.service.Delete(ctx, callerPlatformUserID(ctx), id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "platform user deleted"}, nil
+}
+
+func (h *PlatformUser) SetupTOTP(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	return h.service.SetupTOTP(ctx, callerPlatformUserID(ctx), id)
+}
+
+func (h *PlatformUser) VerifyTOTP(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	var req model.TOTPVerifyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.VerifyTOTP(ctx, callerPlatformUserID(ctx), id, req.Code)
+}
+
+func (h *PlatformUser) DisableTOTP(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform user id")
+	}
+
+	if err := h.service.DisableTOTP(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "two-factor authentication disabled"}, nil
+}
+
+func callerPlatformUserID(ctx *gofr.Context) int {
+	claims := auth.GetClaimsFromContext(ctx)
+	if claims == nil {
+		return 0
+	}
+
+	return claims.PlatformUserID
+}
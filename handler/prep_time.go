@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type PrepTime struct {
+	service *service.PrepTime
+}
+
+func NewPrepTime(svc *service.PrepTime) *PrepTime {
+	return &PrepTime{service: svc}
+}
+
+// ETAReport handles GET /restaurants/{restaurantId}/reports/eta?from=&to= (dates
+// as YYYY-MM-DD, defaulting to the last 7 days)
+func (h *PrepTime) ETAReport(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	from, to, err := dateRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Report(ctx, restaurantID, from, to)
+}
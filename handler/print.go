@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// defaultClaimWait is how long a claim waits for jobs when the agent does not say
+const defaultClaimWait = 25 * time.Second
+
+type Print struct {
+	service *service.Print
+}
+
+func NewPrint(svc *service.Print) *Print {
+	return &Print{service: svc}
+}
+
+// GetJobs handles GET /restaurants/{restaurantId}/print-jobs?status=failed
+func (h *Print) GetJobs(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetJobs(ctx, restaurantID, ctx.Param("status"))
+}
+
+// Queue handles POST /restaurants/{restaurantId}/print-jobs
+func (h *Print) Queue(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var job model.PrintJob
+	if err := ctx.Bind(&job); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Queue(ctx, restaurantID, &job)
+}
+
+// Retry handles POST /restaurants/{restaurantId}/print-jobs/{id}/retry
+func (h *Print) Retry(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid print job id")
+	}
+
+	return h.service.Retry(ctx, restaurantID, id)
+}
+
+func (h *Print) GetAgents(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetAgents(ctx, restaurantID)
+}
+
+func (h *Print) RegisterAgent(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var a model.PrintAgent
+	if err := ctx.Bind(&a); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.RegisterAgent(ctx, restaurantID, &a)
+}
+
+func (h *Print) DeleteAgent(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid print agent id")
+	}
+
+	if err := h.service.DeleteAgent(ctx, restaurantID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "print agent deleted"}, nil
+}
+
+// Claim handles POST /print-agent/jobs/claim?max={n}&wait={seconds}. The
+// agent authenticates with its key in the X-Print-Agent-Key header.
+func (h *Print) Claim(ctx *gofr.Context) (interface{}, error) {
+	limit := 0
+	if v := ctx.Param("max"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max")
+		}
+		limit = n
+	}
+
+	wait := defaultClaimWait
+	if v := ctx.Param("wait"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wait")
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	return h.service.Claim(ctx, auth.GetAgentKeyFromContext(ctx), limit, wait)
+}
+
+// Ack handles POST /print-agent/jobs/{id}/ack
+func (h *Print) Ack(ctx *gofr.Context) (interface{}, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid print job id")
+	}
+
+	var ack model.PrintAck
+	if err := ctx.Bind(&ack); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Ack(ctx, auth.GetAgentKeyFromContext(ctx), id, &ack)
+}
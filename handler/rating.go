@@ -40,6 +40,15 @@ func (h *Rating) GetAllByRestaurant(ctx *gofr.Context) (interface{}, error) {
 	return h.service.GetAllByRestaurant(ctx, restaurantID)
 }
 
+func (h *Rating) GetSummary(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetSummary(ctx, restaurantID)
+}
+
 func (h *Rating) Create(ctx *gofr.Context) (interface{}, error) {
 	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
 	if err != nil {
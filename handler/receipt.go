@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+type Receipt struct {
+	service *service.Receipt
+}
+
+func NewReceipt(svc *service.Receipt) *Receipt {
+	return &Receipt{service: svc}
+}
+
+// Ticket handles GET /restaurants/{restaurantId}/orders/{id}/ticket
+// ?format=escpos|pdf|html&kind=receipt|kitchen&width=58|80&station={stationId}
+func (h *Receipt) Ticket(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id")
+	}
+
+	req := &model.PrintRequest{Kind: ctx.Param("kind"), Format: ctx.Param("format")}
+
+	if w := ctx.Param("width"); w != "" {
+		if req.Width, err = strconv.Atoi(w); err != nil {
+			return nil, fmt.Errorf("invalid width")
+		}
+	}
+
+	if s := ctx.Param("station"); s != "" {
+		stationID, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid station id")
+		}
+		req.StationID = &stationID
+	}
+
+	content, contentType, err := h.service.Render(ctx, restaurantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.File{Content: content, ContentType: contentType}, nil
+}
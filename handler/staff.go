@@ -90,3 +90,21 @@ func (h *Staff) Delete(ctx *gofr.Context) (interface{}, error) {
 
 	return map[string]string{"message": "staff member deleted"}, nil
 }
+
+func (h *Staff) Unlock(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid staff id")
+	}
+
+	if err := h.service.Unlock(ctx, restaurantID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "staff member unlocked"}, nil
+}
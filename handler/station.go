@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Station struct {
+	service *service.Station
+}
+
+func NewStation(svc *service.Station) *Station {
+	return &Station{service: svc}
+}
+
+func (h *Station) GetAll(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetAll(ctx, restaurantID)
+}
+
+func (h *Station) GetByID(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := stationPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetByID(ctx, restaurantID, id)
+}
+
+func (h *Station) Create(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var st model.Station
+	if err := ctx.Bind(&st); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Create(ctx, restaurantID, &st)
+}
+
+func (h *Station) Update(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := stationPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var st model.Station
+	if err := ctx.Bind(&st); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Update(ctx, restaurantID, id, &st)
+}
+
+func (h *Station) Delete(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := stationPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Delete(ctx, restaurantID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "station deleted"}, nil
+}
+
+// SetChefs handles PUT /restaurants/{restaurantId}/stations/{id}/chefs
+func (h *Station) SetChefs(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := stationPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.StationChefsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.SetChefs(ctx, restaurantID, id, req.ChefIDs)
+}
+
+// GetTickets handles GET /restaurants/{restaurantId}/stations/{id}/tickets
+func (h *Station) GetTickets(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := stationPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetTickets(ctx, restaurantID, id)
+}
+
+func stationPathIDs(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid station id")
+	}
+
+	return restaurantID, id, nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Tab struct {
+	service *service.Tab
+}
+
+func NewTab(svc *service.Tab) *Tab {
+	return &Tab{service: svc}
+}
+
+// GetAll handles GET /restaurants/{restaurantId}/tabs?status=open|closed
+func (h *Tab) GetAll(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetAll(ctx, restaurantID, ctx.Param("status"))
+}
+
+func (h *Tab) GetByID(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := tabPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetByID(ctx, restaurantID, id)
+}
+
+func (h *Tab) GetBill(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := tabPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.GetBill(ctx, restaurantID, id)
+}
+
+func (h *Tab) Split(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := tabPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.SplitRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Split(ctx, restaurantID, id, &req)
+}
+
+// Close handles POST /restaurants/{restaurantId}/tabs/{id}/close. The body
+// is {} when the tab is paid, or the settlement taken at the counter.
+func (h *Tab) Close(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, id, err := tabPathIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.SettleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Close(ctx, restaurantID, id, &req)
+}
+
+func tabPathIDs(ctx *gofr.Context) (int, int, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tab id")
+	}
+
+	return restaurantID, id, nil
+}
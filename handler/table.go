@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+type Table struct {
+	service *service.Table
+}
+
+func NewTable(svc *service.Table) *Table {
+	return &Table{service: svc}
+}
+
+func (h *Table) GetAll(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetAll(ctx, restaurantID)
+}
+
+func (h *Table) GetByID(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid table id")
+	}
+
+	return h.service.GetByID(ctx, restaurantID, id)
+}
+
+func (h *Table) Create(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var t model.Table
+	if err := ctx.Bind(&t); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Create(ctx, restaurantID, &t)
+}
+
+func (h *Table) Update(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid table id")
+	}
+
+	var t model.Table
+	if err := ctx.Bind(&t); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Update(ctx, restaurantID, id, &t)
+}
+
+func (h *Table) Delete(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid table id")
+	}
+
+	if err := h.service.Delete(ctx, restaurantID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "table deleted"}, nil
+}
+
+// GetQR returns the signed QR payload for a table, or the PNG image when ?format=png
+func (h *Table) GetQR(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid table id")
+	}
+
+	if ctx.Param("format") == "png" {
+		png, err := h.service.GetQRImage(ctx, restaurantID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return response.File{Content: png, ContentType: "image/png"}, nil
+	}
+
+	return h.service.GetQR(ctx, restaurantID, id)
+}
+
+// Verify handles the public GET /restaurants/{restaurantId}/tables/verify?token={tableToken}
+func (h *Table) Verify(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.Verify(ctx, restaurantID, ctx.Param("token"))
+}
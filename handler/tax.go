@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/service"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Tax struct {
+	service *service.Tax
+}
+
+func NewTax(svc *service.Tax) *Tax {
+	return &Tax{service: svc}
+}
+
+func (h *Tax) GetAll(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	return h.service.GetAll(ctx, restaurantID)
+}
+
+func (h *Tax) GetByID(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tax component id")
+	}
+
+	return h.service.GetByID(ctx, restaurantID, id)
+}
+
+func (h *Tax) Create(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	var t model.TaxComponent
+	if err := ctx.Bind(&t); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Create(ctx, restaurantID, &t)
+}
+
+func (h *Tax) Update(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tax component id")
+	}
+
+	var t model.TaxComponent
+	if err := ctx.Bind(&t); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return h.service.Update(ctx, restaurantID, id, &t)
+}
+
+func (h *Tax) Delete(ctx *gofr.Context) (interface{}, error) {
+	restaurantID, err := strconv.Atoi(ctx.PathParam("restaurantId"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restaurant id")
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tax component id")
+	}
+
+	if err := h.service.Delete(ctx, restaurantID, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "tax component deleted"}, nil
+}
@@ -4,12 +4,17 @@ import (
 	"log"
 	"os"
 	"qr-dinein-backend/auth"
+	"qr-dinein-backend/event"
 	"qr-dinein-backend/handler"
 	"qr-dinein-backend/migrations"
+	"qr-dinein-backend/notify"
+	"qr-dinein-backend/payment"
 	"qr-dinein-backend/service"
 	"qr-dinein-backend/store"
 	"qr-dinein-backend/strategy"
+	"strconv"
 
+	"github.com/redis/go-redis/v9"
 	"gofr.dev/pkg/gofr"
 )
 
@@ -25,72 +30,201 @@ func main() {
 		log.Fatalf("Failed to initialize JWT manager: %v", err)
 	}
 
+	// Initialize table QR token signer
+	tableSigner, err := auth.NewTableTokenSigner()
+	if err != nil {
+		log.Fatalf("Failed to initialize table token signer: %v", err)
+	}
+
+	// Session revocation is checked in the auth middleware, which runs
+	// outside GoFr handlers, so it gets its own client to the same Redis
+	redisDB, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+	redisOptions := redis.Options{
+		Addr:     os.Getenv("REDIS_HOST") + ":" + getEnvOrDefault("REDIS_PORT", "6379"),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       redisDB,
+	}
+	redisClient := redis.NewClient(&redisOptions)
+	revocations := auth.NewRevocations(redisClient, jwtManager.RefreshExpiry())
+
+	// Initialize payment gateway
+	paymentProvider, err := payment.NewProviderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize payment provider: %v", err)
+	}
+
+	if paymentProvider.Name() == "fake" {
+		app.Logger().Warn("Using the in-process fake payment provider; payments are not real")
+	}
+
+	notifiers, err := notify.NewRegistryFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize notifiers: %v", err)
+	}
+	service.RegisterNotificationMetrics(app.Metrics())
+
+	// OTPs, their limits and customer sessions live in the database, where
+	// long-lived customer tokens already are, unless CUSTOMER_STATE_STORE
+	// says redis (or memory for a single local instance)
+	customerState, err := store.NewCustomerStateStore(getEnvOrDefault("CUSTOMER_STATE_STORE", "sql"), redisClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize customer state store: %v", err)
+	}
+
 	// Initialize auth middleware
-	authMiddleware := auth.NewMiddleware(jwtManager)
+	authMiddleware := auth.NewMiddleware(jwtManager, revocations)
+
+	// Client addresses for login limits come from X-Forwarded-For only
+	// behind this many proxies
+	trustedProxies, _ := strconv.Atoi(os.Getenv("TRUSTED_PROXY_HOPS"))
+	authMiddleware.SetTrustedProxies(trustedProxies)
+
+	// Customer screens open order streams with a single-use ticket
+	streamTickets := auth.NewStreamTickets(redisClient)
+	authMiddleware.SetStreamTickets(streamTickets)
 
 	// Configure public paths (no auth required)
 	authMiddleware.AddPublicPath("POST", "/auth/login")
+	authMiddleware.AddPublicPath("POST", "/auth/refresh")
 	authMiddleware.AddPublicPath("GET", "app/restaurants/{id}")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/settings")
 	authMiddleware.AddPublicPath("GET", "/restaurants/slug/{slug}")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/categories")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/products")
+	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/tables/verify")
 	authMiddleware.AddPublicPath("POST", "/restaurants/{restaurantId}/orders")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/orders/{id}")
+	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/orders/{id}/events")
 	authMiddleware.AddPublicPath("POST", "/customer/send-otp")
 	authMiddleware.AddPublicPath("POST", "/customer/verify-otp")
 	authMiddleware.AddPublicPath("GET", "/customer/session")
+	authMiddleware.AddPublicPath("POST", "/customer/logout")
+	authMiddleware.AddPublicPath("POST", "/customer/stream-ticket")
+	authMiddleware.AddPublicPath("GET", "/customer/profile")
+	authMiddleware.AddPublicPath("PUT", "/customer/profile")
+	authMiddleware.AddPublicPath("GET", "/customer/orders")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/customer/orders")
+	authMiddleware.AddPublicPath("POST", "/restaurants/{restaurantId}/customer/orders/{id}/cancel")
 	authMiddleware.AddPublicPath("POST", "/restaurants/{restaurantId}/orders/{orderId}/rating")
 	authMiddleware.AddPublicPath("GET", "/restaurants/{restaurantId}/orders/{orderId}/rating")
 	authMiddleware.AddPublicPath("POST", "/superuser/login")
+	authMiddleware.AddPublicPath("POST", "/restaurants/{restaurantId}/orders/{id}/payments")
+	authMiddleware.AddPublicPath("POST", "/payments/webhook")
+	authMiddleware.AddPublicPath("POST", "/print-agent/jobs/claim")
+	authMiddleware.AddPublicPath("POST", "/print-agent/jobs/{id}/ack")
 
 	// Apply auth middleware (with authorization)
 	app.UseMiddleware(authMiddleware.HandlerWithAuth)
 
+	// Keep the raw webhook body for signature verification
+	app.UseMiddleware(payment.WebhookCapture("/payments/webhook", paymentProvider.SignatureHeader()))
+
 	// --- Store layer ---
 	restaurantStore := store.NewRestaurant()
 	categoryStore := store.NewCategory()
 	productStore := store.NewProduct()
+	optionGroupStore := store.NewOptionGroup()
 	orderStore := store.NewOrder()
 	staffStore := store.NewStaff()
 	settingsStore := store.NewSettings()
 	ratingStore := store.NewRating()
+	platformUserStore := store.NewPlatformUser()
+	taxStore := store.NewTax()
+	tableStore := store.NewTable()
+	stationStore := store.NewStation()
+	ticketStore := store.NewTicket()
+	tabStore := store.NewTab()
+	shiftStore := store.NewShift()
+	timeEntryStore := store.NewTimeEntry()
+	prepTimeStore := store.NewPrepTime()
+	paymentStore := store.NewPayment()
+	auditStore := store.NewAudit()
+	printStore := store.NewPrint()
+	customerStore := store.NewCustomer()
+	notificationStore := store.NewNotification()
 
 	// --- Service layer ---
-	restaurantSvc := service.NewRestaurant(restaurantStore)
-	categorySvc := service.NewCategory(categoryStore)
-	productSvc := service.NewProduct(productStore)
-	staffSvc := service.NewStaff(staffStore)
-	settingsSvc := service.NewSettings(settingsStore)
+	auditSvc := service.NewAudit(auditStore)
+	restaurantSvc := service.NewRestaurant(restaurantStore, auditSvc)
+	categorySvc := service.NewCategory(categoryStore, auditSvc)
+	productSvc := service.NewProduct(productStore, optionGroupStore, auditSvc)
+	optionGroupSvc := service.NewOptionGroup(optionGroupStore, productStore, auditSvc)
+	loginLimiter := service.NewLoginLimiter()
+	staffSvc := service.NewStaff(staffStore, loginLimiter, revocations, auditSvc)
+	settingsSvc := service.NewSettings(settingsStore, auditSvc)
+	taxSvc := service.NewTax(taxStore)
+	tableSvc := service.NewTable(tableStore, restaurantStore, tableSigner, os.Getenv("MENU_BASE_URL"))
+	stationSvc := service.NewStation(stationStore, staffStore, ticketStore, auditSvc)
+	dutySvc := service.NewDuty(staffStore, shiftStore, timeEntryStore, auditSvc)
+	// Env superuser credentials only bootstrap the first platform user account
 	superuserUsername := os.Getenv("SUPERUSER_USERNAME")
 	superuserPassword := os.Getenv("SUPERUSER_PASSWORD")
-	authSvc := service.NewAuth(staffStore, jwtManager, superuserUsername, superuserPassword)
-	smsSvc := service.NewSMSService()
-	customerSvc := service.NewCustomer(smsSvc)
-	chefResolver := strategy.NewResolver(settingsStore, staffStore, orderStore)
-	orderSvc := service.NewOrder(orderStore, productStore, settingsStore, customerSvc, chefResolver)
+	authSvc := service.NewAuth(staffStore, platformUserStore, jwtManager, revocations, loginLimiter, superuserUsername, superuserPassword)
+	platformUserSvc := service.NewPlatformUser(platformUserStore, revocations)
+	notificationSvc := service.NewNotification(notificationStore, notifiers, settingsStore, restaurantStore)
+	customerSvc := service.NewCustomer(customerStore, customerState, notificationSvc, streamTickets)
+	prepTimeSvc := service.NewPrepTime(prepTimeStore, productStore)
+	chefResolver := strategy.NewResolver(settingsStore, staffStore, ticketStore, productStore)
+	// Event streams are read with blocking XREADs that would each hold one of
+	// the connections GoFr's handlers share, so the readers get their own client
+	eventBroker := event.NewBroker(redis.NewClient(&redis.Options{
+		Addr:     redisOptions.Addr,
+		Password: redisOptions.Password,
+		DB:       redisOptions.DB,
+	}))
+	orderSvc := service.NewOrder(orderStore, ticketStore, tabStore, productStore, optionGroupStore, settingsStore, restaurantStore, taxStore, customerSvc, notificationSvc, tableSvc, prepTimeSvc, chefResolver, eventBroker, auditSvc)
+	paymentSvc := service.NewPayment(paymentStore, orderStore, restaurantStore, orderSvc, paymentProvider, auditSvc)
+	orderSvc.SetRefundHook(paymentSvc.Refund)
 	ratingSvc := service.NewRating(ratingStore, orderStore)
+	tabSvc := service.NewTab(tabStore, orderStore, orderSvc, paymentSvc, auditSvc)
+	receiptSvc := service.NewReceipt(orderSvc, restaurantStore, stationStore)
+	printSvc := service.NewPrint(printStore, orderStore, settingsStore, receiptSvc, auditSvc)
+	orderSvc.SetPrintHook(printSvc.QueueTickets)
 
 	// --- Handler layer ---
 	restaurantH := handler.NewRestaurant(restaurantSvc)
 	categoryH := handler.NewCategory(categorySvc)
 	productH := handler.NewProduct(productSvc)
+	optionGroupH := handler.NewOptionGroup(optionGroupSvc)
 	orderH := handler.NewOrder(orderSvc)
 	staffH := handler.NewStaff(staffSvc)
 	settingsH := handler.NewSettings(settingsSvc)
+	taxH := handler.NewTax(taxSvc)
+	tableH := handler.NewTable(tableSvc)
+	stationH := handler.NewStation(stationSvc)
+	dutyH := handler.NewDuty(dutySvc)
+	prepTimeH := handler.NewPrepTime(prepTimeSvc)
 	authH := handler.NewAuth(authSvc)
+	platformUserH := handler.NewPlatformUser(platformUserSvc)
 	customerH := handler.NewCustomer(customerSvc)
 	ratingH := handler.NewRating(ratingSvc)
+	paymentH := handler.NewPayment(paymentSvc)
+	tabH := handler.NewTab(tabSvc)
+	receiptH := handler.NewReceipt(receiptSvc)
+	printH := handler.NewPrint(printSvc)
+	notificationH := handler.NewNotification(notificationSvc)
+	eventH := handler.NewEvent(eventBroker, orderSvc)
+	auditH := handler.NewAudit(auditSvc)
 
 	// ==================== Routes ====================
 
 	// --- Auth ---
 	app.POST("/auth/login", authH.Login)
+	app.POST("/auth/refresh", authH.Refresh)
+	app.POST("/auth/logout", authH.Logout)
 	app.GET("/auth/me", authH.Me)
 
 	// --- Superuser ---
 	app.POST("/superuser/login", authH.SuperuserLogin)
+	app.GET("/superuser/users", platformUserH.GetAll)
+	app.POST("/superuser/users", platformUserH.Create)
+	app.GET("/superuser/users/{id}", platformUserH.GetByID)
+	app.PUT("/superuser/users/{id}", platformUserH.Update)
+	app.DELETE("/superuser/users/{id}", platformUserH.Delete)
+	app.POST("/superuser/users/{id}/totp", platformUserH.SetupTOTP)
+	app.POST("/superuser/users/{id}/totp/verify", platformUserH.VerifyTOTP)
+	app.DELETE("/superuser/users/{id}/totp", platformUserH.DisableTOTP)
+	app.GET("/superuser/audit", auditH.GetAll)
 
 	// --- Restaurants ---
 	app.GET("/restaurants", restaurantH.GetAll)
@@ -114,20 +248,58 @@ func main() {
 	app.PUT("/restaurants/{restaurantId}/products/{id}", productH.Update)
 	app.DELETE("/restaurants/{restaurantId}/products/{id}", productH.Delete)
 
+	// --- Product option groups (variants, add-ons) ---
+	app.GET("/restaurants/{restaurantId}/products/{productId}/option-groups", optionGroupH.GetAll)
+	app.POST("/restaurants/{restaurantId}/products/{productId}/option-groups", optionGroupH.Create)
+	app.GET("/restaurants/{restaurantId}/products/{productId}/option-groups/{id}", optionGroupH.GetByID)
+	app.PUT("/restaurants/{restaurantId}/products/{productId}/option-groups/{id}", optionGroupH.Update)
+	app.DELETE("/restaurants/{restaurantId}/products/{productId}/option-groups/{id}", optionGroupH.Delete)
+
 	// --- Orders (scoped to restaurant) ---
 	app.GET("/restaurants/{restaurantId}/orders", orderH.GetAll)
 	app.POST("/restaurants/{restaurantId}/orders", orderH.Create)
 	app.GET("/restaurants/{restaurantId}/orders/{id}", orderH.GetByID)
 	app.PUT("/restaurants/{restaurantId}/orders/{id}", orderH.Update)
 	app.DELETE("/restaurants/{restaurantId}/orders/{id}", orderH.Delete)
+	app.PUT("/restaurants/{restaurantId}/orders/{id}/items/{lineId}", orderH.UpdateItemStatus)
+	app.POST("/restaurants/{restaurantId}/orders/{id}/cancel", orderH.Cancel)
+
+	// --- Printable receipts and kitchen tickets (ESC/POS, PDF, HTML) ---
+	app.GET("/restaurants/{restaurantId}/orders/{id}/ticket", receiptH.Ticket)
+
+	// --- Print queue for the print agents on restaurant networks ---
+	app.GET("/restaurants/{restaurantId}/print-jobs", printH.GetJobs)
+	app.POST("/restaurants/{restaurantId}/print-jobs", printH.Queue)
+	app.POST("/restaurants/{restaurantId}/print-jobs/{id}/retry", printH.Retry)
+	app.GET("/restaurants/{restaurantId}/print-agents", printH.GetAgents)
+	app.POST("/restaurants/{restaurantId}/print-agents", printH.RegisterAgent)
+	app.DELETE("/restaurants/{restaurantId}/print-agents/{id}", printH.DeleteAgent)
+	app.POST("/print-agent/jobs/claim", printH.Claim)
+	app.POST("/print-agent/jobs/{id}/ack", printH.Ack)
+
+	// --- Customer notification outbox (delivery status, dead-letter retry) ---
+	app.GET("/restaurants/{restaurantId}/notifications", notificationH.GetOutbox)
+	app.POST("/restaurants/{restaurantId}/notifications/{id}/retry", notificationH.Retry)
+
+	// --- Payments (intent is public so customers can pay from their phone) ---
+	app.GET("/restaurants/{restaurantId}/orders/{id}/payments", paymentH.GetByOrder)
+	app.POST("/restaurants/{restaurantId}/orders/{id}/payments", paymentH.CreateIntent)
+	app.POST("/restaurants/{restaurantId}/orders/{id}/payments/refund", paymentH.Refund)
+	app.POST("/payments/webhook", paymentH.Webhook)
+
+	// --- Order events (WebSocket; chefs see only their orders) ---
+	app.WebSocket("/restaurants/{restaurantId}/events", eventH.Stream)
+	app.WebSocket("/restaurants/{restaurantId}/orders/{id}/events", eventH.OrderStream)
 
 	// --- Customer Orders (public, filtered by phone) ---
-	app.GET("/restaurants/{restaurantId}/customer/orders", orderH.GetByPhone)
+	app.GET("/restaurants/{restaurantId}/customer/orders", orderH.GetCustomerOrders)
+	app.POST("/restaurants/{restaurantId}/customer/orders/{id}/cancel", orderH.CustomerCancel)
 
 	// --- Order Ratings (scoped to restaurant + order) ---
 	app.POST("/restaurants/{restaurantId}/orders/{orderId}/rating", ratingH.Create)
 	app.GET("/restaurants/{restaurantId}/orders/{orderId}/rating", ratingH.GetByOrderID)
 	app.GET("/restaurants/{restaurantId}/ratings", ratingH.GetAllByRestaurant)
+	app.GET("/restaurants/{restaurantId}/ratings/summary", ratingH.GetSummary)
 
 	// --- Staff (scoped to restaurant) ---
 	app.GET("/restaurants/{restaurantId}/staff", staffH.GetAll)
@@ -135,6 +307,20 @@ func main() {
 	app.GET("/restaurants/{restaurantId}/staff/{id}", staffH.GetByID)
 	app.PUT("/restaurants/{restaurantId}/staff/{id}", staffH.Update)
 	app.DELETE("/restaurants/{restaurantId}/staff/{id}", staffH.Delete)
+	app.POST("/restaurants/{restaurantId}/staff/{id}/unlock", staffH.Unlock)
+	app.GET("/restaurants/{restaurantId}/staff/{id}/shifts", dutyH.GetShifts)
+	app.PUT("/restaurants/{restaurantId}/staff/{id}/shifts", dutyH.SetShifts)
+
+	// --- Duty: clock-in/out and breaks for the calling staff member ---
+	app.GET("/restaurants/{restaurantId}/duty", dutyH.Status)
+	app.POST("/restaurants/{restaurantId}/duty/clock-in", dutyH.ClockIn)
+	app.POST("/restaurants/{restaurantId}/duty/clock-out", dutyH.ClockOut)
+	app.POST("/restaurants/{restaurantId}/duty/break/start", dutyH.StartBreak)
+	app.POST("/restaurants/{restaurantId}/duty/break/end", dutyH.EndBreak)
+	app.GET("/restaurants/{restaurantId}/timesheet", dutyH.Timesheet)
+
+	// --- Reports (scoped to restaurant) ---
+	app.GET("/restaurants/{restaurantId}/reports/eta", prepTimeH.ETAReport)
 
 	// --- Settings (scoped to restaurant) ---
 	app.GET("/restaurants/{restaurantId}/settings", settingsH.GetAll)
@@ -143,10 +329,63 @@ func main() {
 	app.PUT("/restaurants/{restaurantId}/settings/{key}", settingsH.Upsert)
 	app.DELETE("/restaurants/{restaurantId}/settings/{key}", settingsH.Delete)
 
-	// --- Customer OTP (public endpoints) ---
+	// --- Tax components (scoped to restaurant) ---
+	app.GET("/restaurants/{restaurantId}/taxes", taxH.GetAll)
+	app.POST("/restaurants/{restaurantId}/taxes", taxH.Create)
+	app.GET("/restaurants/{restaurantId}/taxes/{id}", taxH.GetByID)
+	app.PUT("/restaurants/{restaurantId}/taxes/{id}", taxH.Update)
+	app.DELETE("/restaurants/{restaurantId}/taxes/{id}", taxH.Delete)
+
+	// --- Tables (scoped to restaurant) ---
+	app.GET("/restaurants/{restaurantId}/tables", tableH.GetAll)
+	app.POST("/restaurants/{restaurantId}/tables", tableH.Create)
+	app.GET("/restaurants/{restaurantId}/tables/verify", tableH.Verify)
+	app.GET("/restaurants/{restaurantId}/tables/{id}", tableH.GetByID)
+	app.PUT("/restaurants/{restaurantId}/tables/{id}", tableH.Update)
+	app.DELETE("/restaurants/{restaurantId}/tables/{id}", tableH.Delete)
+	app.GET("/restaurants/{restaurantId}/tables/{id}/qr", tableH.GetQR)
+
+	// --- Table tabs: orders from one table on a single bill ---
+	app.GET("/restaurants/{restaurantId}/tabs", tabH.GetAll)
+	app.GET("/restaurants/{restaurantId}/tabs/{id}", tabH.GetByID)
+	app.GET("/restaurants/{restaurantId}/tabs/{id}/bill", tabH.GetBill)
+	app.POST("/restaurants/{restaurantId}/tabs/{id}/split", tabH.Split)
+	app.POST("/restaurants/{restaurantId}/tabs/{id}/close", tabH.Close)
+
+	// --- Kitchen stations (scoped to restaurant) ---
+	app.GET("/restaurants/{restaurantId}/stations", stationH.GetAll)
+	app.POST("/restaurants/{restaurantId}/stations", stationH.Create)
+	app.GET("/restaurants/{restaurantId}/stations/{id}", stationH.GetByID)
+	app.PUT("/restaurants/{restaurantId}/stations/{id}", stationH.Update)
+	app.DELETE("/restaurants/{restaurantId}/stations/{id}", stationH.Delete)
+	app.PUT("/restaurants/{restaurantId}/stations/{id}/chefs", stationH.SetChefs)
+	app.GET("/restaurants/{restaurantId}/stations/{id}/tickets", stationH.GetTickets)
+
+	// --- Audit log (scoped to restaurant) ---
+	app.GET("/restaurants/{restaurantId}/audit", auditH.GetByRestaurant)
+
+	// --- Customer OTP and accounts (public, signed in with X-Customer-Token) ---
 	app.POST("/customer/send-otp", customerH.SendOTP)
 	app.POST("/customer/verify-otp", customerH.VerifyOTP)
 	app.GET("/customer/session", customerH.GetSession)
+	app.POST("/customer/logout", customerH.Logout)
+	app.POST("/customer/stream-ticket", customerH.StreamTicket)
+	app.GET("/customer/profile", customerH.GetProfile)
+	app.PUT("/customer/profile", customerH.UpdateProfile)
+	app.GET("/customer/orders", orderH.GetCustomerHistory)
+
+	// --- Background jobs ---
+	// Reassign stuck or orphaned kitchen tickets and alert admins
+	app.AddCronJob("* * * * *", "order-sweeper", orderSvc.Sweep)
+	// Send queued customer notifications, OTPs included, every five seconds
+	app.AddCronJob("*/5 * * * * *", "notification-outbox", notificationSvc.DeliverOutbox)
 
 	app.Run()
 }
+
+func getEnvOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -4,14 +4,596 @@ import "gofr.dev/pkg/gofr/migration"
 
 func All() map[int64]migration.Migrate {
 	return map[int64]migration.Migrate{
-		1: createRestaurantsTable(),
-		2: createCategoriesTable(),
-		3: createProductsTable(),
-		4: createStaffTable(),
-		5: createOrdersTable(),
-		6: createSettingsTable(),
-		7: addPrepTimeAndEstimatedReadyAt(),
-		8: createOrderRatingsTable(),
+		1:  createRestaurantsTable(),
+		2:  createCategoriesTable(),
+		3:  createProductsTable(),
+		4:  createStaffTable(),
+		5:  createOrdersTable(),
+		6:  createSettingsTable(),
+		7:  addPrepTimeAndEstimatedReadyAt(),
+		8:  createOrderRatingsTable(),
+		9:  createTaxComponentsTable(),
+		10: addOrderBill(),
+		11: createDiningTablesTable(),
+		12: addOrderTableID(),
+		13: widenStaffPinForHashes(),
+		14: createPlatformUsersTable(),
+		15: createAuditLogTable(),
+		16: createProductOptionTables(),
+		17: createPaymentsTable(),
+		18: createStationTables(),
+		19: addChefSkillsAndCapacity(),
+		20: createStaffDutyTables(),
+		21: createPrepTimeTables(),
+		22: addOrderCancellation(),
+		23: createTableTabs(),
+		24: createPrintTables(),
+		25: createCustomerTables(),
+		26: addCustomerEmail(),
+		27: createNotificationOutbox(),
+		28: addOrderCustomerID(),
+		29: addNotificationDeadLetters(),
+		30: createCustomerStateTables(),
+	}
+}
+
+// OTPs and their limits, unless customer state is kept in Redis
+// (CUSTOMER_STATE_STORE=redis)
+func createCustomerStateTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS customer_otps (
+				phone_number VARCHAR(20) PRIMARY KEY,
+				code VARCHAR(10) NOT NULL,
+				attempts INT NOT NULL DEFAULT 0,
+				expires_at TIMESTAMP NOT NULL
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS customer_limits (
+				limit_key VARCHAR(64) PRIMARY KEY,
+				count INT NOT NULL DEFAULT 0,
+				expires_at TIMESTAMP NOT NULL
+			)`)
+			return err
+		},
+	}
+}
+
+// Messages that run out of attempts or expire are dead-lettered; expires_at
+// keeps a late OTP or order update from being sent once it is useless
+func addNotificationDeadLetters() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE notification_outbox ADD COLUMN expires_at TIMESTAMP NULL AFTER next_attempt_at,
+				ADD COLUMN dead_at TIMESTAMP NULL AFTER sent_at,
+				ADD INDEX idx_notification_outbox_restaurant (restaurant_id, status, id)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`UPDATE notification_outbox SET status = 'dead', dead_at = updated_at WHERE status = 'failed'`)
+			return err
+		},
+	}
+}
+
+// Orders placed by customers who verified their phone number by OTP, the
+// only numbers order updates are sent to
+func addOrderCustomerID() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE orders ADD COLUMN customer_id INT DEFAULT NULL AFTER customer_name,
+				ADD FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE SET NULL`)
+			return err
+		},
+	}
+}
+
+func createNotificationOutbox() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS notification_outbox (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				order_id INT DEFAULT NULL,
+				template VARCHAR(50) NOT NULL,
+				channel VARCHAR(20) NOT NULL,
+				recipient VARCHAR(255) NOT NULL,
+				sender VARCHAR(100) DEFAULT '',
+				subject VARCHAR(255) DEFAULT '',
+				body TEXT NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				attempts INT NOT NULL DEFAULT 0,
+				last_error TEXT,
+				claim_token VARCHAR(36) DEFAULT NULL,
+				claimed_at TIMESTAMP NULL,
+				next_attempt_at TIMESTAMP NOT NULL,
+				sent_at TIMESTAMP NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL,
+				INDEX idx_notification_outbox_due (status, next_attempt_at),
+				INDEX idx_notification_outbox_claim (claim_token)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`ALTER TABLE customers ADD COLUMN notifications_opt_out BOOLEAN NOT NULL DEFAULT FALSE`)
+			return err
+		},
+	}
+}
+
+// Restaurants that notify by email reach customers who saved an address
+func addCustomerEmail() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE customers ADD COLUMN email VARCHAR(255) DEFAULT '' AFTER name`)
+			return err
+		},
+	}
+}
+
+func createCustomerTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS customers (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				phone_number VARCHAR(20) NOT NULL,
+				name VARCHAR(100) DEFAULT '',
+				preferences JSON DEFAULT NULL,
+				allergens JSON DEFAULT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				UNIQUE KEY unique_customer_phone (phone_number)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS customer_tokens (
+				token_hash CHAR(64) PRIMARY KEY,
+				customer_id INT NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				last_used_at TIMESTAMP NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE,
+				INDEX idx_customer_tokens_customer (customer_id)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			// Order history is looked up by phone across restaurants
+			_, err = d.SQL.Exec(`ALTER TABLE orders ADD INDEX idx_orders_customer_mobile (customer_mobile, created_at)`)
+			return err
+		},
+	}
+}
+
+func createPrintTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS print_agents (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				key_hash CHAR(64) NOT NULL,
+				active BOOLEAN DEFAULT TRUE,
+				last_seen_at TIMESTAMP NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				UNIQUE KEY unique_print_agent_key (key_hash)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			// claim_token marks the jobs taken by one claim so they can be
+			// read back after the claiming UPDATE
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS print_jobs (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				order_id INT NOT NULL,
+				station_id INT DEFAULT NULL,
+				kind VARCHAR(20) NOT NULL,
+				format VARCHAR(20) NOT NULL,
+				width INT NOT NULL DEFAULT 80,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				attempts INT NOT NULL DEFAULT 0,
+				last_error TEXT,
+				agent_id INT DEFAULT NULL,
+				claim_token VARCHAR(64) DEFAULT NULL,
+				next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				claimed_at TIMESTAMP NULL,
+				printed_at TIMESTAMP NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+				FOREIGN KEY (agent_id) REFERENCES print_agents(id) ON DELETE SET NULL,
+				INDEX idx_print_jobs_queue (restaurant_id, status, next_attempt_at),
+				INDEX idx_print_jobs_claim (claim_token)
+			)`)
+			return err
+		},
+	}
+}
+
+func createTableTabs() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			// open_table is set only while the tab is open, so the unique key
+			// allows a single open tab per table
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS table_tabs (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				table_id INT DEFAULT NULL,
+				table_number VARCHAR(50) NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'open',
+				open_table VARCHAR(100) DEFAULT NULL,
+				opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				closed_at TIMESTAMP NULL,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (table_id) REFERENCES dining_tables(id) ON DELETE SET NULL,
+				UNIQUE KEY unique_open_tab (restaurant_id, open_table),
+				INDEX idx_table_tabs_status (restaurant_id, status)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`ALTER TABLE orders ADD COLUMN tab_id INT DEFAULT NULL AFTER table_number,
+				ADD FOREIGN KEY (tab_id) REFERENCES table_tabs(id) ON DELETE SET NULL`)
+			return err
+		},
+	}
+}
+
+func addOrderCancellation() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE orders ADD COLUMN cancellation JSON NULL AFTER bill`)
+			return err
+		},
+	}
+}
+
+func createPrepTimeTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS prep_samples (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				order_id INT NOT NULL,
+				line_id INT NOT NULL,
+				product_id INT NOT NULL,
+				chef_id INT DEFAULT NULL,
+				quantity INT NOT NULL DEFAULT 1,
+				estimated_seconds INT NOT NULL DEFAULT 0,
+				actual_seconds INT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+				FOREIGN KEY (chef_id) REFERENCES staff(id) ON DELETE SET NULL,
+				INDEX idx_prep_samples_product (restaurant_id, product_id, created_at),
+				INDEX idx_prep_samples_chef (restaurant_id, product_id, chef_id, created_at),
+				INDEX idx_prep_samples_range (restaurant_id, created_at)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			// chef_id 0 holds the statistics across all chefs
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS prep_stats (
+				restaurant_id INT NOT NULL,
+				product_id INT NOT NULL,
+				chef_id INT NOT NULL DEFAULT 0,
+				samples INT NOT NULL,
+				median_seconds INT NOT NULL,
+				p90_seconds INT NOT NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (restaurant_id, product_id, chef_id),
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+			)`)
+			return err
+		},
+	}
+}
+
+func createStaffDutyTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS staff_shifts (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				staff_id INT NOT NULL,
+				weekday TINYINT NOT NULL,
+				start_time VARCHAR(5) NOT NULL,
+				end_time VARCHAR(5) NOT NULL,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (staff_id) REFERENCES staff(id) ON DELETE CASCADE,
+				INDEX idx_staff_shifts_staff (restaurant_id, staff_id)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS staff_time_entries (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				staff_id INT NOT NULL,
+				clock_in_at TIMESTAMP NOT NULL,
+				clock_out_at TIMESTAMP NULL,
+				break_started_at TIMESTAMP NULL,
+				break_minutes INT NOT NULL DEFAULT 0,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (staff_id) REFERENCES staff(id) ON DELETE CASCADE,
+				INDEX idx_time_entries_open (staff_id, clock_out_at),
+				INDEX idx_time_entries_range (restaurant_id, clock_in_at)
+			)`)
+			return err
+		},
+	}
+}
+
+func addChefSkillsAndCapacity() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE staff ADD COLUMN skills JSON NULL AFTER active,
+				ADD COLUMN max_tickets INT NOT NULL DEFAULT 0 AFTER skills`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`ALTER TABLE products ADD COLUMN skill_tags JSON NULL AFTER station_id`)
+			return err
+		},
+	}
+}
+
+func createStationTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS stations (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				active BOOLEAN NOT NULL DEFAULT TRUE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS station_staff (
+				station_id INT NOT NULL,
+				staff_id INT NOT NULL,
+				PRIMARY KEY (station_id, staff_id),
+				FOREIGN KEY (station_id) REFERENCES stations(id) ON DELETE CASCADE,
+				FOREIGN KEY (staff_id) REFERENCES staff(id) ON DELETE CASCADE
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS order_tickets (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				order_id INT NOT NULL,
+				station_id INT NULL,
+				assigned_chef_id INT NULL,
+				status VARCHAR(20) NOT NULL,
+				line_ids JSON NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+				FOREIGN KEY (station_id) REFERENCES stations(id) ON DELETE SET NULL,
+				FOREIGN KEY (assigned_chef_id) REFERENCES staff(id) ON DELETE SET NULL,
+				INDEX idx_order_tickets_order (order_id),
+				INDEX idx_order_tickets_station (station_id, status),
+				INDEX idx_order_tickets_chef (restaurant_id, assigned_chef_id, status)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`ALTER TABLE categories ADD COLUMN station_id INT NULL,
+				ADD FOREIGN KEY (station_id) REFERENCES stations(id) ON DELETE SET NULL`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`ALTER TABLE products ADD COLUMN station_id INT NULL,
+				ADD FOREIGN KEY (station_id) REFERENCES stations(id) ON DELETE SET NULL`)
+			return err
+		},
+	}
+}
+
+func createPaymentsTable() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE orders ADD COLUMN payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid' AFTER status`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS payments (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				order_id INT NOT NULL,
+				provider VARCHAR(20) NOT NULL,
+				provider_order_id VARCHAR(255) NOT NULL DEFAULT '',
+				provider_payment_id VARCHAR(255) NOT NULL DEFAULT '',
+				amount DECIMAL(10,2) NOT NULL,
+				refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+				currency VARCHAR(10) NOT NULL,
+				status VARCHAR(20) NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+				INDEX idx_payments_order (order_id),
+				INDEX idx_payments_provider_order (provider, provider_order_id)
+			)`)
+			return err
+		},
+	}
+}
+
+func createProductOptionTables() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS product_option_groups (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				product_id INT NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				selection_type VARCHAR(10) NOT NULL DEFAULT 'single',
+				min_select INT NOT NULL DEFAULT 0,
+				max_select INT NOT NULL DEFAULT 1,
+				sort_order INT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+				INDEX idx_option_groups_product (restaurant_id, product_id)
+			)`)
+			if err != nil {
+				return err
+			}
+
+			_, err = d.SQL.Exec(`CREATE TABLE IF NOT EXISTS product_options (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				group_id INT NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+				available BOOLEAN DEFAULT TRUE,
+				sort_order INT NOT NULL DEFAULT 0,
+				FOREIGN KEY (group_id) REFERENCES product_option_groups(id) ON DELETE CASCADE
+			)`)
+			return err
+		},
+	}
+}
+
+// audit_log has no foreign keys so entries outlive the rows they describe
+func createAuditLogTable() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS audit_log (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NULL,
+				actor_type VARCHAR(20) NOT NULL,
+				actor_id INT NOT NULL DEFAULT 0,
+				actor_name VARCHAR(255) NOT NULL DEFAULT '',
+				resource VARCHAR(50) NOT NULL,
+				resource_id VARCHAR(255) NOT NULL DEFAULT '',
+				action VARCHAR(20) NOT NULL,
+				before_data JSON NULL,
+				after_data JSON NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_audit_restaurant_created (restaurant_id, created_at),
+				INDEX idx_audit_actor (actor_name),
+				INDEX idx_audit_created (created_at)
+			)`)
+			return err
+		},
+	}
+}
+
+func createPlatformUsersTable() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS platform_users (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				username VARCHAR(255) NOT NULL UNIQUE,
+				password_hash VARCHAR(255) NOT NULL,
+				totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+				totp_enabled BOOLEAN DEFAULT FALSE,
+				active BOOLEAN DEFAULT TRUE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			)`)
+			return err
+		},
+	}
+}
+
+// widenStaffPinForHashes makes room for bcrypt hashes. Existing plaintext
+// PINs are rehashed on the owner's next successful login.
+func widenStaffPinForHashes() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE staff MODIFY COLUMN pin VARCHAR(255) NOT NULL`)
+			return err
+		},
+	}
+}
+
+func createDiningTablesTable() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS dining_tables (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				number VARCHAR(50) NOT NULL,
+				capacity INT DEFAULT 0,
+				zone VARCHAR(100) DEFAULT '',
+				active BOOLEAN DEFAULT TRUE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				UNIQUE KEY unique_restaurant_table (restaurant_id, number)
+			)`)
+			return err
+		},
+	}
+}
+
+func addOrderTableID() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE orders ADD COLUMN table_id INT DEFAULT NULL,
+				ADD FOREIGN KEY (table_id) REFERENCES dining_tables(id) ON DELETE SET NULL`)
+			return err
+		},
+	}
+}
+
+func createTaxComponentsTable() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`CREATE TABLE IF NOT EXISTS tax_components (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				restaurant_id INT NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				rate DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE,
+				INDEX idx_tax_components_restaurant (restaurant_id)
+			)`)
+			return err
+		},
+	}
+}
+
+func addOrderBill() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(`ALTER TABLE orders ADD COLUMN bill JSON NULL`)
+			return err
+		},
 	}
 }
 
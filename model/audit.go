@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records a single mutating call: who did what to which resource
+type AuditEntry struct {
+	ID           int             `json:"id"`
+	RestaurantID *int            `json:"restaurantId"`
+	ActorType    string          `json:"actorType"` // staff, platform_user, customer
+	ActorID      int             `json:"actorId"`
+	ActorName    string          `json:"actorName"`
+	Resource     string          `json:"resource"`
+	ResourceID   string          `json:"resourceId"`
+	Action       string          `json:"action"` // create, update, delete, unlock
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// AuditFilter narrows an audit log query; zero values are ignored
+type AuditFilter struct {
+	RestaurantID *int
+	Actor        string
+	Resource     string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+}
@@ -6,19 +6,49 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt int64  `json:"expiresAt"`
-	Staff     *Staff `json:"staff"`
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt int64  `json:"refreshExpiresAt"`
+	Staff            *Staff `json:"staff"`
 }
 
 type SuperuserLoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	OTP      string `json:"otp"` // TOTP code, required once two-factor is enabled
 }
 
 type SuperuserLoginResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt int64  `json:"expiresAt"`
-	Role      string `json:"role"`
-	Username  string `json:"username"`
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt int64  `json:"refreshExpiresAt"`
+	Role             string `json:"role"`
+	Username         string `json:"username"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshResponse struct {
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt int64  `json:"refreshExpiresAt"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshSession represents the refresh token data stored in Redis
+type RefreshSession struct {
+	StaffID        int    `json:"staffId"`
+	PlatformUserID int    `json:"platformUserId,omitempty"`
+	RestaurantID   int    `json:"restaurantId"`
+	Role           string `json:"role"`
+	Username       string `json:"username"`
+	IssuedAt       int64  `json:"issuedAt"` // Unix milliseconds
 }
@@ -0,0 +1,32 @@
+package model
+
+// BillLine is the priced breakdown of a single order item
+type BillLine struct {
+	ProductID int      `json:"productId"`
+	Name      string   `json:"name"`
+	Options   []string `json:"options,omitempty"`
+	Quantity  int      `json:"quantity"`
+	UnitPrice float64  `json:"unitPrice"`
+	Amount    float64  `json:"amount"`
+	Tax       float64  `json:"tax"`
+}
+
+// BillTax is the total charged for one tax component
+type BillTax struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// Bill is the structured breakdown persisted alongside an order
+type Bill struct {
+	Currency          string     `json:"currency"`
+	Lines             []BillLine `json:"lines"`
+	Subtotal          float64    `json:"subtotal"`
+	ServiceChargeRate float64    `json:"serviceChargeRate"`
+	ServiceCharge     float64    `json:"serviceCharge"`
+	Taxes             []BillTax  `json:"taxes"`
+	TaxTotal          float64    `json:"taxTotal"`
+	Rounding          float64    `json:"rounding"`
+	GrandTotal        float64    `json:"grandTotal"`
+}
@@ -8,6 +8,7 @@ type Category struct {
 	Name         string    `json:"name"`
 	Order        int       `json:"order"`
 	Image        string    `json:"image"`
+	StationID    *int      `json:"stationId"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
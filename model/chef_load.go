@@ -1,6 +1,7 @@
 package model
 
+// ChefLoad is the number of open kitchen tickets assigned to a chef
 type ChefLoad struct {
-	ChefID     int
-	OrderCount int
+	ChefID      int
+	OpenTickets int
 }
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // SendOTPRequest represents the request to send OTP
 type SendOTPRequest struct {
 	PhoneNumber  string `json:"phoneNumber"`
@@ -19,21 +21,49 @@ type OTPResponse struct {
 	ExpiresIn int    `json:"expiresIn"` // seconds
 }
 
-// VerifyOTPResponse represents the response after successful verification
+// VerifyOTPResponse represents the response after successful verification.
+// SessionToken is the customer's long-lived token; it is sent back in the
+// X-Customer-Token header or an order's sessionToken.
 type VerifyOTPResponse struct {
-	SessionToken string `json:"sessionToken"`
-	ExpiresAt    int64  `json:"expiresAt"`
-	PhoneNumber  string `json:"phoneNumber"`
+	SessionToken string    `json:"sessionToken"`
+	ExpiresAt    int64     `json:"expiresAt"`
+	PhoneNumber  string    `json:"phoneNumber"`
+	Customer     *Customer `json:"customer"`
+}
+
+// StreamTicketResponse carries a single-use ticket a customer screen passes
+// as ?ticket= when opening an order's event stream
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expiresIn"` // seconds
 }
 
-// CustomerSession represents the verified customer session stored in Redis
+// CustomerSession is the customer a token was issued to
 type CustomerSession struct {
-	PhoneNumber  string `json:"phoneNumber"`
-	RestaurantID int    `json:"restaurantId"`
-	Verified     bool   `json:"verified"`
+	CustomerID  int       `json:"customerId"`
+	PhoneNumber string    `json:"phoneNumber"`
+	Verified    bool      `json:"verified"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Customer is a diner's account, identified by their verified phone number
+// and kept across visits and restaurants
+type Customer struct {
+	ID          int               `json:"id"`
+	PhoneNumber string            `json:"phoneNumber"`
+	Name        string            `json:"name"`
+	Email       string            `json:"email"`       // for restaurants that notify by email
+	Preferences map[string]string `json:"preferences"` // e.g. "spice": "mild"
+	Allergens   []string          `json:"allergens"`
+
+	// NotificationsOptOut stops order updates being sent to the customer
+	NotificationsOptOut bool `json:"notificationsOptOut"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-// OTPData represents the OTP data stored in Redis
+// OTPData is a pending OTP and the attempts made against it
 type OTPData struct {
 	OTP      string `json:"otp"`
 	Attempts int    `json:"attempts"`
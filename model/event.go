@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Alert reasons raised by the order sweeper
+const (
+	AlertUnassigned = "unassigned"
+	AlertOrphaned   = "orphaned"
+	AlertOverdue    = "overdue"
+)
+
+// Alert tells admins about a kitchen ticket that needed attention and
+// whether it could be handed to another chef
+type Alert struct {
+	Reason     string `json:"reason"`
+	TicketID   int    `json:"ticketId"`
+	StationID  *int   `json:"stationId,omitempty"`
+	FromChefID *int   `json:"fromChefId,omitempty"`
+	ToChefID   *int   `json:"toChefId,omitempty"`
+	Message    string `json:"message"`
+}
+
+// OrderEvent is pushed to kitchen and customer screens when an order changes
+type OrderEvent struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	RestaurantID     int        `json:"restaurantId"`
+	OrderID          int        `json:"orderId"`
+	Status           string     `json:"status,omitempty"`
+	AssignedChefID   *int       `json:"assignedChefId,omitempty"`
+	ChefIDs          []int      `json:"chefIds,omitempty"` // chefs holding a station ticket on the order
+	EstimatedReadyAt *time.Time `json:"estimatedReadyAt,omitempty"`
+	Order            *Order     `json:"order,omitempty"`
+	Item             *OrderItem `json:"item,omitempty"`
+	Alert            *Alert     `json:"alert,omitempty"`
+	Timestamp        time.Time  `json:"timestamp"`
+}
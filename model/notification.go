@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Notification outbox statuses
+const (
+	NotificationPending = "pending" // waiting to be sent, possibly to be retried
+	NotificationSending = "sending" // claimed by a worker
+	NotificationSent    = "sent"
+	NotificationDead    = "dead" // dead-lettered after the last attempt or on expiry
+)
+
+// Notification is a rendered customer message in the outbox. It is stored
+// in the request that triggers it and delivered by a background worker, so
+// it survives restarts and provider outages.
+type Notification struct {
+	ID            int        `json:"id"`
+	RestaurantID  int        `json:"restaurantId"`
+	OrderID       *int       `json:"orderId"`
+	Template      string     `json:"template"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Sender        string     `json:"sender"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	SentAt        *time.Time `json:"sentAt"`
+	DeadAt        *time.Time `json:"deadAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
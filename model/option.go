@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Option group selection types
+const (
+	SelectionSingle = "single"
+	SelectionMulti  = "multi"
+)
+
+// OptionGroup is a set of choices offered on a product, e.g. "Size" or "Add-ons"
+type OptionGroup struct {
+	ID            int       `json:"id"`
+	RestaurantID  int       `json:"restaurantId"`
+	ProductID     int       `json:"productId"`
+	Name          string    `json:"name"`
+	SelectionType string    `json:"selectionType"` // single, multi
+	MinSelect     int       `json:"minSelect"`
+	MaxSelect     int       `json:"maxSelect"` // 0 means no limit for multi select
+	SortOrder     int       `json:"sortOrder"`
+	Options       []Option  `json:"options"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Option is a single choice within an option group
+type Option struct {
+	ID         int     `json:"id"`
+	GroupID    int     `json:"groupId"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"priceDelta"`
+	Available  bool    `json:"available"`
+	SortOrder  int     `json:"sortOrder"`
+}
+
+// OrderItemOption is an option chosen for an order item. Customers send only
+// OptionID; the rest is snapshotted from the catalogue at order time.
+type OrderItemOption struct {
+	OptionID   int     `json:"optionId"`
+	GroupID    int     `json:"groupId"`
+	GroupName  string  `json:"groupName"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"priceDelta"`
+}
@@ -2,29 +2,94 @@ package model
 
 import "time"
 
+// Order item kitchen statuses
+const (
+	ItemQueued  = "queued"
+	ItemCooking = "cooking"
+	ItemReady   = "ready"
+	ItemServed  = "served"
+	ItemVoided  = "voided"
+)
+
 type OrderItem struct {
-	ProductID int     `json:"productId"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Veg       bool    `json:"veg"`
+	LineID    int               `json:"lineId"` // position of the item within its order, starting at 1
+	ProductID int               `json:"productId"`
+	Name      string            `json:"name"`
+	Price     float64           `json:"price"` // unit price including option deltas
+	Quantity  int               `json:"quantity"`
+	Veg       bool              `json:"veg"`
+	Options   []OrderItemOption `json:"options,omitempty"`
+
+	Status           string     `json:"status"`
+	EstimatedReadyAt *time.Time `json:"estimatedReadyAt,omitempty"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+	ReadyAt          *time.Time `json:"readyAt,omitempty"`
+	ServedAt         *time.Time `json:"servedAt,omitempty"`
+	VoidedAt         *time.Time `json:"voidedAt,omitempty"`
+}
+
+// OrderItemStatusUpdate moves a single order item through the kitchen
+type OrderItemStatusUpdate struct {
+	Status string `json:"status"`
+}
+
+// Order cancellation reasons
+const (
+	CancelCustomerRequest = "customer_request"
+	CancelOutOfStock      = "out_of_stock"
+	CancelKitchenClosed   = "kitchen_closed"
+	CancelDuplicate       = "duplicate_order"
+	CancelPaymentIssue    = "payment_issue"
+	CancelOther           = "other" // requires a note
+)
+
+// Cancellation records why an order was cancelled and by whom
+type Cancellation struct {
+	Reason      string    `json:"reason"`
+	Note        string    `json:"note,omitempty"`
+	ActorType   string    `json:"actorType"` // staff, platform_user or customer
+	ActorID     int       `json:"actorId,omitempty"`
+	ActorName   string    `json:"actorName,omitempty"`
+	CancelledAt time.Time `json:"cancelledAt"`
+}
+
+// CancelRequest cancels an order. Customers cancelling their own order send
+// the session token from verifying their phone number.
+type CancelRequest struct {
+	Reason       string `json:"reason"`
+	Note         string `json:"note"`
+	SessionToken string `json:"sessionToken,omitempty"`
 }
 
 type Order struct {
-	ID                  int        `json:"id"`
-	RestaurantID        int        `json:"restaurantId"`
-	TableNumber         *string    `json:"tableNumber"`
-	CustomerMobile      string     `json:"customerPhone"`
-	CustomerName        string     `json:"customerName"`
+	ID                  int         `json:"id"`
+	RestaurantID        int         `json:"restaurantId"`
+	TableID             *int        `json:"tableId"`
+	TableNumber         *string     `json:"tableNumber"`
+	TabID               *int        `json:"tabId"`
+	CustomerMobile      string      `json:"customerPhone"`
+	CustomerName        string      `json:"customerName"`
+	CustomerID          *int        `json:"customerId"` // set when the customer verified their number by OTP
 	Items               []OrderItem `json:"items"`
-	Status              string     `json:"status"`
-	SpecialInstructions string     `json:"specialInstructions"`
-	Total               float64    `json:"total"`
-	AssignedChefID      *int       `json:"assignedChefId"`
-	EstimatedReadyAt    *time.Time `json:"estimatedReadyAt"`
-	CreatedAt           time.Time  `json:"createdAt"`
-	UpdatedAt           time.Time  `json:"updatedAt"`
+	Status              string      `json:"status"`
+	PaymentStatus       string      `json:"paymentStatus"`
+	SpecialInstructions string      `json:"specialInstructions"`
+	Total               float64     `json:"total"`
+	Bill                *Bill       `json:"bill"`
+	AssignedChefID      *int        `json:"assignedChefId"`
+	EstimatedReadyAt    *time.Time  `json:"estimatedReadyAt"`
+	CreatedAt           time.Time   `json:"createdAt"`
+	UpdatedAt           time.Time   `json:"updatedAt"`
+
+	// Cancellation says why and by whom a cancelled order was cancelled
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+
+	// Tickets are the order's station tickets (stored in order_tickets)
+	Tickets []Ticket `json:"tickets,omitempty"`
 
 	// SessionToken is used only for customer order creation (not stored in DB)
 	SessionToken string `json:"sessionToken,omitempty"`
+
+	// TableToken is the signed token from the table's QR code (not stored in DB)
+	TableToken string `json:"tableToken,omitempty"`
 }
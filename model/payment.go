@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Order payment statuses
+const (
+	PaymentUnpaid            = "unpaid"
+	PaymentPaid              = "paid"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+)
+
+// Payment record statuses
+const (
+	PaymentRecordCreated  = "created"
+	PaymentRecordCaptured = "captured"
+	PaymentRecordFailed   = "failed"
+)
+
+// Counter settlement methods, recorded as the provider of the payment
+const (
+	SettleCash = "cash"
+	SettleCard = "card"
+	SettleUPI  = "upi"
+)
+
+// Payment is one attempt to collect an order's total through a gateway, or
+// a settlement taken at the counter
+type Payment struct {
+	ID                int       `json:"id"`
+	RestaurantID      int       `json:"restaurantId"`
+	OrderID           int       `json:"orderId"`
+	Provider          string    `json:"provider"`
+	ProviderOrderID   string    `json:"providerOrderId"`
+	ProviderPaymentID string    `json:"providerPaymentId"`
+	Amount            float64   `json:"amount"`
+	RefundedAmount    float64   `json:"refundedAmount"`
+	Currency          string    `json:"currency"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// PaymentIntent is returned to the client to open the gateway checkout
+type PaymentIntent struct {
+	PaymentID       int     `json:"paymentId"`
+	Provider        string  `json:"provider"`
+	ProviderOrderID string  `json:"providerOrderId"`
+	ClientKey       string  `json:"clientKey"`
+	Amount          float64 `json:"amount"`
+	Currency        string  `json:"currency"`
+}
+
+type RefundRequest struct {
+	Amount float64 `json:"amount"` // 0 refunds the remaining captured amount
+	Reason string  `json:"reason"`
+}
+
+// SettleRequest records a bill paid at the counter. Method is empty when
+// nothing is being settled.
+type SettleRequest struct {
+	Method    string `json:"method"`    // cash, card or upi
+	Reference string `json:"reference"` // card slip or UPI transaction number
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// PlatformUser is an operator account with superuser access across restaurants
+type PlatformUser struct {
+	ID          int       `json:"id"`
+	Username    string    `json:"username"`
+	Password    string    `json:"password,omitempty"`
+	TOTPEnabled bool      `json:"totpEnabled"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	// Stored credentials, never serialised
+	PasswordHash string `json:"-"`
+	TOTPSecret   string `json:"-"`
+}
+
+// TOTPSetupResponse is returned when a platform user starts TOTP enrolment
+type TOTPSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TOTPVerifyRequest struct {
+	Code string `json:"code"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// PrepSample is the measured time an order item took from starting to cook
+// until it was ready
+type PrepSample struct {
+	ID               int       `json:"id"`
+	RestaurantID     int       `json:"restaurantId"`
+	OrderID          int       `json:"orderId"`
+	LineID           int       `json:"lineId"`
+	ProductID        int       `json:"productId"`
+	ChefID           *int      `json:"chefId"`
+	Quantity         int       `json:"quantity"`
+	EstimatedSeconds int       `json:"estimatedSeconds"` // estimate given when cooking started, 0 when unknown
+	ActualSeconds    int       `json:"actualSeconds"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// PrepStat summarises a product's most recent prep samples, across all chefs
+// (ChefID 0) or for a single chef
+type PrepStat struct {
+	ProductID     int
+	ChefID        int
+	Samples       int
+	MedianSeconds int
+	P90Seconds    int
+}
+
+// ETAReport compares the prep time estimated when items started cooking with
+// how long they actually took
+type ETAReport struct {
+	From                 string             `json:"from"`
+	To                   string             `json:"to"`
+	Samples              int                `json:"samples"`
+	MeanEstimatedMinutes float64            `json:"meanEstimatedMinutes"`
+	MeanActualMinutes    float64            `json:"meanActualMinutes"`
+	MeanAbsErrorMinutes  float64            `json:"meanAbsErrorMinutes"`
+	OnTimePercent        float64            `json:"onTimePercent"` // items ready within their estimate
+	Products             []ETAProductReport `json:"products"`
+}
+
+type ETAProductReport struct {
+	ProductID            int     `json:"productId"`
+	Name                 string  `json:"name"`
+	PrepTime             int     `json:"prepTime"` // static prep time in minutes
+	Samples              int     `json:"samples"`
+	MedianMinutes        float64 `json:"medianMinutes"`
+	P90Minutes           float64 `json:"p90Minutes"`
+	MeanEstimatedMinutes float64 `json:"meanEstimatedMinutes"`
+	MeanActualMinutes    float64 `json:"meanActualMinutes"`
+	MeanAbsErrorMinutes  float64 `json:"meanAbsErrorMinutes"`
+	OnTimePercent        float64 `json:"onTimePercent"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// Printout kinds
+const (
+	PrintReceipt = "receipt" // customer receipt with prices and taxes
+	PrintKitchen = "kitchen" // kitchen ticket, one per station
+)
+
+// Printout formats
+const (
+	FormatESCPOS = "escpos"
+	FormatPDF    = "pdf"
+	FormatHTML   = "html"
+)
+
+// PrintRequest describes how an order should be rendered. Width is the
+// thermal paper width in millimetres (58 or 80) and only applies to ESC/POS;
+// StationID limits a kitchen printout to one station's ticket, 0 meaning the
+// items routed to no station.
+type PrintRequest struct {
+	Kind      string `json:"kind"`
+	Format    string `json:"format"`
+	Width     int    `json:"width"`
+	StationID *int   `json:"stationId,omitempty"`
+}
+
+// Print job statuses
+const (
+	PrintPending = "pending" // waiting for an agent, possibly to be retried
+	PrintClaimed = "claimed" // handed to an agent that has not reported back
+	PrintPrinted = "printed" // the agent printed it
+	PrintFailed  = "failed"  // gave up after the last attempt
+)
+
+// PrintJob is a printout queued for a restaurant's print agent
+type PrintJob struct {
+	ID            int        `json:"id"`
+	RestaurantID  int        `json:"restaurantId"`
+	OrderID       int        `json:"orderId"`
+	StationID     *int       `json:"stationId"`
+	Kind          string     `json:"kind"`
+	Format        string     `json:"format"`
+	Width         int        `json:"width"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	AgentID       *int       `json:"agentId"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	ClaimedAt     *time.Time `json:"claimedAt"`
+	PrintedAt     *time.Time `json:"printedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+
+	// Content is the rendered printout, sent only to the agent claiming the job
+	Content     []byte `json:"content,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// PrintAck is an agent's report on a job it claimed
+type PrintAck struct {
+	Status string `json:"status"` // printed or failed
+	Error  string `json:"error"`
+}
+
+// PrintAgent is a program on the restaurant's network that claims print
+// jobs and sends them to its printers, authenticating with its key
+type PrintAgent struct {
+	ID           int        `json:"id"`
+	RestaurantID int        `json:"restaurantId"`
+	Name         string     `json:"name"`
+	Active       bool       `json:"active"`
+	LastSeenAt   *time.Time `json:"lastSeenAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+
+	// Key is returned only when the agent is registered; only its hash is stored
+	Key string `json:"key,omitempty"`
+}
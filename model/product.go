@@ -13,6 +13,11 @@ type Product struct {
 	Veg          bool      `json:"veg"`
 	Available    bool      `json:"available"`
 	PrepTime     int       `json:"prepTime"`
+	StationID    *int      `json:"stationId"` // overrides the category's station
+	SkillTags    []string  `json:"skillTags"` // skills a chef needs to prepare the product
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
+
+	// OptionGroups are managed through the option-groups endpoints
+	OptionGroups []OptionGroup `json:"optionGroups"`
 }
@@ -10,3 +10,10 @@ type Rating struct {
 	Comment      string    `json:"comment"`
 	CreatedAt    time.Time `json:"createdAt"`
 }
+
+// RatingSummary aggregates a restaurant's ratings. Cancelled orders are left out.
+type RatingSummary struct {
+	Count        int         `json:"count"`
+	Average      float64     `json:"average"`
+	Distribution map[int]int `json:"distribution"` // number of ratings per star value
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// Shift is one weekly scheduled working period of a staff member. Times are
+// "HH:MM" in the restaurant's local time; an end before the start runs past
+// midnight.
+type Shift struct {
+	ID           int    `json:"id"`
+	RestaurantID int    `json:"restaurantId"`
+	StaffID      int    `json:"staffId"`
+	Weekday      int    `json:"weekday"` // 0 = Sunday
+	StartTime    string `json:"startTime"`
+	EndTime      string `json:"endTime"`
+}
+
+type ShiftsRequest struct {
+	Shifts []Shift `json:"shifts"`
+}
+
+// TimeEntry is one clocked-in period of a staff member
+type TimeEntry struct {
+	ID             int        `json:"id"`
+	RestaurantID   int        `json:"restaurantId"`
+	StaffID        int        `json:"staffId"`
+	ClockInAt      time.Time  `json:"clockInAt"`
+	ClockOutAt     *time.Time `json:"clockOutAt"`
+	BreakStartedAt *time.Time `json:"breakStartedAt"` // set while on break
+	BreakMinutes   int        `json:"breakMinutes"`   // completed breaks
+}
+
+// Duty statuses
+const (
+	DutyOff     = "off_duty"
+	DutyOn      = "on_duty"
+	DutyOnBreak = "on_break"
+)
+
+type DutyStatus struct {
+	StaffID int        `json:"staffId"`
+	Status  string     `json:"status"`
+	Entry   *TimeEntry `json:"entry"`
+}
+
+// TimesheetDay is the time a staff member worked on one day, attributed to
+// the day they clocked in
+type TimesheetDay struct {
+	StaffID          int     `json:"staffId"`
+	Username         string  `json:"username"`
+	Date             string  `json:"date"` // YYYY-MM-DD
+	WorkedMinutes    int     `json:"workedMinutes"`
+	BreakMinutes     int     `json:"breakMinutes"`
+	ScheduledMinutes int     `json:"scheduledMinutes"`
+	Hours            float64 `json:"hours"`
+}
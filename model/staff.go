@@ -9,6 +9,8 @@ type Staff struct {
 	Pin          string    `json:"pin,omitempty"`
 	Role         string    `json:"role"`
 	Active       bool      `json:"active"`
+	Skills       []string  `json:"skills"`               // matched against product skill tags
+	MaxTickets   int       `json:"maxConcurrentTickets"` // open tickets a chef can hold at once; 0 means no limit
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
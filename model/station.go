@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Station is a section of the kitchen (tandoor, grill, bar, ...) that
+// prepares the items routed to it
+type Station struct {
+	ID           int       `json:"id"`
+	RestaurantID int       `json:"restaurantId"`
+	Name         string    `json:"name"`
+	Active       bool      `json:"active"`
+	ChefIDs      []int     `json:"chefIds"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type StationChefsRequest struct {
+	ChefIDs []int `json:"chefIds"`
+}
+
+// Ticket statuses
+const (
+	TicketOpen   = "open"
+	TicketDone   = "done"
+	TicketVoided = "voided"
+)
+
+// Ticket is the part of an order prepared at one station. Items whose
+// product and category have no station go on a ticket with no StationID.
+type Ticket struct {
+	ID             int       `json:"id"`
+	RestaurantID   int       `json:"restaurantId"`
+	OrderID        int       `json:"orderId"`
+	StationID      *int      `json:"stationId"`
+	AssignedChefID *int      `json:"assignedChefId"`
+	Status         string    `json:"status"`
+	LineIDs        []int     `json:"lineIds"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+
+	// Items are the order items on the ticket, filled in for kitchen views
+	Items []OrderItem `json:"items,omitempty"`
+}
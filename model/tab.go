@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Tab statuses
+const (
+	TabOpen   = "open"
+	TabClosed = "closed"
+)
+
+// Tab groups the orders placed from one table until the bill is settled
+type Tab struct {
+	ID           int        `json:"id"`
+	RestaurantID int        `json:"restaurantId"`
+	TableID      *int       `json:"tableId"`
+	TableNumber  string     `json:"tableNumber"`
+	Status       string     `json:"status"`
+	OpenedAt     time.Time  `json:"openedAt"`
+	ClosedAt     *time.Time `json:"closedAt"`
+
+	// Orders are the tab's orders, filled in when fetching a single tab
+	Orders []Order `json:"orders,omitempty"`
+}
+
+// TabBill is one bill covering every order on a tab that was not cancelled
+type TabBill struct {
+	TabID    int     `json:"tabId"`
+	OrderIDs []int   `json:"orderIds"`
+	Bill     *Bill   `json:"bill"`
+	Paid     float64 `json:"paid"` // total of orders already paid online
+	Due      float64 `json:"due"`
+}
+
+// Bill split modes
+const (
+	SplitEqual  = "equal"
+	SplitByItem = "items"
+)
+
+// SplitRequest divides a tab's bill either into a number of equal shares or
+// by the items each guest had
+type SplitRequest struct {
+	Mode   string         `json:"mode"`
+	Shares int            `json:"shares"` // number of guests for an equal split
+	Groups [][]TabItemRef `json:"groups"` // items of each guest for a split by item
+}
+
+// TabItemRef points at one item of an order on the tab
+type TabItemRef struct {
+	OrderID int `json:"orderId"`
+	LineID  int `json:"lineId"`
+}
+
+type TabShare struct {
+	Items  []TabItemRef `json:"items,omitempty"`
+	Bill   *Bill        `json:"bill,omitempty"`
+	Amount float64      `json:"amount"`
+}
+
+type TabSplit struct {
+	TabID  int        `json:"tabId"`
+	Mode   string     `json:"mode"`
+	Total  float64    `json:"total"` // amount due on the tab
+	Shares []TabShare `json:"shares"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Table struct {
+	ID           int       `json:"id"`
+	RestaurantID int       `json:"restaurantId"`
+	Number       string    `json:"number"`
+	Capacity     int       `json:"capacity"`
+	Zone         string    `json:"zone"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableQR is the signed payload encoded into a table's QR code
+type TableQR struct {
+	TableID int    `json:"tableId"`
+	Number  string `json:"number"`
+	Token   string `json:"token"`
+	URL     string `json:"url"`
+}
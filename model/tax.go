@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// TaxComponent is a named tax line applied to a restaurant's bills (e.g. CGST, SGST).
+type TaxComponent struct {
+	ID           int       `json:"id"`
+	RestaurantID int       `json:"restaurantId"`
+	Name         string    `json:"name"`
+	Rate         float64   `json:"rate"` // percent
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
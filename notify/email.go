@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+)
+
+// Email implements Notifier over SMTP. The restaurant's sender ID is used
+// as the display name of the configured from address.
+type Email struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewEmail(host, port, username, password, from string) *Email {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &Email{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (n *Email) Name() string {
+	return "smtp"
+}
+
+func (n *Email) Channel() string {
+	return ChannelEmail
+}
+
+// Send delivers msg as a plain-text email. net/smtp cannot be cancelled,
+// so ctx is only checked before connecting.
+func (n *Email) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid email address: %w", err)
+	}
+
+	from := &mail.Address{Name: msg.From, Address: n.from}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{to.Address}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
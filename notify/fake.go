@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is an in-process Notifier for tests and local development. It
+// records every message it is asked to send and fails with Err when set.
+type Fake struct {
+	channel string
+
+	mu   sync.Mutex
+	Sent []Message
+	Err  error
+}
+
+func NewFake(channel string) *Fake {
+	return &Fake{channel: channel}
+}
+
+func (n *Fake) Name() string {
+	return "fake"
+}
+
+func (n *Fake) Channel() string {
+	return n.channel
+}
+
+func (n *Fake) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.Err != nil {
+		return n.Err
+	}
+
+	n.Sent = append(n.Sent, msg)
+
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (n *Fake) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]Message(nil), n.Sent...)
+}
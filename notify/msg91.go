@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const msg91URL = "https://api.msg91.com/api/sendhttp.php"
+
+// MSG91 implements Notifier for SMS through MSG91's HTTP API. Numbers are
+// sent without the leading '+', as the API expects.
+type MSG91 struct {
+	authKey  string
+	senderID string
+	route    string
+	url      string
+	client   *http.Client
+}
+
+func NewMSG91(authKey, senderID, route string) *MSG91 {
+	if route == "" {
+		route = "4" // transactional
+	}
+
+	return &MSG91{
+		authKey:  authKey,
+		senderID: senderID,
+		route:    route,
+		url:      msg91URL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *MSG91) Name() string {
+	return "msg91"
+}
+
+func (n *MSG91) Channel() string {
+	return ChannelSMS
+}
+
+func (n *MSG91) Send(ctx context.Context, msg Message) error {
+	sender := msg.From
+	if sender == "" {
+		sender = n.senderID
+	}
+
+	form := url.Values{
+		"authkey": {n.authKey},
+		"mobiles": {strings.TrimPrefix(msg.To, "+")},
+		"sender":  {sender},
+		"route":   {n.route},
+		"message": {msg.Body},
+		"unicode": {unicodeFlag(msg.Body)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("msg91 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("msg91 returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// unicodeFlag tells MSG91 to send messages outside ASCII, such as Hindi
+// templates, as Unicode SMS
+func unicodeFlag(body string) string {
+	for _, r := range body {
+		if r > 127 {
+			return "1"
+		}
+	}
+
+	return "0"
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Channels a message can be delivered over
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+	ChannelEmail    = "email"
+)
+
+var ErrNoRecipient = errors.New("no recipient address for channel")
+
+// Notifier delivers messages to customers over one channel
+type Notifier interface {
+	Name() string
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is a rendered notification. To is a phone number in E.164 form
+// for SMS and WhatsApp and an email address for email. From is the
+// restaurant's sender ID; notifiers fall back to their configured sender
+// when it is empty.
+type Message struct {
+	Channel string
+	To      string
+	From    string
+	Subject string
+	Body    string
+}
+
+// Registry holds the notifier configured for each channel
+type Registry struct {
+	notifiers map[string]Notifier
+}
+
+func NewRegistry(notifiers ...Notifier) *Registry {
+	r := &Registry{notifiers: make(map[string]Notifier, len(notifiers))}
+	for _, n := range notifiers {
+		r.notifiers[n.Channel()] = n
+	}
+
+	return r
+}
+
+// Get returns the notifier for channel, if one is configured
+func (r *Registry) Get(channel string) (Notifier, bool) {
+	n, ok := r.notifiers[channel]
+	return n, ok
+}
+
+// NewRegistryFromEnv configures the channels from the environment. SMS goes
+// through SMS_PROVIDER ("twilio", "msg91" or "fake", the default, which
+// also picks Twilio when its credentials are set). WhatsApp and email are
+// only available when their credentials are set.
+func NewRegistryFromEnv() (*Registry, error) {
+	sms, err := newSMSFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	notifiers := []Notifier{sms}
+
+	if token, phoneID := os.Getenv("WHATSAPP_ACCESS_TOKEN"), os.Getenv("WHATSAPP_PHONE_NUMBER_ID"); token != "" && phoneID != "" {
+		notifiers = append(notifiers, NewWhatsApp(token, phoneID, os.Getenv("WHATSAPP_TEMPLATE"), os.Getenv("WHATSAPP_TEMPLATE_LANGUAGE")))
+	}
+
+	if host, from := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_FROM"); host != "" && from != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		notifiers = append(notifiers, NewEmail(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from))
+	}
+
+	return NewRegistry(notifiers...), nil
+}
+
+func newSMSFromEnv() (Notifier, error) {
+	provider := os.Getenv("SMS_PROVIDER")
+	if provider == "" && os.Getenv("TWILIO_ACCOUNT_SID") != "" {
+		provider = "twilio"
+	}
+
+	switch provider {
+	case "twilio":
+		accountSID := os.Getenv("TWILIO_ACCOUNT_SID")
+		authToken := os.Getenv("TWILIO_AUTH_TOKEN")
+		fromNumber := os.Getenv("TWILIO_FROM_NUMBER")
+		if accountSID == "" || authToken == "" || fromNumber == "" {
+			return nil, fmt.Errorf("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER are required")
+		}
+
+		return NewTwilio(accountSID, authToken, fromNumber), nil
+	case "msg91":
+		authKey := os.Getenv("MSG91_AUTH_KEY")
+		senderID := os.Getenv("MSG91_SENDER_ID")
+		if authKey == "" || senderID == "" {
+			return nil, fmt.Errorf("MSG91_AUTH_KEY and MSG91_SENDER_ID are required")
+		}
+
+		return NewMSG91(authKey, senderID, os.Getenv("MSG91_ROUTE")), nil
+	case "", "fake":
+		fmt.Println("[NOTIFY] Using in-process fake SMS notifier")
+		return NewFake(ChannelSMS), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider '%s'", provider)
+	}
+}
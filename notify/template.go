@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Message templates
+const (
+	TemplateOTP            = "otp"
+	TemplateOrderAccepted  = "order_accepted"
+	TemplateOrderPreparing = "order_preparing"
+	TemplateOrderReady     = "order_ready"
+	TemplateOrderCancelled = "order_cancelled"
+)
+
+// Data is what templates are filled in with. Fields a template does not
+// use are ignored; ETA is in minutes and left out when zero.
+type Data struct {
+	Restaurant string
+	OTP        string
+	Minutes    int
+	OrderID    int
+	ETA        int
+	Reason     string
+}
+
+// DefaultLanguage is used when a template has no translation for the
+// language a restaurant asks for
+const DefaultLanguage = "en"
+
+type text struct {
+	subject string
+	body    string
+}
+
+// texts holds each template's translations by language. Bodies are kept
+// short enough for a single SMS in English.
+var texts = map[string]map[string]text{
+	TemplateOTP: {
+		"en": {"Your verification code", "{{.Restaurant}}: Your verification code is {{.OTP}}. Valid for {{.Minutes}} minutes."},
+		"hi": {"आपका सत्यापन कोड", "{{.Restaurant}}: आपका सत्यापन कोड {{.OTP}} है। यह {{.Minutes}} मिनट तक मान्य है।"},
+	},
+	TemplateOrderAccepted: {
+		"en": {"Order #{{.OrderID}} accepted", "{{.Restaurant}}: Your order #{{.OrderID}} has been accepted.{{if .ETA}} It should be ready in about {{.ETA}} minutes.{{end}}"},
+		"hi": {"ऑर्डर #{{.OrderID}} स्वीकार", "{{.Restaurant}}: आपका ऑर्डर #{{.OrderID}} स्वीकार कर लिया गया है।{{if .ETA}} यह लगभग {{.ETA}} मिनट में तैयार होगा।{{end}}"},
+	},
+	TemplateOrderPreparing: {
+		"en": {"Order #{{.OrderID}} is being prepared", "{{.Restaurant}}: Your order #{{.OrderID}} is being prepared.{{if .ETA}} It should be ready in about {{.ETA}} minutes.{{end}}"},
+		"hi": {"ऑर्डर #{{.OrderID}} तैयार हो रहा है", "{{.Restaurant}}: आपका ऑर्डर #{{.OrderID}} तैयार किया जा रहा है।{{if .ETA}} यह लगभग {{.ETA}} मिनट में तैयार होगा।{{end}}"},
+	},
+	TemplateOrderReady: {
+		"en": {"Order #{{.OrderID}} is ready", "{{.Restaurant}}: Your order #{{.OrderID}} is ready!"},
+		"hi": {"ऑर्डर #{{.OrderID}} तैयार है", "{{.Restaurant}}: आपका ऑर्डर #{{.OrderID}} तैयार है!"},
+	},
+	TemplateOrderCancelled: {
+		"en": {"Order #{{.OrderID}} cancelled", "{{.Restaurant}}: Your order #{{.OrderID}} has been cancelled.{{if .Reason}} Reason: {{.Reason}}.{{end}}"},
+		"hi": {"ऑर्डर #{{.OrderID}} रद्द", "{{.Restaurant}}: आपका ऑर्डर #{{.OrderID}} रद्द कर दिया गया है।{{if .Reason}} कारण: {{.Reason}}।{{end}}"},
+	},
+}
+
+// templates are the parsed texts, keyed by template and language
+var templates = parseTexts()
+
+type parsed struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func parseTexts() map[string]map[string]parsed {
+	all := make(map[string]map[string]parsed, len(texts))
+	for name, langs := range texts {
+		all[name] = make(map[string]parsed, len(langs))
+		for lang, t := range langs {
+			id := name + "." + lang
+			all[name][lang] = parsed{
+				subject: template.Must(template.New(id + ".subject").Parse(t.subject)),
+				body:    template.Must(template.New(id + ".body").Parse(t.body)),
+			}
+		}
+	}
+
+	return all
+}
+
+// Supported reports whether lang has translations
+func Supported(lang string) bool {
+	_, ok := texts[TemplateOTP][lang]
+	return ok
+}
+
+// Render fills in a template in lang, falling back to English, and returns
+// the subject and body
+func Render(name, lang string, data Data) (string, string, error) {
+	langs, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown message template '%s'", name)
+	}
+
+	t, ok := langs[lang]
+	if !ok {
+		t = langs[DefaultLanguage]
+	}
+
+	var subject, body strings.Builder
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	if err := t.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return subject.String(), body.String(), nil
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEveryTemplateIsTranslated(t *testing.T) {
+	data := Data{Restaurant: "Saravana Bhavan", OTP: "482913", Minutes: 5, OrderID: 42, ETA: 15, Reason: "out of stock"}
+
+	for name, langs := range texts {
+		english := langs[DefaultLanguage]
+
+		for lang := range texts[TemplateOTP] {
+			if _, ok := langs[lang]; !ok {
+				t.Errorf("%s has no %s translation", name, lang)
+				continue
+			}
+
+			subject, body, err := Render(name, lang, data)
+			if err != nil {
+				t.Errorf("%s in %s: %v", name, lang, err)
+				continue
+			}
+
+			if subject == "" || !strings.HasPrefix(body, "Saravana Bhavan: ") {
+				t.Errorf("%s in %s rendered subject %q and body %q", name, lang, subject, body)
+			}
+
+			if lang != DefaultLanguage && langs[lang].body == english.body {
+				t.Errorf("%s in %s is not translated", name, lang)
+			}
+		}
+	}
+}
+
+func TestRenderLeavesOutEmptyOptionalParts(t *testing.T) {
+	_, body, err := Render(TemplateOrderAccepted, "hi", Data{Restaurant: "Saravana Bhavan", OrderID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Saravana Bhavan: आपका ऑर्डर #42 स्वीकार कर लिया गया है।"; body != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+}
+
+func TestRenderFallsBackToEnglish(t *testing.T) {
+	_, body, err := Render(TemplateOTP, "fr", Data{Restaurant: "Saravana Bhavan", OTP: "482913", Minutes: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Saravana Bhavan: Your verification code is 482913. Valid for 5 minutes."; body != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+
+	if _, _, err := Render("unknown", "en", Data{}); err == nil {
+		t.Error("unknown template rendered")
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twilio/twilio-go"
+	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+)
+
+// Twilio implements Notifier for SMS through Twilio's Messages API
+type Twilio struct {
+	client     *twilio.RestClient
+	fromNumber string
+}
+
+func NewTwilio(accountSID, authToken, fromNumber string) *Twilio {
+	client := twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: accountSID,
+		Password: authToken,
+	})
+
+	return &Twilio{client: client, fromNumber: fromNumber}
+}
+
+func (n *Twilio) Name() string {
+	return "twilio"
+}
+
+func (n *Twilio) Channel() string {
+	return ChannelSMS
+}
+
+// Send sends msg from the restaurant's sender ID, which Twilio accepts as an
+// alphanumeric sender where the destination country allows it
+func (n *Twilio) Send(ctx context.Context, msg Message) error {
+	from := msg.From
+	if from == "" {
+		from = n.fromNumber
+	}
+
+	params := &twilioApi.CreateMessageParams{}
+	params.SetTo(msg.To)
+	params.SetFrom(from)
+	params.SetBody(msg.Body)
+
+	if _, err := n.client.Api.CreateMessage(params); err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const whatsAppBaseURL = "https://graph.facebook.com/v19.0"
+
+// WhatsApp implements Notifier through the WhatsApp Business Cloud API.
+// Free-form text is only delivered inside a customer's 24-hour service
+// window, so when template is set every message is sent as that approved
+// template with the rendered body as its single parameter.
+type WhatsApp struct {
+	accessToken   string
+	phoneNumberID string
+	template      string
+	language      string
+	baseURL       string
+	client        *http.Client
+}
+
+func NewWhatsApp(accessToken, phoneNumberID, template, language string) *WhatsApp {
+	if language == "" {
+		language = "en"
+	}
+
+	return &WhatsApp{
+		accessToken:   accessToken,
+		phoneNumberID: phoneNumberID,
+		template:      template,
+		language:      language,
+		baseURL:       whatsAppBaseURL,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WhatsApp) Name() string {
+	return "whatsapp"
+}
+
+func (n *WhatsApp) Channel() string {
+	return ChannelWhatsApp
+}
+
+func (n *WhatsApp) Send(ctx context.Context, msg Message) error {
+	body := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(msg.To, "+"),
+	}
+
+	if n.template != "" {
+		body["type"] = "template"
+		body["template"] = map[string]interface{}{
+			"name":     n.template,
+			"language": map[string]string{"code": n.language},
+			"components": []map[string]interface{}{{
+				"type":       "body",
+				"parameters": []map[string]string{{"type": "text", "text": msg.Body}},
+			}},
+		}
+	} else {
+		body["type"] = "text"
+		body["text"] = map[string]string{"body": msg.Body}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.baseURL+"/"+n.phoneNumberID+"/messages", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+n.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("whatsapp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		return fmt.Errorf("whatsapp returned %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Fake is an in-process Provider for tests and local development. Webhooks
+// are JSON-encoded WebhookEvents signed with HMAC-SHA256 over the body.
+type Fake struct {
+	secret []byte
+
+	mu      sync.Mutex
+	seq     int
+	Intents []IntentRequest
+	Refunds []RefundRequest
+}
+
+func NewFake(secret string) *Fake {
+	return &Fake{secret: []byte(secret)}
+}
+
+func (p *Fake) Name() string {
+	return "fake"
+}
+
+func (p *Fake) ClientKey() string {
+	return "fake"
+}
+
+func (p *Fake) SignatureHeader() string {
+	return "X-Fake-Signature"
+}
+
+func (p *Fake) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	p.Intents = append(p.Intents, req)
+
+	return &Intent{
+		ProviderOrderID: fmt.Sprintf("fake_order_%d", p.seq),
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+	}, nil
+}
+
+func (p *Fake) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(p.sign(payload)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var evt WebhookEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &evt, nil
+}
+
+func (p *Fake) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	p.Refunds = append(p.Refunds, req)
+
+	return &Refund{
+		ProviderRefundID: fmt.Sprintf("fake_refund_%d", p.seq),
+		Amount:           req.Amount,
+		Status:           "processed",
+	}, nil
+}
+
+// SignWebhook builds a signed webhook body for evt, as the gateway would send it
+func (p *Fake) SignWebhook(evt WebhookEvent) ([]byte, string, error) {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, p.sign(payload), nil
+}
+
+func (p *Fake) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,167 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const razorpayBaseURL = "https://api.razorpay.com"
+
+// Razorpay implements Provider against the Razorpay Orders API
+type Razorpay struct {
+	keyID         string
+	keySecret     string
+	webhookSecret string
+	baseURL       string
+	client        *http.Client
+}
+
+func NewRazorpay(keyID, keySecret, webhookSecret string) *Razorpay {
+	return &Razorpay{
+		keyID:         keyID,
+		keySecret:     keySecret,
+		webhookSecret: webhookSecret,
+		baseURL:       razorpayBaseURL,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Razorpay) Name() string {
+	return "razorpay"
+}
+
+func (p *Razorpay) ClientKey() string {
+	return p.keyID
+}
+
+func (p *Razorpay) SignatureHeader() string {
+	return "X-Razorpay-Signature"
+}
+
+func (p *Razorpay) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	var resp struct {
+		ID       string `json:"id"`
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+	}
+
+	body := map[string]interface{}{
+		"amount":   req.Amount,
+		"currency": req.Currency,
+		"receipt":  req.Reference,
+	}
+
+	if err := p.post(ctx, "/v1/orders", body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Intent{
+		ProviderOrderID: resp.ID,
+		Amount:          resp.Amount,
+		Currency:        resp.Currency,
+	}, nil
+}
+
+func (p *Razorpay) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var body struct {
+		Event   string `json:"event"`
+		Payload struct {
+			Payment struct {
+				Entity struct {
+					ID      string `json:"id"`
+					OrderID string `json:"order_id"`
+					Amount  int64  `json:"amount"`
+				} `json:"entity"`
+			} `json:"payment"`
+		} `json:"payload"`
+	}
+
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	entity := body.Payload.Payment.Entity
+	evt := &WebhookEvent{
+		ProviderOrderID:   entity.OrderID,
+		ProviderPaymentID: entity.ID,
+		Amount:            entity.Amount,
+	}
+
+	switch body.Event {
+	case "payment.captured", "order.paid":
+		evt.Type = EventCaptured
+	case "payment.failed":
+		evt.Type = EventFailed
+	case "refund.processed":
+		evt.Type = EventRefunded
+	}
+
+	return evt, nil
+}
+
+func (p *Razorpay) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	var resp struct {
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+		Status string `json:"status"`
+	}
+
+	body := map[string]interface{}{
+		"amount": req.Amount,
+		"notes":  map[string]string{"reference": req.Reference},
+	}
+
+	if err := p.post(ctx, "/v1/payments/"+req.ProviderPaymentID+"/refund", body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Refund{ProviderRefundID: resp.ID, Amount: resp.Amount, Status: resp.Status}, nil
+}
+
+func (p *Razorpay) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.keyID, p.keySecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("razorpay request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		return fmt.Errorf("razorpay returned %d: %s", resp.StatusCode, apiErr.Error.Description)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+type contextKey string
+
+const webhookContextKey contextKey = "payment_webhook"
+
+// maxWebhookBody bounds the payload kept in memory for signature verification
+const maxWebhookBody = 1 << 20
+
+type webhook struct {
+	payload   []byte
+	signature string
+}
+
+// WebhookCapture keeps the raw body and signature header of POST requests to
+// path in the request context. Signatures are computed over the exact bytes
+// sent, which GoFr handlers can't otherwise see.
+func WebhookCapture(path, signatureHeader string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != path {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+			if err != nil {
+				http.Error(w, `{"error":"failed to read request body"}`, http.StatusBadRequest)
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(payload))
+
+			ctx := context.WithValue(r.Context(), webhookContextKey, &webhook{
+				payload:   payload,
+				signature: r.Header.Get(signatureHeader),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WebhookFromContext returns the raw payload and signature captured by WebhookCapture
+func WebhookFromContext(ctx context.Context) ([]byte, string, bool) {
+	wh, ok := ctx.Value(webhookContextKey).(*webhook)
+	if !ok {
+		return nil, "", false
+	}
+
+	return wh.payload, wh.signature, true
+}
@@ -0,0 +1,100 @@
+package receipt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency describes how amounts in a currency are written. ASCII is the
+// prefix used where the symbol cannot be printed, such as on thermal
+// printers' built-in code pages.
+type Currency struct {
+	Code     string
+	Symbol   string
+	ASCII    string
+	Decimals int
+	Indian   bool // group digits as 12,34,567 rather than 1,234,567
+}
+
+var currencies = map[string]Currency{
+	"INR": {Code: "INR", Symbol: "₹", ASCII: "Rs.", Decimals: 2, Indian: true},
+	"USD": {Code: "USD", Symbol: "$", ASCII: "$", Decimals: 2},
+	"EUR": {Code: "EUR", Symbol: "€", ASCII: "EUR ", Decimals: 2},
+	"GBP": {Code: "GBP", Symbol: "£", ASCII: "GBP ", Decimals: 2},
+	"AED": {Code: "AED", Symbol: "AED ", ASCII: "AED ", Decimals: 2},
+	"SGD": {Code: "SGD", Symbol: "S$", ASCII: "S$", Decimals: 2},
+	"JPY": {Code: "JPY", Symbol: "¥", ASCII: "JPY ", Decimals: 0},
+}
+
+// CurrencyFor returns the format for an ISO 4217 code. Unknown codes are
+// written with the code as prefix and two decimals.
+func CurrencyFor(code string) Currency {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if c, ok := currencies[code]; ok {
+		return c
+	}
+
+	return Currency{Code: code, Symbol: code + " ", ASCII: code + " ", Decimals: 2}
+}
+
+// Format writes amount with the currency symbol, or the ASCII prefix when
+// symbol is false
+func (c Currency) Format(amount float64, symbol bool) string {
+	if symbol {
+		return c.format(amount, c.Symbol)
+	}
+
+	return c.format(amount, c.ASCII)
+}
+
+// Number writes amount with grouping and decimals but no currency prefix,
+// for columns of amounts under a currency heading
+func (c Currency) Number(amount float64) string {
+	return c.format(amount, "")
+}
+
+func (c Currency) format(amount float64, prefix string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	// Amounts that round to zero are written without a sign
+	if amount < 0.5*math.Pow10(-c.Decimals) {
+		sign = ""
+	}
+
+	s := strconv.FormatFloat(amount, 'f', c.Decimals, 64)
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if frac != "" {
+		frac = "." + frac
+	}
+
+	return sign + prefix + c.group(whole) + frac
+}
+
+// group inserts thousands separators into a run of digits
+func (c Currency) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if c.Indian {
+		size = 2
+	}
+
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+
+	parts = append([]string{head}, parts...)
+
+	return strings.Join(append(parts, tail), ",")
+}
@@ -0,0 +1,248 @@
+// Package receipt renders orders as customer receipts and kitchen tickets
+// for thermal printers (ESC/POS), PDF and HTML.
+package receipt
+
+import (
+	"fmt"
+	"image"
+	"qr-dinein-backend/model"
+	"strings"
+	"time"
+)
+
+// Paper is a thermal paper roll: the characters per line in the printer's
+// default font and the printable width in dots
+type Paper struct {
+	Columns int
+	Dots    int
+}
+
+var (
+	Paper58 = Paper{Columns: 32, Dots: 384}
+	Paper80 = Paper{Columns: 48, Dots: 576}
+)
+
+// PaperFor returns the paper for a roll width in millimetres
+func PaperFor(mm int) (Paper, error) {
+	switch mm {
+	case 58:
+		return Paper58, nil
+	case 80:
+		return Paper80, nil
+	default:
+		return Paper{}, fmt.Errorf("paper width must be 58 or 80")
+	}
+}
+
+// Header identifies the restaurant at the top of a receipt
+type Header struct {
+	Name    string
+	Address string
+	Phone   string
+	LogoURL string
+}
+
+// Line is one item of a receipt or kitchen ticket
+type Line struct {
+	Name     string
+	Options  []string
+	Quantity int
+	Amount   float64
+}
+
+// Total is one row of the totals block (subtotal, service charge, a tax ...)
+type Total struct {
+	Label  string
+	Amount float64
+}
+
+// Ticket is the part of a kitchen printout for one station
+type Ticket struct {
+	Station string
+	Items   []Line
+}
+
+// Document is an order laid out for printing. Receipts use Lines and
+// Totals; kitchen printouts use Tickets.
+type Document struct {
+	Kind     string
+	Header   Header
+	Logo     image.Image // decoded logo for ESC/POS and PDF; nil prints none
+	Currency Currency
+
+	OrderID       int
+	Table         string
+	Customer      string
+	PlacedAt      time.Time
+	PaymentStatus string
+	Instructions  string
+	Cancelled     bool
+	CancelReason  string
+
+	Lines      []Line
+	Totals     []Total
+	GrandTotal float64
+
+	Tickets []Ticket
+}
+
+// NewReceipt lays out the customer receipt for an order from its stored
+// bill. Orders placed before bills were stored are itemised from their
+// items and total.
+func NewReceipt(r *model.Restaurant, o *model.Order) *Document {
+	d := newDocument(model.PrintReceipt, r, o)
+
+	if o.Bill == nil {
+		for _, item := range o.Items {
+			if item.Status == model.ItemVoided {
+				continue
+			}
+
+			d.Lines = append(d.Lines, Line{
+				Name:     item.Name,
+				Options:  optionNames(item),
+				Quantity: item.Quantity,
+				Amount:   item.Price * float64(item.Quantity),
+			})
+		}
+
+		d.GrandTotal = o.Total
+
+		return d
+	}
+
+	if o.Bill.Currency != "" {
+		d.Currency = CurrencyFor(o.Bill.Currency)
+	}
+
+	for _, l := range o.Bill.Lines {
+		d.Lines = append(d.Lines, Line{Name: l.Name, Options: l.Options, Quantity: l.Quantity, Amount: l.Amount})
+	}
+
+	d.Totals = append(d.Totals, Total{Label: "Subtotal", Amount: o.Bill.Subtotal})
+
+	if o.Bill.ServiceCharge != 0 {
+		label := fmt.Sprintf("Service charge (%s%%)", formatRate(o.Bill.ServiceChargeRate))
+		d.Totals = append(d.Totals, Total{Label: label, Amount: o.Bill.ServiceCharge})
+	}
+
+	for _, tax := range o.Bill.Taxes {
+		label := fmt.Sprintf("%s (%s%%)", tax.Name, formatRate(tax.Rate))
+		d.Totals = append(d.Totals, Total{Label: label, Amount: tax.Amount})
+	}
+
+	if o.Bill.Rounding != 0 {
+		d.Totals = append(d.Totals, Total{Label: "Rounding", Amount: o.Bill.Rounding})
+	}
+
+	d.GrandTotal = o.Bill.GrandTotal
+
+	return d
+}
+
+// NewKitchen lays out one kitchen ticket per station ticket of the order,
+// leaving out voided items. stations maps station IDs to names; when
+// stationID is set only that station's ticket is included, 0 selecting the
+// ticket of items routed to no station.
+func NewKitchen(r *model.Restaurant, o *model.Order, stations map[int]string, stationID *int) *Document {
+	d := newDocument(model.PrintKitchen, r, o)
+
+	items := make(map[int]model.OrderItem, len(o.Items))
+	for _, item := range o.Items {
+		items[item.LineID] = item
+	}
+
+	tickets := o.Tickets
+	if len(tickets) == 0 {
+		// Orders without station tickets go to the kitchen as a whole
+		t := model.Ticket{}
+		for _, item := range o.Items {
+			t.LineIDs = append(t.LineIDs, item.LineID)
+		}
+		tickets = []model.Ticket{t}
+	}
+
+	for _, t := range tickets {
+		ticketStation := 0
+		if t.StationID != nil {
+			ticketStation = *t.StationID
+		}
+
+		if stationID != nil && ticketStation != *stationID {
+			continue
+		}
+
+		ticket := Ticket{Station: "Kitchen"}
+		if name := stations[ticketStation]; name != "" {
+			ticket.Station = name
+		}
+
+		for _, lineID := range t.LineIDs {
+			item, ok := items[lineID]
+			if !ok || item.Status == model.ItemVoided {
+				continue
+			}
+
+			ticket.Items = append(ticket.Items, Line{Name: item.Name, Options: optionNames(item), Quantity: item.Quantity})
+		}
+
+		if len(ticket.Items) > 0 {
+			d.Tickets = append(d.Tickets, ticket)
+		}
+	}
+
+	return d
+}
+
+func newDocument(kind string, r *model.Restaurant, o *model.Order) *Document {
+	d := &Document{
+		Kind: kind,
+		Header: Header{
+			Name:    r.Name,
+			Address: r.Address,
+			Phone:   r.Phone,
+			LogoURL: r.Logo,
+		},
+		Currency:      CurrencyFor(r.Currency),
+		OrderID:       o.ID,
+		Customer:      o.CustomerName,
+		PlacedAt:      o.CreatedAt,
+		PaymentStatus: o.PaymentStatus,
+		Instructions:  strings.TrimSpace(o.SpecialInstructions),
+	}
+
+	if o.TableNumber != nil {
+		d.Table = *o.TableNumber
+	}
+
+	if o.Status == "cancelled" {
+		d.Cancelled = true
+		if o.Cancellation != nil {
+			d.CancelReason = strings.ReplaceAll(o.Cancellation.Reason, "_", " ")
+		}
+	}
+
+	return d
+}
+
+// tableLabel says where the order is served
+func (d *Document) tableLabel() string {
+	if d.Table == "" {
+		return "Takeaway"
+	}
+
+	return "Table " + d.Table
+}
+
+func optionNames(item model.OrderItem) []string {
+	var names []string
+	for _, opt := range item.Options {
+		names = append(names, opt.Name)
+	}
+
+	return names
+}
+
+func formatRate(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".")
+}
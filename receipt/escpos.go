@@ -0,0 +1,103 @@
+package receipt
+
+import (
+	"bytes"
+	"image"
+)
+
+// ESC/POS commands understood by common 58/80mm thermal printers
+var (
+	escInit        = []byte{0x1b, '@'}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escSizeNormal  = []byte{0x1d, '!', 0x00}
+	escSizeTall    = []byte{0x1d, '!', 0x01}
+	escFeedAndCut  = []byte{0x1b, 'd', 4, 0x1d, 'V', 1}
+)
+
+// ESC/POS logos are printed at most half the paper wide and this many dots high
+const escposLogoHeight = 160
+
+// ESCPOS renders the document as an ESC/POS byte stream for paper. Each
+// page (the receipt, or one kitchen ticket) ends with a paper cut. Text is
+// sent as ASCII as printers' built-in code pages vary; characters outside
+// it print as '?'.
+func ESCPOS(d *Document, paper Paper) []byte {
+	var buf bytes.Buffer
+	buf.Write(escInit)
+
+	for _, page := range d.layout(paper.Columns, false) {
+		for _, l := range page {
+			if l.logo {
+				buf.Write(escAlignCenter)
+				writeRaster(&buf, fit(d.Logo, paper.Dots/2, escposLogoHeight))
+				buf.Write(escAlignLeft)
+				continue
+			}
+
+			if l.center {
+				buf.Write(escAlignCenter)
+			}
+			if l.bold {
+				buf.Write(escBoldOn)
+			}
+			if l.tall {
+				buf.Write(escSizeTall)
+			}
+
+			buf.WriteString(toASCII(l.text))
+			buf.WriteByte('\n')
+
+			if l.tall {
+				buf.Write(escSizeNormal)
+			}
+			if l.bold {
+				buf.Write(escBoldOff)
+			}
+			if l.center {
+				buf.Write(escAlignLeft)
+			}
+		}
+
+		buf.Write(escFeedAndCut)
+	}
+
+	return buf.Bytes()
+}
+
+// writeRaster prints img with GS v 0, one bit per dot, dark pixels black
+func writeRaster(buf *bytes.Buffer, img *image.Gray) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+
+	rowBytes := (w + 7) / 8
+	buf.Write([]byte{0x1d, 'v', '0', 0, byte(rowBytes), byte(rowBytes >> 8), byte(h), byte(h >> 8)})
+
+	for y := 0; y < h; y++ {
+		row := make([]byte, rowBytes)
+		for x := 0; x < w; x++ {
+			if img.GrayAt(x, y).Y < 128 {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		buf.Write(row)
+	}
+
+	buf.WriteByte('\n')
+}
+
+func toASCII(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+
+	return string(out)
+}
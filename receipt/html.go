@@ -0,0 +1,106 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"qr-dinein-backend/model"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money": func(d *Document, amount float64) string { return d.Currency.Format(amount, true) },
+	"upper": func(s string) string { return strings.ToUpper(strings.ReplaceAll(s, "_", " ")) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Kitchen}}Kitchen ticket{{else}}Receipt{{end}} #{{.Doc.OrderID}}</title>
+<style>
+body { font-family: "Courier New", monospace; font-size: 13px; max-width: 320px; margin: 16px auto; color: #000; }
+header, .center { text-align: center; }
+header img { max-width: 160px; max-height: 80px; }
+h1 { font-size: 18px; margin: 4px 0; }
+table { width: 100%; border-collapse: collapse; }
+td, th { padding: 2px 0; vertical-align: top; }
+th { text-align: left; border-bottom: 1px dashed #000; }
+.num { text-align: right; white-space: nowrap; }
+.option { padding-left: 12px; font-size: 12px; }
+.total td { font-weight: bold; font-size: 16px; border-top: 1px solid #000; border-bottom: 1px solid #000; }
+.ticket { border-bottom: 1px dashed #000; padding-bottom: 8px; margin-bottom: 8px; page-break-after: always; }
+.item { font-size: 16px; font-weight: bold; }
+.status { font-weight: bold; margin-top: 8px; }
+</style>
+</head>
+<body>
+{{- $d := .Doc}}
+{{- if .Kitchen}}
+{{- range $d.Tickets}}
+<section class="ticket">
+<h1 class="center">{{upper .Station}}</h1>
+{{- if $d.Cancelled}}
+<p class="center status">CANCELLED</p>
+{{- end}}
+<p>Order #{{$d.OrderID}} &middot; {{$.Table}}<br>{{$d.PlacedAt.Format "02 Jan 2006 15:04"}}</p>
+{{- range .Items}}
+<div class="item">{{.Quantity}} x {{.Name}}</div>
+{{- range .Options}}
+<div class="option">+ {{.}}</div>
+{{- end}}
+{{- end}}
+{{- if $d.Instructions}}
+<p><strong>Note:</strong> {{$d.Instructions}}</p>
+{{- end}}
+</section>
+{{- end}}
+{{- else}}
+<header>
+{{- if $d.Header.LogoURL}}
+<img src="{{$d.Header.LogoURL}}" alt="">
+{{- end}}
+<h1>{{$d.Header.Name}}</h1>
+{{- if $d.Header.Address}}
+<div>{{$d.Header.Address}}</div>
+{{- end}}
+{{- if $d.Header.Phone}}
+<div>Tel: {{$d.Header.Phone}}</div>
+{{- end}}
+</header>
+<p>Order #{{$d.OrderID}} &middot; {{.Table}}<br>{{$d.PlacedAt.Format "02 Jan 2006 15:04"}}
+{{- if $d.Customer}}<br>Customer: {{$d.Customer}}{{end}}</p>
+<table>
+<tr><th>Item</th><th class="num">Qty</th><th class="num">Amount</th></tr>
+{{- range $d.Lines}}
+<tr><td>{{.Name}}{{range .Options}}<div class="option">+ {{.}}</div>{{end}}</td><td class="num">{{.Quantity}}</td><td class="num">{{money $d .Amount}}</td></tr>
+{{- end}}
+{{- range $d.Totals}}
+<tr><td colspan="2">{{.Label}}</td><td class="num">{{money $d .Amount}}</td></tr>
+{{- end}}
+<tr class="total"><td colspan="2">TOTAL</td><td class="num">{{money $d $d.GrandTotal}}</td></tr>
+</table>
+{{- if $d.Cancelled}}
+<p class="center status">CANCELLED{{if $d.CancelReason}} - {{upper $d.CancelReason}}{{end}}</p>
+{{- else if $d.PaymentStatus}}
+<p class="center status">{{upper $d.PaymentStatus}}</p>
+{{- end}}
+<p class="center">Thank you!</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders the document as a standalone HTML page for printing from a
+// browser or attaching to an email. The logo is linked, not embedded.
+func HTML(d *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		Doc     *Document
+		Kitchen bool
+		Table   string
+	}{Doc: d, Kitchen: d.Kind == model.PrintKitchen, Table: d.tableLabel()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
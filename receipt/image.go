@@ -0,0 +1,38 @@
+package receipt
+
+import (
+	"image"
+	"image/color"
+)
+
+// fit scales img down (nearest neighbour) to fit within maxW x maxH and
+// flattens any transparency onto white, as receipts are printed on white
+// paper. Images that already fit keep their size.
+func fit(img image.Image, maxW, maxH int) *image.Gray {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return image.NewGray(image.Rect(0, 0, 0, 0))
+	}
+
+	if w > maxW {
+		h, w = h*maxW/w, maxW
+	}
+	if h > maxH {
+		w, h = w*maxH/h, maxH
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	out := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h)).(color.NRGBA)
+			gray := color.GrayModel.Convert(color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}).(color.Gray).Y
+
+			// Blend with the white paper by the pixel's opacity
+			out.SetGray(x, y, color.Gray{Y: uint8((int(gray)*int(c.A) + 255*(255-int(c.A))) / 255)})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,240 @@
+package receipt
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"strings"
+	"unicode/utf8"
+)
+
+// textLine is one line of a fixed-width printout. A logo line carries no
+// text and marks where the restaurant logo is drawn.
+type textLine struct {
+	text   string
+	center bool
+	bold   bool
+	tall   bool // double height
+	logo   bool
+}
+
+// layout writes the document as pages of fixed-width text, cols characters
+// wide. A receipt is one page; each kitchen ticket is a page of its own.
+// The currency symbol is used only when symbol is true.
+func (d *Document) layout(cols int, symbol bool) [][]textLine {
+	if d.Kind == model.PrintKitchen {
+		pages := make([][]textLine, 0, len(d.Tickets))
+		for _, t := range d.Tickets {
+			pages = append(pages, d.layoutTicket(t, cols))
+		}
+
+		return pages
+	}
+
+	return [][]textLine{d.layoutReceipt(cols, symbol)}
+}
+
+func (d *Document) layoutReceipt(cols int, symbol bool) []textLine {
+	var lines []textLine
+
+	if d.Logo != nil {
+		lines = append(lines, textLine{logo: true})
+	}
+
+	lines = appendWrapped(lines, d.Header.Name, cols, textLine{center: true, bold: true, tall: true})
+	lines = appendWrapped(lines, d.Header.Address, cols, textLine{center: true})
+	if d.Header.Phone != "" {
+		lines = appendWrapped(lines, "Tel: "+d.Header.Phone, cols, textLine{center: true})
+	}
+
+	lines = append(lines, rule('-', cols))
+	lines = append(lines, d.orderLines(cols, false)...)
+	if d.Customer != "" {
+		lines = appendWrapped(lines, "Customer: "+d.Customer, cols, textLine{})
+	}
+
+	lines = append(lines, rule('-', cols))
+
+	amountWidth := 9
+	if cols >= 48 {
+		amountWidth = 11
+	}
+
+	lines = append(lines, textLine{text: itemRow("Item", "Qty", d.Currency.Code, cols, amountWidth), bold: true})
+	for _, l := range d.Lines {
+		lines = append(lines, itemLines(l, d.Currency.Number(l.Amount), cols, amountWidth)...)
+	}
+
+	lines = append(lines, rule('-', cols))
+	for _, t := range d.Totals {
+		lines = append(lines, pair(t.Label, d.Currency.Number(t.Amount), cols, textLine{})...)
+	}
+
+	lines = append(lines, rule('=', cols))
+	lines = append(lines, pair("TOTAL", d.Currency.Format(d.GrandTotal, symbol), cols, textLine{bold: true, tall: true})...)
+	lines = append(lines, rule('=', cols))
+
+	if d.Cancelled {
+		status := "CANCELLED"
+		if d.CancelReason != "" {
+			status += " - " + strings.ToUpper(d.CancelReason)
+		}
+		lines = appendWrapped(lines, status, cols, textLine{center: true, bold: true})
+	} else if d.PaymentStatus != "" {
+		lines = appendWrapped(lines, strings.ToUpper(strings.ReplaceAll(d.PaymentStatus, "_", " ")), cols, textLine{center: true, bold: true})
+	}
+
+	lines = append(lines, textLine{}, textLine{text: "Thank you!", center: true})
+
+	return lines
+}
+
+func (d *Document) layoutTicket(t Ticket, cols int) []textLine {
+	var lines []textLine
+
+	lines = appendWrapped(lines, strings.ToUpper(t.Station), cols, textLine{center: true, bold: true, tall: true})
+	if d.Cancelled {
+		lines = append(lines, textLine{text: "*** CANCELLED ***", center: true, bold: true, tall: true})
+	}
+
+	lines = append(lines, rule('-', cols))
+	lines = append(lines, d.orderLines(cols, true)...)
+	lines = append(lines, rule('-', cols))
+
+	for _, item := range t.Items {
+		lines = appendWrapped(lines, fmt.Sprintf("%d x %s", item.Quantity, item.Name), cols, textLine{bold: true, tall: true})
+		for _, opt := range item.Options {
+			lines = appendWrapped(lines, "    + "+opt, cols, textLine{})
+		}
+	}
+
+	if d.Instructions != "" {
+		lines = append(lines, rule('-', cols))
+		lines = appendWrapped(lines, "Note: "+d.Instructions, cols, textLine{bold: true})
+	}
+
+	return lines
+}
+
+// orderLines identifies the order: number, table and when it was placed
+func (d *Document) orderLines(cols int, bold bool) []textLine {
+	lines := pair(fmt.Sprintf("Order #%d", d.OrderID), d.tableLabel(), cols, textLine{bold: bold})
+
+	return append(lines, textLine{text: d.PlacedAt.Format("02 Jan 2006 15:04")})
+}
+
+func rule(c rune, cols int) textLine {
+	return textLine{text: strings.Repeat(string(c), cols)}
+}
+
+// pair puts left and right on one line, or on two when they do not fit
+func pair(left, right string, cols int, style textLine) []textLine {
+	gap := cols - width(left) - width(right)
+	if gap >= 1 {
+		style.text = left + strings.Repeat(" ", gap) + right
+		return []textLine{style}
+	}
+
+	lines := appendWrapped(nil, left, cols, style)
+	style.text = padLeft(right, cols)
+
+	return append(lines, style)
+}
+
+// itemRow puts an item's name, quantity and amount in columns. The name
+// takes what the quantity and amount leave of the line.
+func itemRow(name, qty, amount string, cols, amountWidth int) string {
+	right := padLeft(qty, 4) + " " + padLeft(amount, amountWidth)
+
+	return padRight(name, cols-width(right)) + right
+}
+
+// itemLines writes a receipt line, wrapping its name and options under the
+// name column
+func itemLines(l Line, amount string, cols, amountWidth int) []textLine {
+	nameWidth := cols - max(4+1+amountWidth, 5+width(amount))
+	name := wrap(l.Name, nameWidth)
+
+	lines := []textLine{{text: itemRow(name[0], fmt.Sprint(l.Quantity), amount, cols, amountWidth)}}
+	for _, rest := range name[1:] {
+		lines = append(lines, textLine{text: rest})
+	}
+
+	for _, opt := range l.Options {
+		lines = appendWrapped(lines, "  + "+opt, nameWidth, textLine{})
+	}
+
+	return lines
+}
+
+func appendWrapped(lines []textLine, text string, cols int, style textLine) []textLine {
+	if strings.TrimSpace(text) == "" {
+		return lines
+	}
+
+	for _, l := range wrap(text, cols) {
+		style.text = l
+		lines = append(lines, style)
+	}
+
+	return lines
+}
+
+// wrap breaks text into lines of at most cols characters at spaces, cutting
+// words longer than a line. Leading indentation is kept on the first line.
+func wrap(text string, cols int) []string {
+	indent := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	words := strings.Fields(text)
+
+	var lines []string
+	current := indent
+	for _, w := range words {
+		for width(w) > cols {
+			if strings.TrimSpace(current) != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+
+			head := string([]rune(w)[:cols])
+			lines = append(lines, head)
+			w = string([]rune(w)[cols:])
+		}
+
+		switch {
+		case strings.TrimSpace(current) == "" && width(current)+width(w) <= cols:
+			current += w
+		case strings.TrimSpace(current) == "":
+			current = w
+		case width(current)+1+width(w) <= cols:
+			current += " " + w
+		default:
+			lines = append(lines, current)
+			current = w
+		}
+	}
+
+	if strings.TrimSpace(current) != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func padRight(s string, n int) string {
+	if w := width(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+
+	return s
+}
+
+func padLeft(s string, n int) string {
+	if w := width(s); w < n {
+		return strings.Repeat(" ", n-w) + s
+	}
+
+	return s
+}
@@ -0,0 +1,231 @@
+package receipt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// PDF receipts are laid out like an 80mm roll in Courier so they match the
+// printed ticket: 48 columns of 7pt text on a 226.77pt (80mm) wide page
+// whose height follows the content.
+const (
+	pdfPageWidth  = 226.77
+	pdfFontSize   = 7.0
+	pdfCharWidth  = pdfFontSize * 0.6 // Courier advance width
+	pdfLineHeight = 9.0
+	pdfMargin     = 12.0
+	pdfLogoWidth  = 120.0
+	pdfLogoHeight = 60.0
+)
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding has
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// PDF renders the document as a PDF with one page per receipt or kitchen
+// ticket, using the standard Courier fonts so no fonts are embedded.
+// Characters outside WinAnsiEncoding print as '?'.
+func PDF(d *Document) []byte {
+	cols := Paper80.Columns
+	pages := d.layout(cols, encodable(d.Currency.Symbol))
+
+	var w pdfWriter
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts; the logo and the
+	// pages follow
+	next := 5
+	logoRef := 0
+
+	// The logo is drawn at two pixels per point
+	var logo *image.Gray
+	logoWidth, logoHeight := 0.0, 0.0
+	if d.Logo != nil {
+		if img := fit(d.Logo, int(pdfLogoWidth*2), int(pdfLogoHeight*2)); img.Bounds().Dx() > 0 {
+			logo = img
+			logoWidth, logoHeight = float64(img.Bounds().Dx())/2, float64(img.Bounds().Dy())/2
+			logoRef = next
+			next++
+		}
+	}
+
+	pageRefs := make([]int, len(pages))
+	for i := range pages {
+		pageRefs[i] = next
+		next += 2 // page and its content stream
+	}
+
+	kids := make([]string, len(pageRefs))
+	for i, ref := range pageRefs {
+		kids[i] = fmt.Sprintf("%d 0 R", ref)
+	}
+
+	w.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	w.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	if logoRef != 0 {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(logo.Pix)
+		zw.Close()
+
+		w.stream(logoRef, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode",
+			logo.Bounds().Dx(), logo.Bounds().Dy()), z.Bytes())
+	}
+
+	resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if logoRef != 0 {
+		resources += fmt.Sprintf(" /XObject << /Logo %d 0 R >>", logoRef)
+	}
+
+	for i, page := range pages {
+		height := 2 * pdfMargin
+		for _, l := range page {
+			switch {
+			case l.logo:
+				height += logoHeight + pdfLineHeight/2
+			case l.tall:
+				height += 2 * pdfLineHeight
+			default:
+				height += pdfLineHeight
+			}
+		}
+
+		var content bytes.Buffer
+		y := height - pdfMargin
+		for _, l := range page {
+			if l.logo {
+				y -= logoHeight
+				fmt.Fprintf(&content, "q %.2f 0 0 %.2f %.2f %.2f cm /Logo Do Q\n",
+					logoWidth, logoHeight, (pdfPageWidth-logoWidth)/2, y)
+				y -= pdfLineHeight / 2
+				continue
+			}
+
+			scale := 1.0
+			if l.tall {
+				scale = 2
+			}
+			y -= pdfLineHeight * scale
+
+			text := strings.TrimRight(l.text, " ")
+			if text == "" {
+				continue
+			}
+
+			x := pdfMargin
+			if l.center {
+				x += float64(cols-width(text)) / 2 * pdfCharWidth
+			}
+
+			font := "F1"
+			if l.bold {
+				font = "F2"
+			}
+
+			// The baseline sits a little above the bottom of the line
+			fmt.Fprintf(&content, "BT /%s %.1f Tf 1 0 0 %.1f %.2f %.2f Tm (%s) Tj ET\n",
+				font, pdfFontSize, scale, x, y+2*scale, pdfString(text))
+		}
+
+		w.object(pageRefs[i], fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents %d 0 R >>",
+			pdfPageWidth, height, resources, pageRefs[i]+1))
+		w.stream(pageRefs[i]+1, "", content.Bytes())
+	}
+
+	return w.finish(next)
+}
+
+// pdfWriter writes numbered objects and keeps their offsets for the
+// cross-reference table
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *pdfWriter) object(n int, body string) {
+	w.begin(n)
+	w.buf.WriteString(body)
+	w.buf.WriteString("\nendobj\n")
+}
+
+func (w *pdfWriter) stream(n int, dict string, data []byte) {
+	w.begin(n)
+	fmt.Fprintf(&w.buf, "<< %s /Length %d >>\nstream\n", strings.TrimSpace(dict), len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *pdfWriter) begin(n int) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", n)
+}
+
+// finish writes the cross-reference table and trailer for objects 1..size-1
+func (w *pdfWriter) finish(size int) []byte {
+	xref := w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for n := 1; n < size; n++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[n])
+	}
+
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, xref)
+
+	return w.buf.Bytes()
+}
+
+// pdfString encodes s as the body of a PDF literal string in WinAnsiEncoding
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := winAnsiByte(r)
+		if !ok {
+			c = '?'
+		}
+
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func winAnsiByte(r rune) (byte, bool) {
+	switch {
+	case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+		return byte(r), true
+	default:
+		c, ok := winAnsi[r]
+		return c, ok
+	}
+}
+
+// encodable reports whether every character of s can be written in a PDF
+func encodable(s string) bool {
+	for _, r := range s {
+		if _, ok := winAnsiByte(r); !ok {
+			return false
+		}
+	}
+
+	return true
+}
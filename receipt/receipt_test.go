@@ -0,0 +1,155 @@
+package receipt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"qr-dinein-backend/model"
+	"testing"
+	"time"
+)
+
+// Run with -update to rewrite the golden files after an intended layout change
+var update = flag.Bool("update", false, "update golden files")
+
+func testRestaurant() *model.Restaurant {
+	return &model.Restaurant{
+		Name:     "Saravana Bhavan",
+		Address:  "12 Anna Salai, Chennai",
+		Phone:    "+91 44 2434 5678",
+		Currency: "INR",
+	}
+}
+
+func testOrder() *model.Order {
+	table := "7"
+	bar := 2
+
+	return &model.Order{
+		ID:                  1042,
+		RestaurantID:        1,
+		TableNumber:         &table,
+		CustomerName:        "Priya",
+		Status:              "served",
+		PaymentStatus:       model.PaymentPaid,
+		SpecialInstructions: "Less spicy, no onions in the uttapam please",
+		CreatedAt:           time.Date(2026, time.March, 14, 19, 45, 0, 0, time.UTC),
+		Total:               1234,
+		Items: []model.OrderItem{
+			{LineID: 1, Name: "Masala Dosa", Price: 140, Quantity: 2, Status: model.ItemServed,
+				Options: []model.OrderItemOption{{Name: "Extra ghee", PriceDelta: 20}}},
+			{LineID: 2, Name: "Onion Uttapam with Coconut and Tomato Chutney", Price: 160, Quantity: 1, Status: model.ItemServed},
+			{LineID: 3, Name: "Filter Coffee", Price: 60, Quantity: 3, Status: model.ItemServed},
+			{LineID: 4, Name: "Mango Lassi", Price: 90, Quantity: 1, Status: model.ItemVoided},
+		},
+		Tickets: []model.Ticket{
+			{LineIDs: []int{1, 2}},
+			{StationID: &bar, LineIDs: []int{3, 4}},
+		},
+		Bill: &model.Bill{
+			Currency: "INR",
+			Lines: []model.BillLine{
+				{Name: "Masala Dosa", Options: []string{"Extra ghee"}, Quantity: 2, UnitPrice: 140, Amount: 280},
+				{Name: "Onion Uttapam with Coconut and Tomato Chutney", Quantity: 1, UnitPrice: 160, Amount: 160},
+				{Name: "Filter Coffee", Quantity: 3, UnitPrice: 60, Amount: 180},
+			},
+			Subtotal:          620,
+			ServiceChargeRate: 10,
+			ServiceCharge:     62,
+			Taxes: []model.BillTax{
+				{Name: "CGST", Rate: 2.5, Amount: 17.05},
+				{Name: "SGST", Rate: 2.5, Amount: 17.05},
+			},
+			TaxTotal:   34.1,
+			Rounding:   -0.1,
+			GrandTotal: 716,
+		},
+	}
+}
+
+func TestReceiptGolden(t *testing.T) {
+	receipt := NewReceipt(testRestaurant(), testOrder())
+
+	html, err := HTML(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "receipt_58mm.escpos", ESCPOS(receipt, Paper58))
+	assertGolden(t, "receipt_80mm.escpos", ESCPOS(receipt, Paper80))
+	assertGolden(t, "receipt.html", html)
+	assertGolden(t, "receipt.pdf", PDF(receipt))
+}
+
+func TestReceiptWithoutBillGolden(t *testing.T) {
+	o := testOrder()
+	o.Bill = nil
+	o.TableNumber = nil
+	o.Total = 800
+
+	assertGolden(t, "receipt_unbilled_80mm.escpos", ESCPOS(NewReceipt(testRestaurant(), o), Paper80))
+}
+
+func TestCancelledReceiptGolden(t *testing.T) {
+	o := testOrder()
+	o.Status = "cancelled"
+	o.PaymentStatus = model.PaymentRefunded
+	o.Cancellation = &model.Cancellation{Reason: "out_of_stock", ActorType: "staff"}
+
+	assertGolden(t, "receipt_cancelled_80mm.escpos", ESCPOS(NewReceipt(testRestaurant(), o), Paper80))
+}
+
+func TestKitchenGolden(t *testing.T) {
+	stations := map[int]string{2: "Beverages"}
+	kitchen := NewKitchen(testRestaurant(), testOrder(), stations, nil)
+
+	html, err := HTML(kitchen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "kitchen_80mm.escpos", ESCPOS(kitchen, Paper80))
+	assertGolden(t, "kitchen.html", html)
+	assertGolden(t, "kitchen.pdf", PDF(kitchen))
+
+	bar := 2
+	assertGolden(t, "kitchen_station_58mm.escpos", ESCPOS(NewKitchen(testRestaurant(), testOrder(), stations, &bar), Paper58))
+}
+
+func TestKitchenLeavesOutVoidedItems(t *testing.T) {
+	bar := 2
+	d := NewKitchen(testRestaurant(), testOrder(), nil, &bar)
+
+	if len(d.Tickets) != 1 || d.Tickets[0].Station != "Kitchen" {
+		t.Fatalf("got tickets %+v, want one unnamed station ticket", d.Tickets)
+	}
+
+	if items := d.Tickets[0].Items; len(items) != 1 || items[0].Name != "Filter Coffee" {
+		t.Errorf("got items %+v, want only the filter coffee", items)
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test ./receipt -update to create it)", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file, run go test ./receipt -update and review the diff", name)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditUnlock = "unlock"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+type systemActorKey struct{}
+
+// asSystem marks ctx as background work so its mutations are audited as the system
+func asSystem(ctx *gofr.Context) {
+	ctx.Context = context.WithValue(ctx.Context, systemActorKey{}, true)
+}
+
+type Audit struct {
+	store *store.Audit
+}
+
+func NewAudit(s *store.Audit) *Audit {
+	return &Audit{store: s}
+}
+
+// Record writes an audit entry for a mutation performed by the caller in ctx.
+// before and after are snapshots of the resource (nil when not applicable).
+// Failures are logged and never fail the mutation being audited.
+func (svc *Audit) Record(ctx *gofr.Context, restaurantID int, resource string, resourceID interface{}, action string, before, after interface{}) {
+	entry := &model.AuditEntry{
+		Resource:   resource,
+		ResourceID: formatResourceID(resourceID),
+		Action:     action,
+		Before:     marshalSnapshot(before),
+		After:      marshalSnapshot(after),
+	}
+
+	if restaurantID > 0 {
+		entry.RestaurantID = &restaurantID
+	}
+
+	entry.ActorType, entry.ActorID, entry.ActorName = actor(ctx)
+
+	if err := svc.store.Create(ctx, entry); err != nil {
+		ctx.Logger.Errorf("failed to write audit entry for %s %s %s: %v", action, resource, entry.ResourceID, err)
+	}
+}
+
+// actor identifies who is making the request in ctx
+func actor(ctx *gofr.Context) (actorType string, id int, name string) {
+	claims := auth.GetClaimsFromContext(ctx)
+	switch {
+	case ctx.Value(systemActorKey{}) != nil:
+		return "system", 0, ""
+	case claims == nil:
+		return "customer", 0, ""
+	case claims.PlatformUserID != 0:
+		return "platform_user", claims.PlatformUserID, claims.Username
+	default:
+		return "staff", claims.StaffID, claims.Username
+	}
+}
+
+func (svc *Audit) Query(ctx *gofr.Context, f *model.AuditFilter) ([]model.AuditEntry, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultAuditLimit
+	}
+	if f.Limit > maxAuditLimit {
+		f.Limit = maxAuditLimit
+	}
+
+	return svc.store.Query(ctx, f)
+}
+
+func formatResourceID(id interface{}) string {
+	switch v := id.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
+
+func marshalSnapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+
+	return data
+}
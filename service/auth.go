@@ -1,26 +1,40 @@
 package service
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
 	"qr-dinein-backend/store"
+	"time"
 
 	"gofr.dev/pkg/gofr"
 )
 
+// Redis key prefixes
+const (
+	keyPrefixRefreshToken = "refresh_token:"
+)
+
 type Auth struct {
 	staffStore        *store.Staff
+	platformUserStore *store.PlatformUser
 	jwtManager        *auth.JWTManager
-	superuserUsername  string
+	revocations       *auth.Revocations
+	loginLimiter      *LoginLimiter
+	superuserUsername string
 	superuserPassword string
 }
 
-func NewAuth(staffStore *store.Staff, jwtManager *auth.JWTManager, superuserUsername, superuserPassword string) *Auth {
+func NewAuth(staffStore *store.Staff, platformUserStore *store.PlatformUser, jwtManager *auth.JWTManager, revocations *auth.Revocations, loginLimiter *LoginLimiter, superuserUsername, superuserPassword string) *Auth {
 	return &Auth{
 		staffStore:        staffStore,
+		platformUserStore: platformUserStore,
 		jwtManager:        jwtManager,
-		superuserUsername:  superuserUsername,
+		revocations:       revocations,
+		loginLimiter:      loginLimiter,
+		superuserUsername: superuserUsername,
 		superuserPassword: superuserPassword,
 	}
 }
@@ -33,28 +47,57 @@ func (s *Auth) Login(ctx *gofr.Context, req *model.LoginRequest) (*model.LoginRe
 		return nil, fmt.Errorf("pin is required")
 	}
 
+	ip := auth.GetClientIPFromContext(ctx)
+	if err := s.loginLimiter.Attempt(ctx, req.Username, ip); err != nil {
+		return nil, err
+	}
+
 	staff, err := s.staffStore.GetByUsername(ctx, req.Username)
 	if err != nil {
+		auth.BurnPinCheck(req.Pin)
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	if staff.Pin != req.Pin {
+	ok, needsRehash := auth.VerifyPin(staff.Pin, req.Pin)
+	if !ok {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	if err := s.loginLimiter.Succeeded(ctx, req.Username, ip); err != nil {
+		ctx.Logger.Errorf("failed to reset login failures for %s: %v", req.Username, err)
+	}
+
+	// Upgrade rows still holding a plaintext PIN
+	if needsRehash {
+		if pinHash, err := auth.HashPin(req.Pin); err == nil {
+			if err := s.staffStore.UpdatePinHash(ctx, staff.ID, pinHash); err != nil {
+				ctx.Logger.Errorf("failed to rehash pin for staff %d: %v", staff.ID, err)
+			}
+		}
+	}
+
+	staff.Pin = "" // Don't return pin
+
 	if !staff.Active {
 		return nil, fmt.Errorf("staff account is inactive")
 	}
 
-	token, expiresAt, err := s.jwtManager.GenerateToken(staff.ID, staff.RestaurantID, staff.Role, staff.Username)
+	tokens, err := s.issueTokens(ctx, &model.RefreshSession{
+		StaffID:      staff.ID,
+		RestaurantID: staff.RestaurantID,
+		Role:         staff.Role,
+		Username:     staff.Username,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	return &model.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		Staff:     staff,
+		Token:            tokens.Token,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		Staff:            staff,
 	}, nil
 }
 
@@ -66,23 +109,212 @@ func (s *Auth) SuperuserLogin(ctx *gofr.Context, req *model.SuperuserLoginReques
 		return nil, fmt.Errorf("password is required")
 	}
 
+	// Superuser attempts are counted separately from staff usernames
+	limiterKey := "platform:" + req.Username
+	ip := auth.GetClientIPFromContext(ctx)
+	if err := s.loginLimiter.Attempt(ctx, limiterKey, ip); err != nil {
+		return nil, err
+	}
+
+	user, err := s.platformUserStore.GetByUsername(ctx, req.Username)
+	if err != nil {
+		user, err = s.bootstrapPlatformUser(ctx, req)
+	}
+	if err != nil {
+		auth.BurnPinCheck(req.Password)
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	if user.TOTPEnabled {
+		if req.OTP == "" {
+			return nil, fmt.Errorf("two-factor code is required")
+		}
+
+		if !auth.ValidateTOTP(user.TOTPSecret, req.OTP, time.Now()) {
+			return nil, fmt.Errorf("invalid two-factor code")
+		}
+	}
+
+	if !user.Active {
+		return nil, fmt.Errorf("account is inactive")
+	}
+
+	if err := s.loginLimiter.Succeeded(ctx, limiterKey, ip); err != nil {
+		ctx.Logger.Errorf("failed to reset login failures for %s: %v", limiterKey, err)
+	}
+
+	tokens, err := s.issueTokens(ctx, &model.RefreshSession{
+		PlatformUserID: user.ID,
+		Role:           "superuser",
+		Username:       user.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SuperuserLoginResponse{
+		Token:            tokens.Token,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		Role:             "superuser",
+		Username:         user.Username,
+	}, nil
+}
+
+// bootstrapPlatformUser creates the first platform account from the
+// SUPERUSER_USERNAME/SUPERUSER_PASSWORD env pair. Once any account exists
+// the env credentials are no longer accepted.
+func (s *Auth) bootstrapPlatformUser(ctx *gofr.Context, req *model.SuperuserLoginRequest) (*model.PlatformUser, error) {
 	if s.superuserUsername == "" || s.superuserPassword == "" {
 		return nil, fmt.Errorf("superuser login is not configured")
 	}
 
-	if req.Username != s.superuserUsername || req.Password != s.superuserPassword {
+	count, err := s.platformUserStore.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("bootstrap credentials are disabled")
+	}
+
+	usernameOK := subtle.ConstantTimeCompare([]byte(req.Username), []byte(s.superuserUsername)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(req.Password), []byte(s.superuserPassword)) == 1
+	if !usernameOK || !passwordOK {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	token, expiresAt, err := s.jwtManager.GenerateToken(0, 0, "superuser", req.Username)
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		ctx.Logger.Errorf("cannot bootstrap platform user: %v", err)
+		return nil, err
+	}
+
+	ctx.Logger.Infof("creating initial platform user %s from bootstrap credentials", req.Username)
+
+	return s.platformUserStore.Create(ctx, &model.PlatformUser{
+		Username:     req.Username,
+		PasswordHash: hash,
+		Active:       true,
+	})
+}
+
+// Refresh exchanges a refresh token for a new access token. Refresh tokens
+// are single use: the presented token is consumed and a new one issued.
+func (s *Auth) Refresh(ctx *gofr.Context, req *model.RefreshRequest) (*model.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, fmt.Errorf("refresh token is required")
+	}
+
+	data, err := ctx.Redis.GetDel(ctx, keyPrefixRefreshToken+req.RefreshToken).Result()
+	if err != nil {
+		return nil, fmt.Errorf("refresh token expired or invalid")
+	}
+
+	var session model.RefreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("invalid refresh token data")
+	}
+
+	issuedAt := time.UnixMilli(session.IssuedAt)
+
+	revoked, err := s.revocations.IsStaffRevokedAt(ctx, session.StaffID, issuedAt)
+	if err == nil && !revoked {
+		revoked, err = s.revocations.IsPlatformUserRevokedAt(ctx, session.PlatformUserID, issuedAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify session: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	// Re-read the account so role changes and deactivation take effect
+	if session.PlatformUserID != 0 {
+		user, err := s.platformUserStore.GetByID(ctx, session.PlatformUserID)
+		if err != nil {
+			return nil, fmt.Errorf("account not found")
+		}
+
+		if !user.Active {
+			return nil, fmt.Errorf("account is inactive")
+		}
+
+		session.Username = user.Username
+	} else if session.StaffID != 0 {
+		staff, err := s.staffStore.GetByID(ctx, session.RestaurantID, session.StaffID)
+		if err != nil {
+			return nil, fmt.Errorf("staff account not found")
+		}
+
+		if !staff.Active {
+			return nil, fmt.Errorf("staff account is inactive")
+		}
+
+		session.Role = staff.Role
+		session.Username = staff.Username
+	}
+
+	return s.issueTokens(ctx, &session)
+}
+
+// Logout revokes the caller's access token and discards their refresh token
+func (s *Auth) Logout(ctx *gofr.Context, claims *auth.Claims, req *model.LogoutRequest) error {
+	if claims != nil && claims.ExpiresAt != nil {
+		if err := s.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+	}
+
+	if req.RefreshToken != "" {
+		if err := ctx.Redis.Del(ctx, keyPrefixRefreshToken+req.RefreshToken).Err(); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// issueTokens generates an access token and stores a fresh refresh token for the session
+func (s *Auth) issueTokens(ctx *gofr.Context, session *model.RefreshSession) (*model.RefreshResponse, error) {
+	var token string
+	var expiresAt int64
+	var err error
+	if session.PlatformUserID != 0 {
+		token, expiresAt, err = s.jwtManager.GeneratePlatformToken(session.PlatformUserID, session.Username)
+	} else {
+		token, expiresAt, err = s.jwtManager.GenerateToken(session.StaffID, session.RestaurantID, session.Role, session.Username)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	return &model.SuperuserLoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		Role:      "superuser",
-		Username:  req.Username,
+	refreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session.IssuedAt = now.UnixMilli()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal refresh session: %w", err)
+	}
+
+	ttl := s.jwtManager.RefreshExpiry()
+	if err := ctx.Redis.Set(ctx, keyPrefixRefreshToken+refreshToken, string(data), ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &model.RefreshResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(ttl).Unix(),
 	}, nil
 }
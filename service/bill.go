@@ -0,0 +1,141 @@
+package service
+
+import (
+	"math"
+	"qr-dinein-backend/model"
+	"sort"
+	"strconv"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Setting keys controlling bill composition
+const (
+	settingServiceChargeRate = "service_charge_rate" // percent of subtotal
+	settingBillRounding      = "bill_rounding"       // "none" or "nearest"
+)
+
+// buildBill loads the restaurant's tax and service charge configuration and
+// computes the bill for the given (already priced) items.
+func (svc *Order) buildBill(ctx *gofr.Context, restaurantID int, items []model.OrderItem) (*model.Bill, error) {
+	restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := svc.taxStore.GetAll(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to the single restaurant-level rate when no components are configured
+	if len(components) == 0 && restaurant.TaxRate > 0 {
+		components = []model.TaxComponent{{Name: "Tax", Rate: restaurant.TaxRate}}
+	}
+
+	serviceChargeRate := 0.0
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingServiceChargeRate); err == nil {
+		if rate, err := strconv.ParseFloat(setting.Value, 64); err == nil && rate > 0 {
+			serviceChargeRate = rate
+		}
+	}
+
+	roundToUnit := false
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingBillRounding); err == nil && setting.Value == "nearest" {
+		roundToUnit = true
+	}
+
+	bill := computeBill(items, components, serviceChargeRate, roundToUnit)
+	bill.Currency = restaurant.Currency
+
+	return bill, nil
+}
+
+// computeBill builds the breakdown for items. Taxes are levied on the line
+// amounts plus the service charge; each line carries its share of the tax
+// total, service charge tax included, so the lines add up to it.
+func computeBill(items []model.OrderItem, components []model.TaxComponent, serviceChargeRate float64, roundToUnit bool) *model.Bill {
+	bill := &model.Bill{
+		Lines:             make([]model.BillLine, 0, len(items)),
+		Taxes:             make([]model.BillTax, 0, len(components)),
+		ServiceChargeRate: serviceChargeRate,
+	}
+
+	for _, item := range items {
+		amount := roundMoney(item.Price * float64(item.Quantity))
+
+		var options []string
+		for _, opt := range item.Options {
+			options = append(options, opt.Name)
+		}
+
+		bill.Lines = append(bill.Lines, model.BillLine{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Options:   options,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Amount:    amount,
+		})
+		bill.Subtotal += amount
+	}
+
+	bill.Subtotal = roundMoney(bill.Subtotal)
+	bill.ServiceCharge = roundMoney(bill.Subtotal * serviceChargeRate / 100)
+
+	taxable := bill.Subtotal + bill.ServiceCharge
+	for _, c := range components {
+		amount := roundMoney(taxable * c.Rate / 100)
+		bill.Taxes = append(bill.Taxes, model.BillTax{Name: c.Name, Rate: c.Rate, Amount: amount})
+		bill.TaxTotal += amount
+	}
+
+	bill.TaxTotal = roundMoney(bill.TaxTotal)
+	splitTax(bill.Lines, bill.Subtotal, bill.TaxTotal)
+
+	total := roundMoney(taxable + bill.TaxTotal)
+	if roundToUnit {
+		bill.Rounding = roundMoney(math.Round(total) - total)
+	}
+
+	bill.GrandTotal = roundMoney(total + bill.Rounding)
+
+	return bill
+}
+
+// splitTax shares the tax total across lines in proportion to their amounts.
+// Shares are whole cents rounded down, and the cents left over go to the
+// lines that lost the most to rounding.
+func splitTax(lines []model.BillLine, subtotal, taxTotal float64) {
+	if subtotal <= 0 {
+		return
+	}
+
+	total := int64(math.Round(taxTotal * 100))
+	left := total
+	shares := make([]int64, len(lines))
+	lost := make([]float64, len(lines))
+	order := make([]int, len(lines))
+
+	for i := range lines {
+		exact := float64(total) * lines[i].Amount / subtotal
+		shares[i] = int64(math.Floor(exact))
+		lost[i] = exact - float64(shares[i])
+		order[i] = i
+		left -= shares[i]
+	}
+
+	sort.SliceStable(order, func(a, b int) bool { return lost[order[a]] > lost[order[b]] })
+	for i := 0; left > 0 && len(order) > 0; i++ {
+		shares[order[i%len(order)]]++
+		left--
+	}
+
+	for i := range lines {
+		lines[i].Tax = float64(shares[i]) / 100
+	}
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"math"
+	"qr-dinein-backend/model"
+	"testing"
+)
+
+func TestComputeBill(t *testing.T) {
+	dosa := model.OrderItem{ProductID: 1, Name: "Masala Dosa", Price: 120, Quantity: 2}
+	coffee := model.OrderItem{ProductID: 2, Name: "Filter Coffee", Price: 85, Quantity: 1}
+	gst := []model.TaxComponent{{Name: "CGST", Rate: 2.5}, {Name: "SGST", Rate: 2.5}}
+
+	tests := []struct {
+		name              string
+		items             []model.OrderItem
+		components        []model.TaxComponent
+		serviceChargeRate float64
+		roundToUnit       bool
+
+		serviceCharge, taxTotal, rounding, grandTotal float64
+		lineTaxes                                     []float64
+	}{
+		{"single rate", []model.OrderItem{dosa, coffee}, []model.TaxComponent{{Name: "Tax", Rate: 5}}, 0, false,
+			0, 16.25, 0, 341.25, []float64{12, 4.25}},
+		{"several rates with a service charge", []model.OrderItem{dosa, coffee}, gst, 10, false,
+			32.5, 17.88, 0, 375.38, []float64{13.2, 4.68}},
+		{"rounded down to the unit", []model.OrderItem{dosa, coffee}, gst, 10, true,
+			32.5, 17.88, -0.38, 375, []float64{13.2, 4.68}},
+		{"rounded up to the unit", []model.OrderItem{{Name: "Thali", Price: 110, Quantity: 1}}, []model.TaxComponent{{Name: "GST", Rate: 18}}, 0, true,
+			0, 19.8, 0.2, 130, []float64{19.8}},
+		{"untaxed", []model.OrderItem{dosa, coffee}, nil, 0, false,
+			0, 0, 0, 325, []float64{0, 0}},
+		{"cents left over by equal lines", []model.OrderItem{coffee, coffee, coffee}, []model.TaxComponent{{Name: "GST", Rate: 5}}, 0, false,
+			0, 12.75, 0, 267.75, []float64{4.25, 4.25, 4.25}},
+		{"cents left over by the service charge", []model.OrderItem{{Name: "Idli", Price: 10, Quantity: 1}, {Name: "Vada", Price: 10, Quantity: 1}, {Name: "Chutney", Price: 10, Quantity: 1}}, gst, 10, false,
+			3, 1.66, 0, 34.66, []float64{0.56, 0.55, 0.55}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill := computeBill(tt.items, tt.components, tt.serviceChargeRate, tt.roundToUnit)
+
+			if bill.ServiceCharge != tt.serviceCharge || bill.TaxTotal != tt.taxTotal || bill.Rounding != tt.rounding || bill.GrandTotal != tt.grandTotal {
+				t.Errorf("got service charge %.2f, tax %.2f, rounding %.2f, total %.2f, want %.2f, %.2f, %.2f, %.2f",
+					bill.ServiceCharge, bill.TaxTotal, bill.Rounding, bill.GrandTotal,
+					tt.serviceCharge, tt.taxTotal, tt.rounding, tt.grandTotal)
+			}
+
+			if len(bill.Lines) != len(tt.lineTaxes) {
+				t.Fatalf("got %d lines, want %d", len(bill.Lines), len(tt.lineTaxes))
+			}
+
+			var lineTaxes float64
+			for i, line := range bill.Lines {
+				if line.Tax != tt.lineTaxes[i] {
+					t.Errorf("line %d: got tax %.2f, want %.2f", i, line.Tax, tt.lineTaxes[i])
+				}
+				lineTaxes += line.Tax
+			}
+
+			if math.Round(lineTaxes*100) != math.Round(bill.TaxTotal*100) {
+				t.Errorf("line taxes add up to %.2f, want the tax total %.2f", lineTaxes, bill.TaxTotal)
+			}
+		})
+	}
+}
@@ -13,10 +13,11 @@ import (
 
 type Category struct {
 	store *store.Category
+	audit *Audit
 }
 
-func NewCategory(s *store.Category) *Category {
-	return &Category{store: s}
+func NewCategory(s *store.Category, audit *Audit) *Category {
+	return &Category{store: s, audit: audit}
 }
 
 func (svc *Category) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Category, error) {
@@ -59,27 +60,40 @@ func (svc *Category) Create(ctx *gofr.Context, restaurantID int, c *model.Catego
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "category", result.ID, AuditCreate, nil, result)
 
 	return result, nil
 }
 
 func (svc *Category) Update(ctx *gofr.Context, restaurantID, id int, c *model.Category) (*model.Category, error) {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+
 	result, err := svc.store.Update(ctx, restaurantID, id, c)
 	if err != nil {
 		return nil, err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "category", id, AuditUpdate, before, result)
 
 	return result, nil
 }
 
 func (svc *Category) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
+	}
+
 	if err := svc.store.Delete(ctx, restaurantID, id); err != nil {
 		return err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "category", id, AuditDelete, before, nil)
 
 	return nil
 }
@@ -2,43 +2,59 @@ package service
 
 import (
 	"crypto/rand"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net/mail"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"gofr.dev/pkg/gofr"
 
+	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
+	"qr-dinein-backend/notify"
+	"qr-dinein-backend/store"
 )
 
 const (
-	otpLength            = 6
-	otpExpiryMinutes     = 5
-	sessionExpiryMinutes = 30
-	maxOTPAttempts       = 5
-	resendCooldownSecs   = 60
-	maxOTPPerHour        = 5
+	otpLength          = 6
+	otpExpiryMinutes   = 5
+	maxOTPAttempts     = 5
+	resendCooldownSecs = 60
+	maxOTPPerHour      = 5
+
+	// customerTokenLifetime keeps returning diners signed in between visits
+	customerTokenLifetime = 90 * 24 * time.Hour
 )
 
-// Redis key prefixes
+// Limits on what a customer can save in their profile
 const (
-	keyPrefixOTP       = "otp:"
-	keyPrefixCooldown  = "otp_cooldown:"
-	keyPrefixRateLimit = "otp_rate:"
-	keyPrefixSession   = "customer_session:"
+	maxCustomerNameLength = 100
+	maxProfileEntries     = 20
+	maxProfileValueLength = 100
+	maxCustomerHistory    = 100 // orders returned in a customer's history
 )
 
-// Customer handles customer OTP operations
+// Customer handles customer OTP verification and accounts. Verifying a
+// phone number signs the customer in with a long-lived token.
 type Customer struct {
-	smsService *SMSService
+	store         *store.Customer
+	state         store.CustomerStateStore
+	notifications *Notification
+	streamTickets *auth.StreamTickets
 }
 
-// NewCustomer creates a new customer service
-func NewCustomer(smsService *SMSService) *Customer {
+// NewCustomer creates a new customer service keeping OTPs, their limits and
+// sessions in state
+func NewCustomer(s *store.Customer, state store.CustomerStateStore, notifications *Notification, streamTickets *auth.StreamTickets) *Customer {
 	return &Customer{
-		smsService: smsService,
+		store:         s,
+		state:         state,
+		notifications: notifications,
+		streamTickets: streamTickets,
 	}
 }
 
@@ -52,63 +68,66 @@ func (svc *Customer) SendOTP(ctx *gofr.Context, req *model.SendOTPRequest) (*mod
 		return nil, fmt.Errorf("restaurant ID is required")
 	}
 
-	// Check rate limit (max 5 OTP requests per phone per hour)
-	rateLimitKey := keyPrefixRateLimit + req.PhoneNumber
-	rateCount, _ := ctx.Redis.Get(ctx, rateLimitKey).Int()
-	if rateCount >= maxOTPPerHour {
-		return nil, fmt.Errorf("too many OTP requests. Please try again later")
+	// Start the resend cooldown (60 seconds between requests)
+	wait, err := svc.state.StartOTPCooldown(ctx, req.PhoneNumber, resendCooldownSecs*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check OTP cooldown: %w", err)
 	}
 
-	// Check resend cooldown (60 seconds between requests)
-	cooldownKey := keyPrefixCooldown + req.PhoneNumber
-	exists, _ := ctx.Redis.Exists(ctx, cooldownKey).Result()
-	if exists > 0 {
-		ttl, _ := ctx.Redis.TTL(ctx, cooldownKey).Result()
-		return nil, fmt.Errorf("please wait %d seconds before requesting another OTP", int(ttl.Seconds()))
+	if wait > 0 {
+		return nil, fmt.Errorf("please wait %d seconds before requesting another OTP", int(math.Ceil(wait.Seconds())))
 	}
 
-	// Generate 6-digit OTP
-	otp, err := generateOTP(otpLength)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate OTP: %w", err)
+	if err := svc.issueOTP(ctx, req); err != nil {
+		// Let the customer ask again straight away
+		if clearErr := svc.state.ClearOTPCooldown(ctx, req.PhoneNumber); clearErr != nil {
+			ctx.Logger.Errorf("failed to clear OTP cooldown: %v", clearErr)
+		}
+
+		return nil, err
 	}
 
-	ctx.Logger.Infof("OTP generated: %s", otp)
+	return &model.OTPResponse{
+		Message:   "OTP sent successfully",
+		ExpiresIn: otpExpiryMinutes * 60,
+	}, nil
+}
 
-	// Store OTP data in Redis
-	otpKey := keyPrefixOTP + req.PhoneNumber
-	otpData := model.OTPData{
-		OTP:      otp,
-		Attempts: 0,
+// issueOTP counts the request against the hourly limit, then generates an
+// OTP, stores it and queues it for the customer
+func (svc *Customer) issueOTP(ctx *gofr.Context, req *model.SendOTPRequest) error {
+	// Max 5 OTP requests per phone per hour
+	count, err := svc.state.CountOTPRequest(ctx, req.PhoneNumber, time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to check OTP rate limit: %w", err)
 	}
-	otpDataJSON, _ := json.Marshal(otpData)
-	ctx.Redis.Set(ctx, otpKey, string(otpDataJSON), time.Duration(otpExpiryMinutes)*time.Minute)
 
-	// Set resend cooldown
-	ctx.Redis.Set(ctx, cooldownKey, "1", time.Duration(resendCooldownSecs)*time.Second)
+	if count > maxOTPPerHour {
+		return fmt.Errorf("too many OTP requests. Please try again later")
+	}
 
-	// Increment rate limit counter
-	if rateCount == 0 {
-		ctx.Redis.Set(ctx, rateLimitKey, "1", time.Hour)
-	} else {
-		ctx.Redis.Incr(ctx, rateLimitKey)
+	// Generate 6-digit OTP
+	otp, err := generateOTP(otpLength)
+	if err != nil {
+		return fmt.Errorf("failed to generate OTP: %w", err)
 	}
 
-	// Send OTP asynchronously
-	go func() {
-		if err := svc.smsService.SendOTP(req.PhoneNumber, otp); err != nil {
-			// Log error but don't fail the request
-			fmt.Printf("Failed to send OTP to %s: %v\n", req.PhoneNumber, err)
-		}
-	}()
+	if err := svc.state.SaveOTP(ctx, req.PhoneNumber, otp, otpExpiryMinutes*time.Minute); err != nil {
+		return fmt.Errorf("failed to store OTP: %w", err)
+	}
 
-	return &model.OTPResponse{
-		Message:   "OTP sent successfully",
-		ExpiresIn: otpExpiryMinutes * 60,
-	}, nil
+	// Queue the OTP in the outbox so it survives a restart or a provider
+	// outage; the delivery worker sends it within seconds
+	if err := svc.notifications.Queue(ctx, req.RestaurantID, nil, Recipient{Phone: req.PhoneNumber}, notify.TemplateOTP,
+		notify.Data{OTP: otp, Minutes: otpExpiryMinutes}); err != nil {
+		return fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	return nil
 }
 
-// VerifyOTP verifies the OTP and creates a session
+// VerifyOTP verifies the OTP and signs the customer in, creating their
+// account on their first visit
 func (svc *Customer) VerifyOTP(ctx *gofr.Context, req *model.VerifyOTPRequest) (*model.VerifyOTPResponse, error) {
 	if req.PhoneNumber == "" {
 		return nil, fmt.Errorf("phone number is required")
@@ -122,93 +141,187 @@ func (svc *Customer) VerifyOTP(ctx *gofr.Context, req *model.VerifyOTPRequest) (
 		return nil, fmt.Errorf("restaurant ID is required")
 	}
 
-	// Get OTP data from Redis
-	otpKey := keyPrefixOTP + req.PhoneNumber
-	otpDataJSON, err := ctx.Redis.Get(ctx, otpKey).Result()
-	if err != nil {
+	// Count the attempt before comparing, so concurrent guesses cannot
+	// get past the limit
+	otpData, err := svc.state.AddOTPAttempt(ctx, req.PhoneNumber)
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("OTP expired or not found. Please request a new OTP")
 	}
-
-	var otpData model.OTPData
-	if err := json.Unmarshal([]byte(otpDataJSON), &otpData); err != nil {
-		return nil, fmt.Errorf("invalid OTP data")
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify OTP: %w", err)
 	}
 
-	// Check attempts
-	if otpData.Attempts >= maxOTPAttempts {
-		ctx.Redis.Del(ctx, otpKey)
+	if otpData.Attempts > maxOTPAttempts {
+		if _, err := svc.state.DeleteOTP(ctx, req.PhoneNumber); err != nil {
+			return nil, fmt.Errorf("failed to verify OTP: %w", err)
+		}
+
 		return nil, fmt.Errorf("too many failed attempts. Please request a new OTP")
 	}
 
-	// Verify OTP
 	if otpData.OTP != req.OTP {
-		// Increment attempts
-		otpData.Attempts++
-		updatedJSON, _ := json.Marshal(otpData)
-
-		// Get remaining TTL and update with same expiry
-		ttl, _ := ctx.Redis.TTL(ctx, otpKey).Result()
-		ctx.Redis.Set(ctx, otpKey, string(updatedJSON), ttl)
-
 		remainingAttempts := maxOTPAttempts - otpData.Attempts
 		return nil, fmt.Errorf("invalid OTP. %d attempts remaining", remainingAttempts)
 	}
 
-	// OTP verified - delete it
-	ctx.Redis.Del(ctx, otpKey)
+	// OTP verified - delete it. Only the request that deletes it signs in.
+	deleted, err := svc.state.DeleteOTP(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify OTP: %w", err)
+	}
 
-	// Generate session token
-	sessionToken := uuid.New().String()
-	expiresAt := time.Now().Add(time.Duration(sessionExpiryMinutes) * time.Minute).Unix()
+	if !deleted {
+		return nil, fmt.Errorf("OTP expired or not found. Please request a new OTP")
+	}
 
-	// Store session in Redis
-	sessionKey := keyPrefixSession + sessionToken
-	session := model.CustomerSession{
-		PhoneNumber:  req.PhoneNumber,
-		RestaurantID: req.RestaurantID,
-		Verified:     true,
+	customer, err := svc.store.Upsert(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	token, err := auth.NewCustomerToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(customerTokenLifetime)
+	session := &model.CustomerSession{
+		CustomerID:  customer.ID,
+		PhoneNumber: customer.PhoneNumber,
+		Verified:    true,
+		ExpiresAt:   expiresAt,
+	}
+	if err := svc.state.CreateSession(ctx, auth.HashCustomerToken(token), session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
-	sessionJSON, _ := json.Marshal(session)
-	ctx.Redis.Set(ctx, sessionKey, string(sessionJSON), time.Duration(sessionExpiryMinutes)*time.Minute)
 
 	return &model.VerifyOTPResponse{
-		SessionToken: sessionToken,
-		ExpiresAt:    expiresAt,
+		SessionToken: token,
+		ExpiresAt:    expiresAt.Unix(),
 		PhoneNumber:  req.PhoneNumber,
+		Customer:     customer,
 	}, nil
 }
 
-// GetSession retrieves a customer session by token
+// GetSession returns the customer a token was issued to
 func (svc *Customer) GetSession(ctx *gofr.Context, sessionToken string) (*model.CustomerSession, error) {
 	if sessionToken == "" {
 		return nil, fmt.Errorf("session token is required")
 	}
 
-	sessionKey := keyPrefixSession + sessionToken
-	sessionJSON, err := ctx.Redis.Get(ctx, sessionKey).Result()
-	if err != nil {
+	session, err := svc.state.GetSession(ctx, auth.HashCustomerToken(sessionToken))
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("session expired or invalid")
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
 
-	var session model.CustomerSession
-	if err := json.Unmarshal([]byte(sessionJSON), &session); err != nil {
-		return nil, fmt.Errorf("invalid session data")
+	return session, nil
+}
+
+// StreamTicket issues a ticket that signs the customer's screen in to an
+// order's event stream once
+func (svc *Customer) StreamTicket(ctx *gofr.Context, sessionToken string) (*model.StreamTicketResponse, error) {
+	if _, err := svc.GetSession(ctx, sessionToken); err != nil {
+		return nil, err
 	}
 
-	return &session, nil
+	ticket, err := svc.streamTickets.Issue(ctx, sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue stream ticket: %w", err)
+	}
+
+	return &model.StreamTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int(auth.StreamTicketTTL.Seconds()),
+	}, nil
 }
 
-// InvalidateSession removes a customer session (call after order is placed)
+// InvalidateSession signs the customer out by revoking their token
 func (svc *Customer) InvalidateSession(ctx *gofr.Context, sessionToken string) error {
 	if sessionToken == "" {
 		return fmt.Errorf("session token is required")
 	}
 
-	sessionKey := keyPrefixSession + sessionToken
-	ctx.Redis.Del(ctx, sessionKey)
+	if err := svc.state.DeleteSession(ctx, auth.HashCustomerToken(sessionToken)); err != nil {
+		return fmt.Errorf("failed to sign out: %w", err)
+	}
+
 	return nil
 }
 
+// GetProfile returns the signed-in customer's account
+func (svc *Customer) GetProfile(ctx *gofr.Context, sessionToken string) (*model.Customer, error) {
+	session, err := svc.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.store.GetByID(ctx, session.CustomerID)
+}
+
+func (svc *Customer) GetByID(ctx *gofr.Context, id int) (*model.Customer, error) {
+	return svc.store.GetByID(ctx, id)
+}
+
+func (svc *Customer) GetByPhone(ctx *gofr.Context, phone string) (*model.Customer, error) {
+	return svc.store.GetByPhone(ctx, phone)
+}
+
+// UpdateProfile saves the signed-in customer's name, email, preferences,
+// allergens and notification opt-out for their next visits
+func (svc *Customer) UpdateProfile(ctx *gofr.Context, sessionToken string, c *model.Customer) (*model.Customer, error) {
+	session, err := svc.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	c.ID = session.CustomerID
+	c.Name = strings.TrimSpace(c.Name)
+	if len(c.Name) > maxCustomerNameLength {
+		return nil, fmt.Errorf("name must be at most %d characters", maxCustomerNameLength)
+	}
+
+	c.Email = strings.TrimSpace(c.Email)
+	if c.Email != "" {
+		addr, err := mail.ParseAddress(c.Email)
+		if err != nil || addr.Address != c.Email {
+			return nil, fmt.Errorf("invalid email address")
+		}
+	}
+
+	if len(c.Preferences) > maxProfileEntries || len(c.Allergens) > maxProfileEntries {
+		return nil, fmt.Errorf("at most %d preferences and %d allergens can be saved", maxProfileEntries, maxProfileEntries)
+	}
+
+	preferences := make(map[string]string, len(c.Preferences))
+	for k, v := range c.Preferences {
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if k == "" || len(k) > maxProfileValueLength || len(v) > maxProfileValueLength {
+			return nil, fmt.Errorf("invalid preference '%s'", k)
+		}
+		preferences[k] = v
+	}
+	c.Preferences = preferences
+
+	allergens := []string{}
+	for _, a := range c.Allergens {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || len(a) > maxProfileValueLength {
+			return nil, fmt.Errorf("invalid allergen '%s'", a)
+		}
+		allergens = append(allergens, a)
+	}
+	c.Allergens = allergens
+
+	if err := svc.store.UpdateProfile(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return svc.store.GetByID(ctx, c.ID)
+}
+
 // generateOTP generates a cryptographically secure random OTP
 func generateOTP(length int) (string, error) {
 	const digits = "0123456789"
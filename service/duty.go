@@ -0,0 +1,347 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"sort"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	shiftTimeLayout   = "15:04"
+	timesheetDateFmt  = "2006-01-02"
+	maxTimesheetRange = 93 * 24 * time.Hour
+)
+
+// Duty tracks when staff are working: their weekly shift schedule and the
+// periods they are clocked in, including breaks.
+type Duty struct {
+	staffStore     *store.Staff
+	shiftStore     *store.Shift
+	timeEntryStore *store.TimeEntry
+	audit          *Audit
+}
+
+func NewDuty(staffStore *store.Staff, shiftStore *store.Shift, timeEntryStore *store.TimeEntry, audit *Audit) *Duty {
+	return &Duty{staffStore: staffStore, shiftStore: shiftStore, timeEntryStore: timeEntryStore, audit: audit}
+}
+
+func (svc *Duty) GetShifts(ctx *gofr.Context, restaurantID, staffID int) ([]model.Shift, error) {
+	if _, err := svc.staffStore.GetByID(ctx, restaurantID, staffID); err != nil {
+		return nil, fmt.Errorf("staff member not found: %w", err)
+	}
+
+	return svc.shiftStore.GetByStaff(ctx, restaurantID, staffID)
+}
+
+// SetShifts replaces a staff member's weekly shift schedule
+func (svc *Duty) SetShifts(ctx *gofr.Context, restaurantID, staffID int, shifts []model.Shift) ([]model.Shift, error) {
+	if _, err := svc.staffStore.GetByID(ctx, restaurantID, staffID); err != nil {
+		return nil, fmt.Errorf("staff member not found: %w", err)
+	}
+
+	for _, sh := range shifts {
+		if sh.Weekday < 0 || sh.Weekday > 6 {
+			return nil, fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+
+		start, err := time.Parse(shiftTimeLayout, sh.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shift start time '%s', expected HH:MM", sh.StartTime)
+		}
+
+		end, err := time.Parse(shiftTimeLayout, sh.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shift end time '%s', expected HH:MM", sh.EndTime)
+		}
+
+		if start.Equal(end) {
+			return nil, fmt.Errorf("shift start and end time cannot be the same")
+		}
+	}
+
+	before, err := svc.shiftStore.GetByStaff(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := svc.shiftStore.ReplaceForStaff(ctx, restaurantID, staffID, shifts)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "shifts", staffID, AuditUpdate, before, result)
+
+	return result, nil
+}
+
+// Status reports whether the staff member is off duty, on duty or on a break
+func (svc *Duty) Status(ctx *gofr.Context, restaurantID, staffID int) (*model.DutyStatus, error) {
+	entry, err := svc.openEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.DutyStatus{StaffID: staffID, Status: model.DutyOff, Entry: entry}
+	switch {
+	case entry == nil:
+	case entry.BreakStartedAt != nil:
+		status.Status = model.DutyOnBreak
+	default:
+		status.Status = model.DutyOn
+	}
+
+	return status, nil
+}
+
+func (svc *Duty) ClockIn(ctx *gofr.Context, restaurantID, staffID int) (*model.DutyStatus, error) {
+	st, err := svc.staffStore.GetByID(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("staff member not found: %w", err)
+	}
+
+	if !st.Active {
+		return nil, fmt.Errorf("staff member is inactive")
+	}
+
+	open, err := svc.openEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if open != nil {
+		return nil, fmt.Errorf("already clocked in")
+	}
+
+	entry, err := svc.timeEntryStore.ClockIn(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "time_entry", entry.ID, AuditCreate, nil, entry)
+
+	return svc.Status(ctx, restaurantID, staffID)
+}
+
+// ClockOut ends the current clocked-in period, closing any break in progress
+func (svc *Duty) ClockOut(ctx *gofr.Context, restaurantID, staffID int) (*model.DutyStatus, error) {
+	entry, err := svc.requireOpenEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := svc.timeEntryStore.ClockOut(ctx, entry.ID, now, breakMinutes(entry, now)); err != nil {
+		return nil, err
+	}
+
+	after := *entry
+	after.ClockOutAt = &now
+	after.BreakMinutes = breakMinutes(entry, now)
+	after.BreakStartedAt = nil
+
+	svc.audit.Record(ctx, restaurantID, "time_entry", entry.ID, AuditUpdate, entry, &after)
+
+	return &model.DutyStatus{StaffID: staffID, Status: model.DutyOff}, nil
+}
+
+func (svc *Duty) StartBreak(ctx *gofr.Context, restaurantID, staffID int) (*model.DutyStatus, error) {
+	entry, err := svc.requireOpenEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.BreakStartedAt != nil {
+		return nil, fmt.Errorf("already on a break")
+	}
+
+	if err := svc.timeEntryStore.StartBreak(ctx, entry.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return svc.recordChange(ctx, restaurantID, staffID, entry)
+}
+
+func (svc *Duty) EndBreak(ctx *gofr.Context, restaurantID, staffID int) (*model.DutyStatus, error) {
+	entry, err := svc.requireOpenEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.BreakStartedAt == nil {
+		return nil, fmt.Errorf("not on a break")
+	}
+
+	if err := svc.timeEntryStore.EndBreak(ctx, entry.ID, breakMinutes(entry, time.Now())); err != nil {
+		return nil, err
+	}
+
+	return svc.recordChange(ctx, restaurantID, staffID, entry)
+}
+
+// Timesheet totals the time each staff member worked per day between from
+// and to (inclusive dates). Periods still open are counted up to now.
+func (svc *Duty) Timesheet(ctx *gofr.Context, restaurantID int, from, to time.Time) ([]model.TimesheetDay, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("from date must not be after to date")
+	}
+
+	end := to.AddDate(0, 0, 1)
+	if end.Sub(from) > maxTimesheetRange {
+		return nil, fmt.Errorf("timesheet range cannot exceed 93 days")
+	}
+
+	entries, err := svc.timeEntryStore.GetByRange(ctx, restaurantID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	staff, err := svc.staffStore.GetAll(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[int]string, len(staff))
+	for _, st := range staff {
+		usernames[st.ID] = st.Username
+	}
+
+	shifts, err := svc.shiftStore.GetByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	days := make(map[string]*model.TimesheetDay)
+	var list []*model.TimesheetDay
+
+	for i := range entries {
+		e := &entries[i]
+
+		stop := now
+		if e.ClockOutAt != nil {
+			stop = *e.ClockOutAt
+		}
+
+		date := e.ClockInAt.Format(timesheetDateFmt)
+		key := fmt.Sprintf("%d|%s", e.StaffID, date)
+
+		day, ok := days[key]
+		if !ok {
+			day = &model.TimesheetDay{
+				StaffID:          e.StaffID,
+				Username:         usernames[e.StaffID],
+				Date:             date,
+				ScheduledMinutes: scheduledMinutes(shifts, e.StaffID, e.ClockInAt.Weekday()),
+			}
+			days[key] = day
+			list = append(list, day)
+		}
+
+		breaks := breakMinutes(e, stop)
+		worked := int(stop.Sub(e.ClockInAt).Minutes()) - breaks
+		if worked < 0 {
+			worked = 0
+		}
+
+		day.WorkedMinutes += worked
+		day.BreakMinutes += breaks
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date < list[j].Date
+		}
+		return list[i].Username < list[j].Username
+	})
+
+	result := make([]model.TimesheetDay, 0, len(list))
+	for _, day := range list {
+		day.Hours = math.Round(float64(day.WorkedMinutes)/60*100) / 100
+		result = append(result, *day)
+	}
+
+	return result, nil
+}
+
+func (svc *Duty) recordChange(ctx *gofr.Context, restaurantID, staffID int, before *model.TimeEntry) (*model.DutyStatus, error) {
+	status, err := svc.Status(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "time_entry", before.ID, AuditUpdate, before, status.Entry)
+
+	return status, nil
+}
+
+// openEntry returns the staff member's current clocked-in period, or nil when off duty
+func (svc *Duty) openEntry(ctx *gofr.Context, restaurantID, staffID int) (*model.TimeEntry, error) {
+	entry, err := svc.timeEntryStore.GetOpen(ctx, restaurantID, staffID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (svc *Duty) requireOpenEntry(ctx *gofr.Context, restaurantID, staffID int) (*model.TimeEntry, error) {
+	entry, err := svc.openEntry(ctx, restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, fmt.Errorf("not clocked in")
+	}
+
+	return entry, nil
+}
+
+// breakMinutes is the entry's completed break time plus any break still running at `at`
+func breakMinutes(e *model.TimeEntry, at time.Time) int {
+	minutes := e.BreakMinutes
+	if e.BreakStartedAt != nil && at.After(*e.BreakStartedAt) {
+		minutes += int(at.Sub(*e.BreakStartedAt).Minutes())
+	}
+
+	return minutes
+}
+
+// scheduledMinutes sums the staff member's shifts starting on the given weekday
+func scheduledMinutes(shifts []model.Shift, staffID int, weekday time.Weekday) int {
+	total := 0
+	for _, sh := range shifts {
+		if sh.StaffID != staffID || sh.Weekday != int(weekday) {
+			continue
+		}
+
+		start, err := time.Parse(shiftTimeLayout, sh.StartTime)
+		if err != nil {
+			continue
+		}
+
+		end, err := time.Parse(shiftTimeLayout, sh.EndTime)
+		if err != nil {
+			continue
+		}
+
+		// Overnight shifts end the next day
+		if !end.After(start) {
+			end = end.Add(24 * time.Hour)
+		}
+
+		total += int(end.Sub(start).Minutes())
+	}
+
+	return total
+}
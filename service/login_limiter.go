@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	maxLoginFailuresPerUser = 5
+	maxLoginFailuresPerIP   = 20
+	loginLockoutWindow      = 15 * time.Minute
+)
+
+// Redis key prefixes
+const (
+	keyPrefixLoginFailUser = "login_fail:user:"
+	keyPrefixLoginFailIP   = "login_fail:ip:"
+)
+
+// releaseLoginAttempt hands back an attempt that turned out to be a
+// successful login. A counter that has expired meanwhile is left alone, as
+// DECR would recreate it without an expiry.
+var releaseLoginAttempt = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
+// LoginLimiter counts staff login attempts per username and per IP address
+// and locks further attempts once either limit is reached. Every attempt is
+// counted before the credentials are checked, so concurrent guesses cannot
+// all slip past a limit none of them has reached yet; a successful login
+// gives its attempt back. Counters expire after the lockout window, which
+// also lifts the lock. While the counters cannot be updated, logins are
+// refused rather than left unlimited.
+type LoginLimiter struct{}
+
+func NewLoginLimiter() *LoginLimiter {
+	return &LoginLimiter{}
+}
+
+// Attempt counts a login attempt for the username and IP, returning an
+// error when either is locked out
+func (l *LoginLimiter) Attempt(ctx *gofr.Context, username, ip string) error {
+	count, err := l.increment(ctx, keyPrefixLoginFailUser+username)
+	if err != nil {
+		return err
+	}
+
+	if count > maxLoginFailuresPerUser {
+		return fmt.Errorf("account temporarily locked due to too many failed attempts")
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	count, err = l.increment(ctx, keyPrefixLoginFailIP+ip)
+	if err != nil {
+		return err
+	}
+
+	if count > maxLoginFailuresPerIP {
+		return fmt.Errorf("too many failed login attempts. Please try again later")
+	}
+
+	return nil
+}
+
+// Succeeded clears the username's failures and hands the attempt back to
+// the IP, so only failed attempts count against it
+func (l *LoginLimiter) Succeeded(ctx *gofr.Context, username, ip string) error {
+	if err := l.Reset(ctx, username); err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return releaseLoginAttempt.Run(ctx, ctx.Redis, []string{keyPrefixLoginFailIP + ip}).Err()
+}
+
+// Reset clears the failure counter for a username (on success or admin unlock)
+func (l *LoginLimiter) Reset(ctx *gofr.Context, username string) error {
+	return ctx.Redis.Del(ctx, keyPrefixLoginFailUser+username).Err()
+}
+
+// increment counts an attempt and returns the count so far, starting the
+// window with the first one. The counter is created with its expiry in the
+// same transaction, so it can never be left without one.
+func (l *LoginLimiter) increment(ctx *gofr.Context, key string) (int64, error) {
+	var count *redis.IntCmd
+
+	_, err := ctx.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, loginLockoutWindow)
+		count = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		ctx.Logger.Errorf("failed to record login attempt: %v", err)
+		return 0, fmt.Errorf("unable to verify login attempts, please try again later")
+	}
+
+	return count.Val(), nil
+}
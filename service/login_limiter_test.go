@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestConcurrentLoginAttemptsStopAtLimit checks that guesses sent together
+// cannot get more tries than the limit allows
+func TestConcurrentLoginAttemptsStopAtLimit(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	ctx.Redis = newTestRedis(t)
+	limiter := NewLoginLimiter()
+
+	const attempts = 20
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int32
+	)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if limiter.Attempt(ctx, "chef.ravi", "") == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != maxLoginFailuresPerUser {
+		t.Errorf("got %d attempts through, want %d", got, maxLoginFailuresPerUser)
+	}
+}
+
+func TestSuccessfulLoginGivesAttemptsBack(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	ctx.Redis = newTestRedis(t)
+	limiter := NewLoginLimiter()
+	ip := "203.0.113.7"
+
+	for i := 0; i < maxLoginFailuresPerUser; i++ {
+		if err := limiter.Attempt(ctx, "chef.ravi", ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := limiter.Attempt(ctx, "chef.ravi", ip); err == nil {
+		t.Fatal("attempt over the limit was allowed")
+	}
+
+	if err := limiter.Succeeded(ctx, "chef.ravi", ip); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := limiter.Attempt(ctx, "chef.ravi", ip); err != nil {
+		t.Errorf("attempt after a successful login: %v", err)
+	}
+
+	// The IP keeps the failures, less the successful attempt
+	count, err := ctx.Redis.Get(ctx, keyPrefixLoginFailIP+ip).Int()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := maxLoginFailuresPerUser; count != want {
+		t.Errorf("got %d attempts on the IP, want %d", count, want)
+	}
+}
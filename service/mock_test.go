@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"qr-dinein-backend/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+)
+
+const (
+	testOrderByID      = "SELECT id, restaurant_id, table_id, table_number, tab_id, customer_mobile, customer_name, customer_id, items, status, payment_status, special_instructions, total, bill, assigned_chef_id, estimated_ready_at, cancellation, created_at, updated_at FROM orders WHERE id = ? AND restaurant_id = ?"
+	testRestaurantByID = "SELECT id, name, slug, address, phone, logo, currency, tax_rate, active, created_at, updated_at FROM restaurants WHERE id = ?"
+)
+
+// newTestContext returns a request context backed by a mock database
+func newTestContext(t *testing.T) (*gofr.Context, sqlmock.Sqlmock) {
+	t.Helper()
+
+	c, mocks := container.NewMockContainer(t)
+
+	return &gofr.Context{Context: context.Background(), Container: c}, mocks.SQL
+}
+
+// newTestRedis returns a client to an in-memory Redis for the test
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return rdb
+}
+
+// expectOrder expects the order to be loaded by ID
+func expectOrder(t *testing.T, mock sqlmock.Sqlmock, o *model.Order) {
+	t.Helper()
+
+	mock.ExpectQuery(testOrderByID).
+		WithArgs(o.ID, o.RestaurantID).
+		WillReturnRows(orderRows(t, o))
+}
+
+// orderRows returns orders as the database would
+func orderRows(t *testing.T, orders ...*model.Order) *sqlmock.Rows {
+	t.Helper()
+
+	rows := sqlmock.NewRows([]string{"id", "restaurant_id", "table_id", "table_number", "tab_id", "customer_mobile", "customer_name", "customer_id", "items", "status", "payment_status", "special_instructions", "total", "bill", "assigned_chef_id", "estimated_ready_at", "cancellation", "created_at", "updated_at"})
+
+	now := time.Now()
+	for _, o := range orders {
+		items, err := json.Marshal(o.Items)
+		if err != nil {
+			t.Fatalf("marshal items: %v", err)
+		}
+
+		rows.AddRow(o.ID, o.RestaurantID, o.TableID, o.TableNumber, o.TabID, o.CustomerMobile, o.CustomerName, o.CustomerID, items, o.Status, o.PaymentStatus, "", o.Total, nil, nil, nil, nil, now, now)
+	}
+
+	return rows
+}
+
+// expectRestaurantName expects restaurant 1 to be loaded
+func expectRestaurantName(mock sqlmock.Sqlmock, name string) {
+	now := time.Now()
+	mock.ExpectQuery(testRestaurantByID).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "address", "phone", "logo", "currency", "tax_rate", "active", "created_at", "updated_at"}).
+			AddRow(1, name, "slug", "", "", "", "INR", 5, true, now, now))
+}
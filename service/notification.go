@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/notify"
+	"qr-dinein-backend/store"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+)
+
+// Settings controlling how a restaurant's customers are notified
+const (
+	settingNotificationChannel  = "notification_channel"   // sms (default), whatsapp or email
+	settingNotificationSenderID = "notification_sender_id" // SMS sender ID or email display name
+	settingNotificationLanguage = "notification_language"  // en (default) or hi
+)
+
+// Outbox delivery
+const (
+	notifyMaxAttempts  = 6
+	notifyRetryBase    = 30 * time.Second
+	notifyRetryMax     = 10 * time.Minute
+	notifyClaimTimeout = 2 * time.Minute // a worker must finish a batch within this
+	notifyBatchSize    = 10              // batches of notifySendTimeout sends must fit in notifyClaimTimeout
+	notifySendTimeout  = 10 * time.Second
+	notifyRunFor       = 4 * time.Second // claim further batches for this long, within the cron interval
+	maxOutboxList      = 200
+
+	// defaultNotificationTTL is how long an order update stays worth sending
+	defaultNotificationTTL = time.Hour
+)
+
+// notificationTTL overrides defaultNotificationTTL for messages that go
+// stale sooner
+var notificationTTL = map[string]time.Duration{
+	notify.TemplateOTP: otpExpiryMinutes * time.Minute,
+}
+
+// Notification metrics, registered by RegisterNotificationMetrics
+const (
+	metricNotificationAttempts    = "notification_attempts_total"
+	metricNotificationLatency     = "notification_delivery_seconds"
+	metricNotificationDeadLetters = "notification_dead_letters_total"
+)
+
+// RegisterNotificationMetrics registers the outbox delivery metrics with the app
+func RegisterNotificationMetrics(m container.Metrics) {
+	m.NewCounter(metricNotificationAttempts, "Notification delivery attempts by channel, template and result")
+	m.NewHistogram(metricNotificationLatency, "Seconds from queueing a notification to delivering it",
+		0.5, 1, 2, 5, 10, 30, 60, 300, 900)
+	m.NewCounter(metricNotificationDeadLetters, "Notifications dead-lettered after their last attempt or on expiry")
+}
+
+// Recipient is who a notification is for. Email is only used when the
+// restaurant notifies by email; everyone else is reached on their phone.
+type Recipient struct {
+	Phone string
+	Email string
+}
+
+// Notification sends customers templated messages over the channel their
+// restaurant chose, in the restaurant's language and under its sender ID.
+// Queued messages go through the outbox and are sent by DeliverOutbox.
+type Notification struct {
+	store           *store.Notification
+	notifiers       *notify.Registry
+	settingsStore   *store.Settings
+	restaurantStore *store.Restaurant
+}
+
+func NewNotification(s *store.Notification, notifiers *notify.Registry, settingsStore *store.Settings, restaurantStore *store.Restaurant) *Notification {
+	return &Notification{store: s, notifiers: notifiers, settingsStore: settingsStore, restaurantStore: restaurantStore}
+}
+
+// Queue composes a message and stores it in the outbox, to be sent by the
+// delivery worker. orderID links the message to the order it is about.
+func (svc *Notification) Queue(ctx *gofr.Context, restaurantID int, orderID *int, to Recipient, template string, data notify.Data) error {
+	msg, err := svc.Compose(ctx, restaurantID, to, template, data)
+	if err != nil {
+		return err
+	}
+
+	ttl, ok := notificationTTL[template]
+	if !ok {
+		ttl = defaultNotificationTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	return svc.store.Create(ctx, &model.Notification{
+		RestaurantID: restaurantID,
+		OrderID:      orderID,
+		Template:     template,
+		Channel:      msg.Channel,
+		Recipient:    msg.To,
+		Sender:       msg.From,
+		Subject:      msg.Subject,
+		Body:         msg.Body,
+		ExpiresAt:    &expiresAt,
+	})
+}
+
+// GetOutbox returns the restaurant's latest messages and their delivery
+// status. OTP bodies are left out so admins cannot read customers' codes.
+func (svc *Notification) GetOutbox(ctx *gofr.Context, restaurantID int, status string) ([]model.Notification, error) {
+	switch status {
+	case "", model.NotificationPending, model.NotificationSending, model.NotificationSent, model.NotificationDead:
+	default:
+		return nil, fmt.Errorf("invalid notification status '%s'", status)
+	}
+
+	list, err := svc.store.GetByRestaurant(ctx, restaurantID, status, maxOutboxList)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		redact(&list[i])
+	}
+
+	return list, nil
+}
+
+// Retry gives a dead-lettered message a fresh set of attempts
+func (svc *Notification) Retry(ctx *gofr.Context, restaurantID, id int) (*model.Notification, error) {
+	requeued, err := svc.store.Requeue(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !requeued {
+		return nil, fmt.Errorf("only dead-lettered messages that have not expired can be retried, and OTPs cannot")
+	}
+
+	n, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	redact(n)
+
+	return n, nil
+}
+
+func redact(n *model.Notification) {
+	if secret(n) {
+		n.Body = ""
+	}
+}
+
+// secret reports whether a message's body must not be kept once it is sent
+// or dead-lettered. OTP bodies hold the code, so they are cleared then.
+func secret(n *model.Notification) bool {
+	return n.Template == notify.TemplateOTP
+}
+
+// DeliverOutbox runs as a cron job and sends the outbox messages that are
+// due. Failed sends are retried with exponential backoff; messages that run
+// out of attempts or expire are dead-lettered. Messages are claimed in
+// small batches so each is finished well within notifyClaimTimeout, and
+// further batches are claimed while a backlog remains.
+func (svc *Notification) DeliverOutbox(ctx *gofr.Context) {
+	started := time.Now()
+
+	for {
+		token := uuid.NewString()
+
+		batch, err := svc.store.Claim(ctx, token, time.Now().Add(-notifyClaimTimeout), notifyBatchSize)
+		if err != nil {
+			ctx.Logger.Errorf("notification outbox: failed to claim messages: %v", err)
+			return
+		}
+
+		for i := range batch {
+			svc.deliverQueued(ctx, token, &batch[i])
+		}
+
+		if len(batch) < notifyBatchSize || time.Since(started) > notifyRunFor {
+			return
+		}
+	}
+}
+
+func (svc *Notification) deliverQueued(ctx *gofr.Context, token string, n *model.Notification) {
+	now := time.Now()
+
+	switch {
+	case n.ExpiresAt != nil && now.After(*n.ExpiresAt):
+		svc.deadLetter(ctx, token, n, "expired before it could be sent")
+		return
+
+	// A claim its worker never finished has already used its last attempt
+	case n.Attempts > notifyMaxAttempts:
+		svc.deadLetter(ctx, token, n, "delivery did not complete")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+	err := svc.Deliver(sendCtx, &notify.Message{
+		Channel: n.Channel,
+		To:      n.Recipient,
+		From:    n.Sender,
+		Subject: n.Subject,
+		Body:    n.Body,
+	})
+	cancel()
+
+	now = time.Now()
+	if err == nil {
+		ctx.Metrics().IncrementCounter(ctx, metricNotificationAttempts, "channel", n.Channel, "template", n.Template, "result", "sent")
+		ctx.Metrics().RecordHistogram(ctx, metricNotificationLatency, now.Sub(n.CreatedAt).Seconds(), "channel", n.Channel, "template", n.Template)
+
+		held, err := svc.store.MarkSent(ctx, n.ID, token, now, secret(n))
+		svc.checkRelease(ctx, n, held, err)
+
+		return
+	}
+
+	ctx.Metrics().IncrementCounter(ctx, metricNotificationAttempts, "channel", n.Channel, "template", n.Template, "result", "failed")
+	ctx.Logger.Errorf("notification %d failed (attempt %d): %v", n.ID, n.Attempts, err)
+
+	if n.Attempts >= notifyMaxAttempts {
+		svc.deadLetter(ctx, token, n, err.Error())
+		return
+	}
+
+	held, err := svc.store.MarkRetry(ctx, n.ID, token, err.Error(), now.Add(notifyBackoff(n.Attempts)))
+	svc.checkRelease(ctx, n, held, err)
+}
+
+func (svc *Notification) deadLetter(ctx *gofr.Context, token string, n *model.Notification, reason string) {
+	ctx.Metrics().IncrementCounter(ctx, metricNotificationDeadLetters, "channel", n.Channel, "template", n.Template)
+	ctx.Logger.Errorf("notification %d dead-lettered: %s", n.ID, reason)
+
+	held, err := svc.store.MarkDead(ctx, n.ID, token, reason, time.Now(), secret(n))
+	svc.checkRelease(ctx, n, held, err)
+}
+
+// checkRelease logs a message whose outcome was not recorded, either
+// because the update failed or because another worker took over the claim
+func (svc *Notification) checkRelease(ctx *gofr.Context, n *model.Notification, held bool, err error) {
+	if err != nil {
+		ctx.Logger.Errorf("notification outbox: failed to update message %d: %v", n.ID, err)
+		return
+	}
+
+	if !held {
+		ctx.Logger.Errorf("notification outbox: claim on message %d expired before it was updated", n.ID)
+	}
+}
+
+// notifyBackoff doubles the wait after each failed attempt, up to notifyRetryMax
+func notifyBackoff(attempts int) time.Duration {
+	backoff := notifyRetryBase
+	for i := 1; i < attempts && backoff < notifyRetryMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, notifyRetryMax)
+}
+
+// Compose renders a message from template for the restaurant. Channels the
+// deployment has no notifier for, and email to recipients without an
+// address, fall back to SMS.
+func (svc *Notification) Compose(ctx *gofr.Context, restaurantID int, to Recipient, template string, data notify.Data) (*notify.Message, error) {
+	channel := svc.setting(ctx, restaurantID, settingNotificationChannel, notify.ChannelSMS)
+	if _, ok := svc.notifiers.Get(channel); !ok {
+		ctx.Logger.Errorf("notification channel '%s' of restaurant %d is not configured, using SMS", channel, restaurantID)
+		channel = notify.ChannelSMS
+	}
+
+	address := to.Phone
+	if channel == notify.ChannelEmail {
+		if to.Email != "" {
+			address = to.Email
+		} else {
+			channel = notify.ChannelSMS
+		}
+	}
+
+	if address == "" {
+		return nil, notify.ErrNoRecipient
+	}
+
+	if data.Restaurant == "" {
+		if restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID); err == nil {
+			data.Restaurant = restaurant.Name
+		}
+	}
+
+	lang := svc.setting(ctx, restaurantID, settingNotificationLanguage, notify.DefaultLanguage)
+
+	subject, body, err := notify.Render(template, lang, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notify.Message{
+		Channel: channel,
+		To:      address,
+		From:    svc.setting(ctx, restaurantID, settingNotificationSenderID, ""),
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+// Deliver sends a composed message through its channel's notifier
+func (svc *Notification) Deliver(ctx context.Context, msg *notify.Message) error {
+	n, ok := svc.notifiers.Get(msg.Channel)
+	if !ok {
+		return fmt.Errorf("no notifier for channel '%s'", msg.Channel)
+	}
+
+	return n.Send(ctx, *msg)
+}
+
+func (svc *Notification) setting(ctx *gofr.Context, restaurantID int, key, fallback string) string {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, key); err == nil {
+		if value := strings.TrimSpace(setting.Value); value != "" {
+			return value
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/notify"
+	"qr-dinein-backend/store"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	testSettingByKey     = "SELECT id, restaurant_id, `key`, value FROM settings WHERE restaurant_id = ? AND `key` = ?"
+	testNotifyRestaurant = 1
+)
+
+// expectSetting expects a restaurant setting to be read; an empty value
+// means the restaurant has not set it
+func expectSetting(mock sqlmock.Sqlmock, key, value string) {
+	q := mock.ExpectQuery(testSettingByKey).WithArgs(testNotifyRestaurant, key)
+	if value == "" {
+		q.WillReturnError(sql.ErrNoRows)
+		return
+	}
+
+	q.WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "key", "value"}).
+		AddRow(1, testNotifyRestaurant, key, value))
+}
+
+func newTestNotification(notifiers ...notify.Notifier) *Notification {
+	return NewNotification(store.NewNotification(), notify.NewRegistry(notifiers...), store.NewSettings(), store.NewRestaurant())
+}
+
+// TestLocalisedMessagesAreDelivered sends a message composed in each
+// language through the fake notifier
+func TestLocalisedMessagesAreDelivered(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		template string
+		data     notify.Data
+		want     string
+	}{
+		{"english ready", "en", notify.TemplateOrderReady, notify.Data{OrderID: 42},
+			"Saravana Bhavan: Your order #42 is ready!"},
+		{"hindi ready", "hi", notify.TemplateOrderReady, notify.Data{OrderID: 42},
+			"Saravana Bhavan: आपका ऑर्डर #42 तैयार है!"},
+		{"hindi accepted with eta", "hi", notify.TemplateOrderAccepted, notify.Data{OrderID: 42, ETA: 15},
+			"Saravana Bhavan: आपका ऑर्डर #42 स्वीकार कर लिया गया है। यह लगभग 15 मिनट में तैयार होगा।"},
+		{"hindi otp", "hi", notify.TemplateOTP, notify.Data{OTP: "482913", Minutes: 5},
+			"Saravana Bhavan: आपका सत्यापन कोड 482913 है। यह 5 मिनट तक मान्य है।"},
+		{"unsupported language falls back to english", "ta", notify.TemplateOrderCancelled, notify.Data{OrderID: 42, Reason: "out of stock"},
+			"Saravana Bhavan: Your order #42 has been cancelled. Reason: out of stock."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, mock := newTestContext(t)
+			sms := notify.NewFake(notify.ChannelSMS)
+			svc := newTestNotification(sms)
+
+			expectSetting(mock, settingNotificationChannel, "")
+			expectRestaurantName(mock, "Saravana Bhavan")
+			expectSetting(mock, settingNotificationLanguage, tt.lang)
+			expectSetting(mock, settingNotificationSenderID, "SRVBVN")
+
+			msg, err := svc.Compose(ctx, testNotifyRestaurant, Recipient{Phone: "+919876543210"}, tt.template, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := svc.Deliver(ctx, msg); err != nil {
+				t.Fatal(err)
+			}
+
+			sent := sms.Messages()
+			if len(sent) != 1 {
+				t.Fatalf("got %d messages, want 1", len(sent))
+			}
+
+			if sent[0].Body != tt.want {
+				t.Errorf("got body %q, want %q", sent[0].Body, tt.want)
+			}
+
+			if sent[0].To != "+919876543210" || sent[0].From != "SRVBVN" {
+				t.Errorf("got message to %s from %s", sent[0].To, sent[0].From)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUnconfiguredChannelFallsBackToSMS(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	sms := notify.NewFake(notify.ChannelSMS)
+	svc := newTestNotification(sms)
+
+	expectSetting(mock, settingNotificationChannel, notify.ChannelWhatsApp)
+	expectSetting(mock, settingNotificationLanguage, "hi")
+	expectSetting(mock, settingNotificationSenderID, "")
+
+	msg, err := svc.Compose(ctx, testNotifyRestaurant, Recipient{Phone: "+919876543210"}, notify.TemplateOrderPreparing,
+		notify.Data{Restaurant: "Saravana Bhavan", OrderID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.Deliver(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := sms.Messages()
+	if len(sent) != 1 || sent[0].Channel != notify.ChannelSMS {
+		t.Fatalf("got %+v, want one SMS", sent)
+	}
+
+	if !strings.Contains(sent[0].Body, "तैयार किया जा रहा है") {
+		t.Errorf("got body %q, want the Hindi text", sent[0].Body)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A batch must be sent before its claim goes stale, or a second worker
+// sends the same messages again
+func TestOutboxBatchFitsInClaim(t *testing.T) {
+	if batch := notifyBatchSize * notifySendTimeout; batch >= notifyClaimTimeout {
+		t.Errorf("a batch can take %v, longer than the %v claim timeout", batch, notifyClaimTimeout)
+	}
+}
+
+func TestOTPBodiesAreNotKept(t *testing.T) {
+	if !secret(&model.Notification{Template: notify.TemplateOTP}) {
+		t.Error("OTP bodies would be kept after delivery")
+	}
+
+	if secret(&model.Notification{Template: notify.TemplateOrderReady}) {
+		t.Error("order update bodies would be cleared after delivery")
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strconv"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type OptionGroup struct {
+	store        *store.OptionGroup
+	productStore *store.Product
+	audit        *Audit
+}
+
+func NewOptionGroup(s *store.OptionGroup, productStore *store.Product, audit *Audit) *OptionGroup {
+	return &OptionGroup{store: s, productStore: productStore, audit: audit}
+}
+
+func (svc *OptionGroup) GetAll(ctx *gofr.Context, restaurantID, productID int) ([]model.OptionGroup, error) {
+	groups, err := svc.store.GetByProductIDs(ctx, restaurantID, []int{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	if groups[productID] == nil {
+		return []model.OptionGroup{}, nil
+	}
+
+	return groups[productID], nil
+}
+
+func (svc *OptionGroup) GetByID(ctx *gofr.Context, restaurantID, productID, id int) (*model.OptionGroup, error) {
+	return svc.store.GetByID(ctx, restaurantID, productID, id)
+}
+
+func (svc *OptionGroup) Create(ctx *gofr.Context, restaurantID, productID int, g *model.OptionGroup) (*model.OptionGroup, error) {
+	if _, err := svc.productStore.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	if err := validateOptionGroup(g); err != nil {
+		return nil, err
+	}
+
+	g.RestaurantID = restaurantID
+	g.ProductID = productID
+
+	result, err := svc.store.Create(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "option_group", result.ID, AuditCreate, nil, result)
+
+	return result, nil
+}
+
+func (svc *OptionGroup) Update(ctx *gofr.Context, restaurantID, productID, id int, g *model.OptionGroup) (*model.OptionGroup, error) {
+	before, err := svc.store.GetByID(ctx, restaurantID, productID, id)
+	if err != nil {
+		return nil, fmt.Errorf("option group not found: %w", err)
+	}
+
+	if err := validateOptionGroup(g); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]bool, len(before.Options))
+	for _, opt := range before.Options {
+		known[opt.ID] = true
+	}
+	for _, opt := range g.Options {
+		if opt.ID != 0 && !known[opt.ID] {
+			return nil, fmt.Errorf("option %d does not belong to this group", opt.ID)
+		}
+	}
+
+	result, err := svc.store.Update(ctx, restaurantID, productID, id, g)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "option_group", id, AuditUpdate, before, result)
+
+	return result, nil
+}
+
+func (svc *OptionGroup) Delete(ctx *gofr.Context, restaurantID, productID, id int) error {
+	before, err := svc.store.GetByID(ctx, restaurantID, productID, id)
+	if err != nil {
+		return fmt.Errorf("option group not found: %w", err)
+	}
+
+	if err := svc.store.Delete(ctx, restaurantID, productID, id); err != nil {
+		return err
+	}
+
+	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "option_group", id, AuditDelete, before, nil)
+
+	return nil
+}
+
+// invalidateCache drops the cached product list, which embeds option groups
+func (svc *OptionGroup) invalidateCache(ctx *gofr.Context, restaurantID int) {
+	cacheKey := "products:" + strconv.Itoa(restaurantID)
+	ctx.Redis.Del(ctx, cacheKey)
+}
+
+func validateOptionGroup(g *model.OptionGroup) error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("option group name is required")
+	}
+
+	if g.SelectionType == "" {
+		g.SelectionType = model.SelectionSingle
+	}
+
+	if len(g.Options) == 0 {
+		return fmt.Errorf("option group must have at least one option")
+	}
+
+	for _, opt := range g.Options {
+		if strings.TrimSpace(opt.Name) == "" {
+			return fmt.Errorf("option name is required")
+		}
+	}
+
+	if g.MinSelect < 0 || g.MaxSelect < 0 {
+		return fmt.Errorf("min and max selections cannot be negative")
+	}
+
+	switch g.SelectionType {
+	case model.SelectionSingle:
+		if g.MinSelect > 1 {
+			return fmt.Errorf("single select group cannot require more than one option")
+		}
+		g.MaxSelect = 1
+	case model.SelectionMulti:
+		if g.MaxSelect > 0 && g.MinSelect > g.MaxSelect {
+			return fmt.Errorf("min selections cannot exceed max selections")
+		}
+	default:
+		return fmt.Errorf("invalid selection type '%s'", g.SelectionType)
+	}
+
+	if g.MinSelect > len(g.Options) {
+		return fmt.Errorf("min selections cannot exceed the number of options")
+	}
+
+	return nil
+}
+
+// priceSelections validates the options chosen for a product against its
+// option groups and returns the snapshotted selections and their total price delta.
+func priceSelections(p model.Product, groups []model.OptionGroup, selected []model.OrderItemOption) ([]model.OrderItemOption, float64, error) {
+	type located struct {
+		group  *model.OptionGroup
+		option model.Option
+	}
+
+	index := make(map[int]located)
+	for i := range groups {
+		for _, opt := range groups[i].Options {
+			index[opt.ID] = located{group: &groups[i], option: opt}
+		}
+	}
+
+	counts := make(map[int]int)
+	seen := make(map[int]bool)
+	priced := make([]model.OrderItemOption, 0, len(selected))
+	delta := 0.0
+
+	for _, sel := range selected {
+		loc, ok := index[sel.OptionID]
+		if !ok {
+			return nil, 0, fmt.Errorf("option %d is not offered on '%s'", sel.OptionID, p.Name)
+		}
+		if seen[sel.OptionID] {
+			return nil, 0, fmt.Errorf("option '%s' selected more than once on '%s'", loc.option.Name, p.Name)
+		}
+		if !loc.option.Available {
+			return nil, 0, fmt.Errorf("option '%s' on '%s' is not available", loc.option.Name, p.Name)
+		}
+
+		seen[sel.OptionID] = true
+		counts[loc.group.ID]++
+		delta += loc.option.PriceDelta
+
+		priced = append(priced, model.OrderItemOption{
+			OptionID:   loc.option.ID,
+			GroupID:    loc.group.ID,
+			GroupName:  loc.group.Name,
+			Name:       loc.option.Name,
+			PriceDelta: loc.option.PriceDelta,
+		})
+	}
+
+	for _, g := range groups {
+		n := counts[g.ID]
+		if n < g.MinSelect {
+			return nil, 0, fmt.Errorf("'%s' on '%s' requires at least %d selection(s)", g.Name, p.Name, g.MinSelect)
+		}
+		if g.MaxSelect > 0 && n > g.MaxSelect {
+			return nil, 0, fmt.Errorf("'%s' on '%s' allows at most %d selection(s)", g.Name, p.Name, g.MaxSelect)
+		}
+	}
+
+	return priced, delta, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"qr-dinein-backend/event"
 	"qr-dinein-backend/model"
 	"qr-dinein-backend/store"
 	"qr-dinein-backend/strategy"
@@ -11,21 +12,65 @@ import (
 	"gofr.dev/pkg/gofr"
 )
 
+const statusAwaitingPayment = "awaiting_payment"
+
+// maxMarkPaidAttempts bounds how often a payment is re-applied to an order
+// that other requests keep changing
+const maxMarkPaidAttempts = 3
+
+// maxJoinTabAttempts bounds how often a new order tries a table's tab when
+// the tab keeps being closed under it
+const maxJoinTabAttempts = 3
+
+// Setting controlling when customers pay
+const (
+	settingPaymentMode  = "payment_mode"
+	paymentModePayFirst = "pay_first"
+	paymentModePayLater = "pay_later"
+)
+
 type Order struct {
-	store         *store.Order
-	productStore  *store.Product
-	settingsStore *store.Settings
-	customerSvc   *Customer
-	chefResolver  *strategy.Resolver
+	store            *store.Order
+	ticketStore      *store.Ticket
+	tabStore         *store.Tab
+	productStore     *store.Product
+	optionGroupStore *store.OptionGroup
+	settingsStore    *store.Settings
+	restaurantStore  *store.Restaurant
+	taxStore         *store.Tax
+	customerSvc      *Customer
+	notifications    *Notification
+	tableSvc         *Table
+	prepTimeSvc      *PrepTime
+	chefResolver     *strategy.Resolver
+	broker           *event.Broker
+	audit            *Audit
+
+	// refundHook refunds paid orders when items are voided or the order is
+	// cancelled
+	refundHook RefundHook
+
+	// printHook queues kitchen tickets for printing once they are created
+	printHook PrintHook
 }
 
-func NewOrder(s *store.Order, productStore *store.Product, settingsStore *store.Settings, customerSvc *Customer, chefResolver *strategy.Resolver) *Order {
+func NewOrder(s *store.Order, ticketStore *store.Ticket, tabStore *store.Tab, productStore *store.Product, optionGroupStore *store.OptionGroup, settingsStore *store.Settings, restaurantStore *store.Restaurant, taxStore *store.Tax, customerSvc *Customer, notifications *Notification, tableSvc *Table, prepTimeSvc *PrepTime, chefResolver *strategy.Resolver, broker *event.Broker, audit *Audit) *Order {
 	return &Order{
-		store:         s,
-		productStore:  productStore,
-		settingsStore: settingsStore,
-		customerSvc:   customerSvc,
-		chefResolver:  chefResolver,
+		store:            s,
+		ticketStore:      ticketStore,
+		tabStore:         tabStore,
+		productStore:     productStore,
+		optionGroupStore: optionGroupStore,
+		settingsStore:    settingsStore,
+		restaurantStore:  restaurantStore,
+		taxStore:         taxStore,
+		customerSvc:      customerSvc,
+		notifications:    notifications,
+		tableSvc:         tableSvc,
+		prepTimeSvc:      prepTimeSvc,
+		chefResolver:     chefResolver,
+		broker:           broker,
+		audit:            audit,
 	}
 }
 
@@ -37,12 +82,54 @@ func (svc *Order) GetByStatus(ctx *gofr.Context, restaurantID int, status string
 	return svc.store.GetByStatus(ctx, restaurantID, status)
 }
 
-func (svc *Order) GetByPhone(ctx *gofr.Context, restaurantID int, phone string) ([]model.Order, error) {
-	return svc.store.GetByPhone(ctx, restaurantID, phone)
+// GetCustomerOrders returns the signed-in customer's orders at the restaurant
+func (svc *Order) GetCustomerOrders(ctx *gofr.Context, restaurantID int, sessionToken string) ([]model.Order, error) {
+	session, err := svc.customerSvc.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.store.GetByPhone(ctx, restaurantID, session.PhoneNumber)
+}
+
+// GetCustomerHistory returns the signed-in customer's latest orders across
+// all their visits and restaurants
+func (svc *Order) GetCustomerHistory(ctx *gofr.Context, sessionToken string) ([]model.Order, error) {
+	session, err := svc.customerSvc.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.store.GetHistory(ctx, session.PhoneNumber, maxCustomerHistory)
+}
+
+// GetCustomerOrder returns one of the signed-in customer's orders. Orders
+// placed by anyone else are reported as not found.
+func (svc *Order) GetCustomerOrder(ctx *gofr.Context, restaurantID, id int, sessionToken string) (*model.Order, error) {
+	session, err := svc.customerSvc.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired session: %w", err)
+	}
+
+	o, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil || o.CustomerMobile != session.PhoneNumber {
+		return nil, fmt.Errorf("order not found")
+	}
+
+	return o, nil
 }
 
 func (svc *Order) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Order, error) {
-	return svc.store.GetByID(ctx, restaurantID, id)
+	o, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Tickets, err = svc.ticketStore.GetByOrder(ctx, restaurantID, id); err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	return o, nil
 }
 
 func (svc *Order) Create(ctx *gofr.Context, restaurantID int, o *model.Order) (*model.Order, error) {
@@ -50,52 +137,40 @@ func (svc *Order) Create(ctx *gofr.Context, restaurantID int, o *model.Order) (*
 		return nil, fmt.Errorf("order must have at least one item")
 	}
 
+	// Tickets are planned here; any sent with the order could route it to
+	// another restaurant's kitchen
+	o.Tickets = nil
+
 	// Check if customer auth is required
 	authRequired := false
 	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, "customer_auth_required"); err == nil && setting.Value == "true" {
 		authRequired = true
 	}
 
-	if authRequired {
+	if authRequired && o.SessionToken == "" {
 		// Customer must verify phone via OTP before placing an order
-		if o.SessionToken == "" {
-			return nil, fmt.Errorf("customer authentication is required: please verify your phone number first")
-		}
+		return nil, fmt.Errorf("customer authentication is required: please verify your phone number first")
+	}
 
-		sessionToken := o.SessionToken
+	// Only a session vouches for the number, whatever the request says
+	o.CustomerID = nil
 
-		session, err := svc.customerSvc.GetSession(ctx, sessionToken)
+	if o.SessionToken != "" {
+		// Signed-in customers order as their verified number, under their
+		// saved name unless they give another
+		session, err := svc.customerSvc.GetSession(ctx, o.SessionToken)
 		if err != nil {
 			return nil, fmt.Errorf("invalid or expired session: %w", err)
 		}
 
-		if session.RestaurantID != restaurantID {
-			return nil, fmt.Errorf("session is not valid for this restaurant")
-		}
-
 		o.CustomerMobile = session.PhoneNumber
+		o.CustomerID = &session.CustomerID
 
-		defer func() {
-			_ = svc.customerSvc.InvalidateSession(ctx, sessionToken)
-		}()
-	} else if o.SessionToken != "" {
-		// Auth not required but session token provided — still validate it
-		sessionToken := o.SessionToken
-
-		session, err := svc.customerSvc.GetSession(ctx, sessionToken)
-		if err != nil {
-			return nil, fmt.Errorf("invalid or expired session: %w", err)
-		}
-
-		if session.RestaurantID != restaurantID {
-			return nil, fmt.Errorf("session is not valid for this restaurant")
+		if o.CustomerName == "" {
+			if customer, err := svc.customerSvc.GetByID(ctx, session.CustomerID); err == nil {
+				o.CustomerName = customer.Name
+			}
 		}
-
-		o.CustomerMobile = session.PhoneNumber
-
-		defer func() {
-			_ = svc.customerSvc.InvalidateSession(ctx, sessionToken)
-		}()
 	} else if o.CustomerMobile == "" {
 		return nil, fmt.Errorf("customer mobile is required")
 	}
@@ -103,32 +178,199 @@ func (svc *Order) Create(ctx *gofr.Context, restaurantID int, o *model.Order) (*
 	// Clear session token before storing (not persisted)
 	o.SessionToken = ""
 
-	// Calculate total from items
-	var total float64
-	for _, item := range o.Items {
-		total += item.Price * float64(item.Quantity)
+	// Resolve the table from the signed token in its QR code
+	if o.TableToken != "" {
+		table, err := svc.tableSvc.Verify(ctx, restaurantID, o.TableToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid table: %w", err)
+		}
+
+		o.TableID = &table.ID
+		o.TableNumber = &table.Number
+	} else if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, "table_token_required"); err == nil && setting.Value == "true" {
+		return nil, fmt.Errorf("please scan the QR code on your table to place an order")
+	} else {
+		o.TableID = nil
 	}
 
-	o.Total = total
-	o.RestaurantID = restaurantID
+	o.TableToken = ""
 
-	if o.Status == "" {
-		o.Status = "pending"
+	// Re-price items from the product catalogue
+	items, err := svc.priceItems(ctx, restaurantID, o.Items)
+	if err != nil {
+		return nil, err
 	}
 
-	// Auto-assign chef
-	assigner := svc.chefResolver.Resolve(ctx, restaurantID)
-	chefID, err := assigner.Assign(ctx, restaurantID)
+	bill, err := svc.buildBill(ctx, restaurantID, items)
 	if err != nil {
-		ctx.Logger.Errorf("chef auto-assignment failed: %v", err)
+		return nil, fmt.Errorf("failed to compute bill: %w", err)
+	}
+
+	o.Items = items
+	o.Bill = bill
+	o.Total = bill.GrandTotal
+	o.RestaurantID = restaurantID
+
+	o.PaymentStatus = model.PaymentUnpaid
+
+	// Pay-first orders reach the kitchen only once the payment is captured
+	if svc.paymentMode(ctx, restaurantID) == paymentModePayFirst {
+		o.Status = statusAwaitingPayment
+		o.AssignedChefID = nil
 	} else {
-		o.AssignedChefID = chefID
+		if o.Status == "" {
+			o.Status = "pending"
+		}
+
+		svc.dispatch(ctx, o)
+	}
+
+	o.TabID = nil
+
+	tickets := o.Tickets
+	o.Tickets = nil
+
+	created, err := svc.store.Create(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.saveTickets(ctx, created, tickets)
+
+	// Orders from a scanned table run up one tab until the bill is settled.
+	// A typed-in table number could put an order on someone else's bill.
+	if created.TableID != nil {
+		svc.joinTab(ctx, created)
+	}
+
+	svc.publish(ctx, event.TypeOrderCreated, created)
+	if created.AssignedChefID != nil {
+		svc.publish(ctx, event.TypeChefAssigned, created)
 	}
 
-	// Calculate estimated ready time
+	svc.audit.Record(ctx, restaurantID, "order", created.ID, AuditCreate, nil, created)
+	svc.notifyCustomer(ctx, nil, created)
+
+	return created, nil
+}
+
+// joinTab puts a new order on its table's open tab, opening one when there
+// is none. The order joins only while the tab is still open, so a tab closed
+// in the meantime is replaced by a new one rather than joined after its
+// bill was settled.
+func (svc *Order) joinTab(ctx *gofr.Context, o *model.Order) {
+	for attempt := 0; attempt < maxJoinTabAttempts; attempt++ {
+		tab, err := svc.tabStore.Open(ctx, o.RestaurantID, o.TableID, *o.TableNumber)
+		if err != nil {
+			ctx.Logger.Errorf("failed to open tab for order %d: %v", o.ID, err)
+			return
+		}
+
+		joined, err := svc.store.SetTab(ctx, o.RestaurantID, o.ID, tab.ID)
+		if err != nil {
+			ctx.Logger.Errorf("failed to put order %d on tab %d: %v", o.ID, tab.ID, err)
+			return
+		}
+
+		if joined {
+			o.TabID = &tab.ID
+			return
+		}
+	}
+
+	ctx.Logger.Errorf("order %d was not put on a tab: its table's tabs kept closing", o.ID)
+}
+
+// dispatch hands a new order to the kitchen: it splits the order into
+// station tickets, auto-assigns their chefs and estimates when the order
+// will be ready. The tickets are stored once the order has an ID.
+func (svc *Order) dispatch(ctx *gofr.Context, o *model.Order) {
+	svc.planTickets(ctx, o)
 	svc.calculateEstimatedReadyAt(ctx, o)
+}
+
+func (svc *Order) paymentMode(ctx *gofr.Context, restaurantID int) string {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingPaymentMode); err == nil && setting.Value == paymentModePayFirst {
+		return paymentModePayFirst
+	}
+
+	return paymentModePayLater
+}
+
+// MarkPaid records a captured payment on the order and, for pay-first orders
+// waiting on it, releases the order to the kitchen. Orders already paid are
+// returned unchanged, so a payment is only applied once.
+func (svc *Order) MarkPaid(ctx *gofr.Context, restaurantID, id int) (*model.Order, error) {
+	for attempt := 0; attempt < maxMarkPaidAttempts; attempt++ {
+		existing, err := svc.store.GetByID(ctx, restaurantID, id)
+		if err != nil {
+			return nil, fmt.Errorf("order not found: %w", err)
+		}
+
+		if existing.PaymentStatus != model.PaymentUnpaid {
+			return existing, nil
+		}
+
+		updated, ok, err := svc.markPaid(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return updated, nil
+		}
+	}
 
-	return svc.store.Create(ctx, o)
+	return nil, fmt.Errorf("order %d kept changing while it was being marked paid", id)
+}
+
+// markPaid applies the payment to the order as it was read. It reports false
+// when the order changed in the meantime and must be read again.
+func (svc *Order) markPaid(ctx *gofr.Context, existing *model.Order) (*model.Order, bool, error) {
+	setClauses := []string{"payment_status = ?"}
+	args := []interface{}{model.PaymentPaid}
+
+	var released model.Order
+	if existing.Status == statusAwaitingPayment {
+		released = *existing
+		released.Status = "pending"
+		released.Items = make([]model.OrderItem, len(existing.Items))
+		copy(released.Items, existing.Items)
+		svc.dispatch(ctx, &released)
+
+		itemsJSON, err := json.Marshal(released.Items)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to marshal items: %w", err)
+		}
+
+		setClauses = append(setClauses, "status = ?", "items = ?", "assigned_chef_id = ?", "estimated_ready_at = ?")
+		args = append(args, released.Status, string(itemsJSON), released.AssignedChefID, released.EstimatedReadyAt)
+	}
+
+	updated, ok, err := svc.store.UpdateFrom(ctx, existing.RestaurantID, existing.ID, existing.Status, existing.PaymentStatus, setClauses, args)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	if existing.Status == statusAwaitingPayment {
+		svc.saveTickets(ctx, updated, released.Tickets)
+	}
+
+	svc.publishChanges(ctx, existing, updated)
+	svc.audit.Record(ctx, existing.RestaurantID, "order", existing.ID, AuditUpdate, existing, updated)
+	svc.notifyCustomer(ctx, existing, updated)
+
+	// A payment captured after the order was cancelled goes straight back
+	if existing.Status == "cancelled" {
+		svc.refundCancelled(ctx, updated)
+	}
+
+	return updated, true, nil
+}
+
+// SetPaymentStatus records a refund or settlement outcome on the order
+func (svc *Order) SetPaymentStatus(ctx *gofr.Context, restaurantID, id int, status string) error {
+	_, err := svc.store.Update(ctx, restaurantID, id, []string{"payment_status = ?"}, []interface{}{status})
+	return err
 }
 
 func (svc *Order) Update(ctx *gofr.Context, restaurantID, id int, o *model.Order) (*model.Order, error) {
@@ -142,6 +384,11 @@ func (svc *Order) Update(ctx *gofr.Context, restaurantID, id int, o *model.Order
 		return nil, fmt.Errorf("chef can only be assigned when order is in pending state")
 	}
 
+	// Cancelling needs a reason, so it goes through Cancel
+	if o.Status == "cancelled" && existing.Status != "cancelled" {
+		return nil, fmt.Errorf("cancelling an order requires a reason, use the cancel endpoint")
+	}
+
 	// Validate status transitions
 	if o.Status != "" && o.Status != existing.Status {
 		if !isValidStatusTransition(existing.Status, o.Status) {
@@ -173,21 +420,85 @@ func (svc *Order) Update(ctx *gofr.Context, restaurantID, id int, o *model.Order
 		setClauses = append(setClauses, "customer_name = ?")
 		args = append(args, o.CustomerName)
 	}
+	var items []model.OrderItem
+	var plan *model.Order
 	if len(o.Items) > 0 {
-		setClauses = append(setClauses, "items = ?")
-		itemsJSON, err := json.Marshal(o.Items)
+		// The payment being collected is for the current bill, and the
+		// kitchen is only planned once it is paid
+		if existing.Status == statusAwaitingPayment {
+			return nil, fmt.Errorf("items cannot be changed while the order is awaiting payment")
+		}
+
+		// A paid or refunded bill is settled; changing it would leave the
+		// total out of step with what was collected
+		if existing.PaymentStatus != model.PaymentUnpaid {
+			return nil, fmt.Errorf("items cannot be changed once the order has been paid")
+		}
+
+		// Items can only be replaced before the kitchen starts on them
+		if !allItemsQueued(existing.Items) {
+			return nil, fmt.Errorf("items can only be changed before the kitchen starts preparing them")
+		}
+
+		// Re-price items from the product catalogue
+		items, err = svc.priceItems(ctx, restaurantID, o.Items)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal items: %w", err)
+			return nil, err
 		}
-		args = append(args, string(itemsJSON))
 
-		// Recalculate total
-		var total float64
-		for _, item := range o.Items {
-			total += item.Price * float64(item.Quantity)
+		bill, err := svc.buildBill(ctx, restaurantID, items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute bill: %w", err)
 		}
+
+		setClauses = append(setClauses, "bill = ?")
+		billJSON, err := json.Marshal(bill)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bill: %w", err)
+		}
+		args = append(args, string(billJSON))
+
 		setClauses = append(setClauses, "total = ?")
-		args = append(args, total)
+		args = append(args, bill.GrandTotal)
+
+		// Re-route the new items to stations and re-estimate; a chef given
+		// in the same request takes precedence over the planned one
+		plan = &model.Order{RestaurantID: restaurantID, Items: items}
+		svc.planTickets(ctx, plan)
+		if o.AssignedChefID != nil {
+			plan.AssignedChefID = o.AssignedChefID
+		} else {
+			setClauses = append(setClauses, "assigned_chef_id = ?")
+			args = append(args, plan.AssignedChefID)
+		}
+		svc.calculateEstimatedReadyAt(ctx, plan)
+		setClauses = append(setClauses, "estimated_ready_at = ?")
+		args = append(args, plan.EstimatedReadyAt)
+	}
+	if o.Status != "" && o.Status != existing.Status {
+		// Carry a whole-order status change down to the items
+		if items == nil {
+			items = make([]model.OrderItem, len(existing.Items))
+			copy(items, existing.Items)
+		}
+		applyOrderStatus(items, o.Status, time.Now())
+
+		// Items the kitchen just started are re-estimated from now
+		if o.Status == "preparing" && plan == nil {
+			svc.estimateStarted(ctx, existing, items, queuedLines(existing.Items))
+			if eta := latestItemETA(items); eta != nil {
+				setClauses = append(setClauses, "estimated_ready_at = ?")
+				args = append(args, *eta)
+			}
+		}
+	}
+	if items != nil {
+		setClauses = append(setClauses, "items = ?")
+		itemsJSON, err := json.Marshal(items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal items: %w", err)
+		}
+		args = append(args, string(itemsJSON))
 	}
 	if o.SpecialInstructions != "" {
 		setClauses = append(setClauses, "special_instructions = ?")
@@ -198,39 +509,179 @@ func (svc *Order) Update(ctx *gofr.Context, restaurantID, id int, o *model.Order
 		return existing, nil
 	}
 
-	return svc.store.Update(ctx, restaurantID, id, setClauses, args)
+	// The checks above and the items carried over were read from existing,
+	// so only apply the update if nothing has moved the order on since
+	updated, ok, err := svc.store.UpdateFrom(ctx, restaurantID, id, existing.Status, existing.PaymentStatus, setClauses, args)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("the order was changed while it was being updated, please try again")
+	}
+
+	if plan != nil {
+		if err := svc.ticketStore.DeleteByOrder(ctx, id); err != nil {
+			ctx.Logger.Errorf("failed to delete tickets for order %d: %v", id, err)
+		}
+		svc.saveTickets(ctx, updated, plan.Tickets)
+	}
+
+	// A manually assigned chef picks up tickets no station chef could take
+	if o.AssignedChefID != nil {
+		if err := svc.ticketStore.AssignUnassigned(ctx, id, *o.AssignedChefID); err != nil {
+			ctx.Logger.Errorf("failed to assign tickets for order %d: %v", id, err)
+		}
+	}
+
+	svc.syncTickets(ctx, updated)
+	svc.prepTimeSvc.Record(ctx, restaurantID, id, existing.Items, updated.Items, lineChefs(updated.Tickets, updated.AssignedChefID))
+	svc.publishChanges(ctx, existing, updated)
+	svc.audit.Record(ctx, restaurantID, "order", id, AuditUpdate, existing, updated)
+	svc.notifyCustomer(ctx, existing, updated)
+
+	return updated, nil
 }
 
-func (svc *Order) Delete(ctx *gofr.Context, restaurantID, id int) error {
-	return svc.store.Delete(ctx, restaurantID, id)
+// publishChanges emits an event for every tracked field that differs between before and after
+func (svc *Order) publishChanges(ctx *gofr.Context, before, after *model.Order) {
+	if before.Status != after.Status {
+		svc.publish(ctx, event.TypeStatusChanged, after)
+	}
+
+	if !equalIntPtr(before.AssignedChefID, after.AssignedChefID) {
+		svc.publish(ctx, event.TypeChefAssigned, after)
+	}
+
+	if !equalTimePtr(before.EstimatedReadyAt, after.EstimatedReadyAt) {
+		svc.publish(ctx, event.TypeETAChanged, after)
+	}
 }
 
-const defaultPrepTime = 5 // minutes
+// publish pushes an order event to subscribed screens. Failures are logged
+// and never fail the request that triggered them.
+func (svc *Order) publish(ctx *gofr.Context, eventType string, o *model.Order) {
+	evt := &model.OrderEvent{
+		Type:             eventType,
+		RestaurantID:     o.RestaurantID,
+		OrderID:          o.ID,
+		Status:           o.Status,
+		AssignedChefID:   o.AssignedChefID,
+		ChefIDs:          ticketChefIDs(o.Tickets),
+		EstimatedReadyAt: o.EstimatedReadyAt,
+	}
 
-func (svc *Order) calculateEstimatedReadyAt(ctx *gofr.Context, o *model.Order) {
-	// Collect unique product IDs
-	productIDs := make([]int, 0, len(o.Items))
+	if eventType == event.TypeOrderCreated {
+		evt.Order = o
+	}
+
+	if err := svc.broker.Publish(ctx, ctx.Redis, evt); err != nil {
+		ctx.Logger.Errorf("failed to publish %s for order %d: %v", eventType, o.ID, err)
+	}
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (svc *Order) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	existing, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return fmt.Errorf("order not found: %w", err)
+	}
+
+	if err := svc.store.Delete(ctx, restaurantID, id); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "order", id, AuditDelete, existing, nil)
+
+	return nil
+}
+
+// priceItems looks up every ordered product in the restaurant's catalogue and
+// returns the items with the authoritative name, price and veg flag, pricing
+// any selected options. Client-supplied values are ignored.
+func (svc *Order) priceItems(ctx *gofr.Context, restaurantID int, items []model.OrderItem) ([]model.OrderItem, error) {
+	productIDs := make([]int, 0, len(items))
 	seen := make(map[int]bool)
-	for _, item := range o.Items {
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than 0 for product %d", item.ProductID)
+		}
 		if !seen[item.ProductID] {
 			productIDs = append(productIDs, item.ProductID)
 			seen[item.ProductID] = true
 		}
 	}
 
-	// Look up prep times from DB
-	prepTimes, err := svc.productStore.GetPrepTimes(ctx, o.RestaurantID, productIDs)
+	products, err := svc.productStore.GetByIDs(ctx, restaurantID, productIDs)
 	if err != nil {
-		ctx.Logger.Errorf("failed to get prep times: %v", err)
+		return nil, fmt.Errorf("failed to load products: %w", err)
 	}
 
+	optionGroups, err := svc.optionGroupStore.GetByProductIDs(ctx, restaurantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product options: %w", err)
+	}
+
+	priced := make([]model.OrderItem, 0, len(items))
+	for _, item := range items {
+		p, ok := products[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product %d not found", item.ProductID)
+		}
+		if !p.Available {
+			return nil, fmt.Errorf("product '%s' is not available", p.Name)
+		}
+
+		options, delta, err := priceSelections(p, optionGroups[p.ID], item.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		item.LineID = len(priced) + 1
+		item.Name = p.Name
+		item.Price = roundMoney(p.Price + delta)
+		item.Veg = p.Veg
+		item.Options = options
+		item.Status = model.ItemQueued
+		item.EstimatedReadyAt = nil
+		item.StartedAt = nil
+		item.ReadyAt = nil
+		item.ServedAt = nil
+		item.VoidedAt = nil
+
+		if item.Price < 0 {
+			return nil, fmt.Errorf("invalid price for product '%s'", p.Name)
+		}
+
+		priced = append(priced, item)
+	}
+
+	return priced, nil
+}
+
+const defaultPrepTime = 5 // minutes
+
+// calculateEstimatedReadyAt estimates when each item and the whole order
+// will be ready from the learned prep time of each line for the chef of its
+// ticket, behind the queue of the order's main chef
+func (svc *Order) calculateEstimatedReadyAt(ctx *gofr.Context, o *model.Order) {
+	prepMinutes := svc.prepTimeSvc.Estimate(ctx, o.RestaurantID, o.Items, lineChefs(o.Tickets, o.AssignedChefID))
+
 	// Max prep time across all items (parallel prep)
 	maxPrepTime := 0
-	for _, item := range o.Items {
-		pt := defaultPrepTime
-		if t, ok := prepTimes[item.ProductID]; ok && t > 0 {
-			pt = t
-		}
+	for _, pt := range prepMinutes {
 		if pt > maxPrepTime {
 			maxPrepTime = pt
 		}
@@ -251,17 +702,65 @@ func (svc *Order) calculateEstimatedReadyAt(ctx *gofr.Context, o *model.Order) {
 		}
 	}
 
-	estimatedMinutes := maxPrepTime + (queueDepth * maxPrepTime)
-	readyAt := time.Now().Add(time.Duration(estimatedMinutes) * time.Minute)
+	// Each item is ready after the chef's queue plus its own prep time;
+	// the order is ready with its slowest item
+	now := time.Now()
+	queueDelay := queueDepth * maxPrepTime
+	for i := range o.Items {
+		pt, ok := prepMinutes[o.Items[i].LineID]
+		if !ok {
+			pt = defaultPrepTime
+		}
+
+		itemReadyAt := now.Add(time.Duration(queueDelay+pt) * time.Minute)
+		o.Items[i].EstimatedReadyAt = &itemReadyAt
+	}
+
+	estimatedMinutes := maxPrepTime + queueDelay
+	readyAt := now.Add(time.Duration(estimatedMinutes) * time.Minute)
 	o.EstimatedReadyAt = &readyAt
 }
 
+// estimateStarted re-estimates the given lines from the moment they started
+// cooking, using the learned prep time for the chef cooking them
+func (svc *Order) estimateStarted(ctx *gofr.Context, o *model.Order, items []model.OrderItem, lineIDs []int) {
+	tickets, err := svc.ticketStore.GetByOrder(ctx, o.RestaurantID, o.ID)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get tickets for order %d: %v", o.ID, err)
+	}
+
+	lines := make(map[int]bool, len(lineIDs))
+	for _, id := range lineIDs {
+		lines[id] = true
+	}
+
+	var started []model.OrderItem
+	for _, item := range items {
+		if lines[item.LineID] && item.StartedAt != nil {
+			started = append(started, item)
+		}
+	}
+
+	prepMinutes := svc.prepTimeSvc.Estimate(ctx, o.RestaurantID, started, lineChefs(tickets, o.AssignedChefID))
+	for i := range items {
+		pt, ok := prepMinutes[items[i].LineID]
+		if !ok {
+			continue
+		}
+
+		readyAt := items[i].StartedAt.Add(time.Duration(pt) * time.Minute)
+		items[i].EstimatedReadyAt = &readyAt
+	}
+}
+
 func isValidStatusTransition(from, to string) bool {
+	// awaiting_payment only leaves through MarkPaid or cancellation
 	transitions := map[string][]string{
-		"pending":   {"preparing", "cancelled"},
-		"preparing": {"completed", "cancelled"},
-		"completed": {},
-		"cancelled": {},
+		statusAwaitingPayment: {"cancelled"},
+		"pending":             {"preparing", "cancelled"},
+		"preparing":           {"completed", "cancelled"},
+		"completed":           {},
+		"cancelled":           {},
 	}
 
 	allowed, ok := transitions[from]
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"qr-dinein-backend/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Setting controlling how long after ordering customers may cancel; "0"
+// turns customer cancellation off
+const (
+	settingCustomerCancelWindow = "customer_cancel_window_minutes"
+	defaultCustomerCancelWindow = 5
+)
+
+var cancelReasons = map[string]bool{
+	model.CancelCustomerRequest: true,
+	model.CancelOutOfStock:      true,
+	model.CancelKitchenClosed:   true,
+	model.CancelDuplicate:       true,
+	model.CancelPaymentIssue:    true,
+	model.CancelOther:           true,
+}
+
+// Cancel cancels an order on behalf of the staff member or platform user in ctx
+func (svc *Order) Cancel(ctx *gofr.Context, restaurantID, id int, req *model.CancelRequest) (*model.Order, error) {
+	existing, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	c := &model.Cancellation{Reason: req.Reason, Note: strings.TrimSpace(req.Note)}
+	c.ActorType, c.ActorID, c.ActorName = actor(ctx)
+
+	return svc.cancel(ctx, existing, c)
+}
+
+// CancelByCustomer lets a customer who verified their phone number cancel
+// their own order before the kitchen starts on it, within the restaurant's
+// cancel window
+func (svc *Order) CancelByCustomer(ctx *gofr.Context, restaurantID, id int, req *model.CancelRequest) (*model.Order, error) {
+	if req.SessionToken == "" {
+		return nil, fmt.Errorf("please verify your phone number to cancel an order")
+	}
+
+	session, err := svc.customerSvc.GetSession(ctx, req.SessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired session: %w", err)
+	}
+
+	existing, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil || existing.CustomerMobile != session.PhoneNumber {
+		return nil, fmt.Errorf("order not found")
+	}
+
+	// Orders still waiting on payment have not reached the kitchen either
+	if existing.Status != "pending" && existing.Status != statusAwaitingPayment {
+		return nil, fmt.Errorf("the kitchen has already started on this order, please ask the staff to cancel it")
+	}
+
+	window := svc.customerCancelWindow(ctx, restaurantID)
+	if window == 0 {
+		return nil, fmt.Errorf("orders cannot be cancelled online, please ask the staff")
+	}
+
+	if time.Since(existing.CreatedAt) > window {
+		return nil, fmt.Errorf("orders can only be cancelled within %d minutes of being placed", int(window.Minutes()))
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = model.CancelCustomerRequest
+	}
+
+	c := &model.Cancellation{
+		Reason:    reason,
+		Note:      strings.TrimSpace(req.Note),
+		ActorType: "customer",
+		ActorName: session.PhoneNumber,
+	}
+
+	return svc.cancel(ctx, existing, c)
+}
+
+// cancel voids the order's unserved items, records the cancellation and
+// refunds the order when it was paid
+func (svc *Order) cancel(ctx *gofr.Context, existing *model.Order, c *model.Cancellation) (*model.Order, error) {
+	if !cancelReasons[c.Reason] {
+		return nil, fmt.Errorf("invalid cancel reason '%s'", c.Reason)
+	}
+
+	if c.Reason == model.CancelOther && c.Note == "" {
+		return nil, fmt.Errorf("a note is required when the cancel reason is 'other'")
+	}
+
+	if !isValidStatusTransition(existing.Status, "cancelled") {
+		return nil, fmt.Errorf("order cannot be cancelled once it is %s", existing.Status)
+	}
+
+	now := time.Now()
+	c.CancelledAt = now
+
+	items := make([]model.OrderItem, len(existing.Items))
+	copy(items, existing.Items)
+	applyOrderStatus(items, "cancelled", now)
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	cancellationJSON, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cancellation: %w", err)
+	}
+
+	// Only cancel the order as it was checked, so a payment captured
+	// meanwhile is not missed by the refund below
+	updated, ok, err := svc.store.UpdateFrom(ctx, existing.RestaurantID, existing.ID, existing.Status, existing.PaymentStatus,
+		[]string{"status = ?", "items = ?", "cancellation = ?"},
+		[]interface{}{"cancelled", string(itemsJSON), string(cancellationJSON)})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("the order was changed while it was being cancelled, please try again")
+	}
+
+	svc.syncTickets(ctx, updated)
+	svc.publishChanges(ctx, existing, updated)
+	svc.audit.Record(ctx, existing.RestaurantID, "order", existing.ID, AuditUpdate, existing, updated)
+	svc.notifyCustomer(ctx, existing, updated)
+
+	svc.refundCancelled(ctx, updated)
+
+	return updated, nil
+}
+
+// refundCancelled returns the payment of a cancelled order that was paid. A
+// failed refund is logged and left for an admin to retry through the refund
+// endpoint.
+func (svc *Order) refundCancelled(ctx *gofr.Context, o *model.Order) {
+	if svc.refundHook == nil || (o.PaymentStatus != model.PaymentPaid && o.PaymentStatus != model.PaymentPartiallyRefunded) {
+		return
+	}
+
+	reason := "order cancelled"
+	if o.Cancellation != nil {
+		reason += ": " + o.Cancellation.Reason
+	}
+
+	if _, err := svc.refundHook(ctx, o.RestaurantID, o.ID, &model.RefundRequest{Reason: reason}); err != nil {
+		ctx.Logger.Errorf("failed to refund cancelled order %d: %v", o.ID, err)
+		return
+	}
+
+	if refunded, err := svc.store.GetByID(ctx, o.RestaurantID, o.ID); err == nil {
+		o.PaymentStatus = refunded.PaymentStatus
+	}
+}
+
+func (svc *Order) customerCancelWindow(ctx *gofr.Context, restaurantID int) time.Duration {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingCustomerCancelWindow); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(setting.Value)); err == nil && n >= 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+
+	return defaultCustomerCancelWindow * time.Minute
+}
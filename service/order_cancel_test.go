@@ -0,0 +1,65 @@
+package service
+
+import (
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCancelRefusesAnOrderChangedMeanwhile(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder()}
+
+	existing := &model.Order{
+		ID: 21, RestaurantID: 1, Status: "pending", PaymentStatus: model.PaymentUnpaid, Total: 120,
+		Items: []model.OrderItem{{LineID: 1, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemQueued}},
+	}
+
+	// The payment was captured after the order was read
+	mock.ExpectExec("UPDATE orders SET status = ?, items = ?, cancellation = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND payment_status = ?").
+		WithArgs("cancelled", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 21, 1, "pending", model.PaymentUnpaid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := svc.cancel(ctx, existing, &model.Cancellation{Reason: model.CancelOutOfStock}); err == nil {
+		t.Fatal("cancelled an order that changed after it was read")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMarkPaidAppliesPaymentOnce(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder()}
+
+	order := &model.Order{
+		ID: 21, RestaurantID: 1, Status: "pending", PaymentStatus: model.PaymentUnpaid, Total: 120,
+		Items: []model.OrderItem{{LineID: 1, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemQueued}},
+	}
+
+	// Another delivery of the same payment marks the order paid first
+	expectOrder(t, mock, order)
+	mock.ExpectExec("UPDATE orders SET payment_status = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND payment_status = ?").
+		WithArgs(model.PaymentPaid, sqlmock.AnyArg(), 21, 1, "pending", model.PaymentUnpaid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	paid := *order
+	paid.PaymentStatus = model.PaymentPaid
+	expectOrder(t, mock, &paid)
+
+	updated, err := svc.MarkPaid(ctx, 1, 21)
+	if err != nil {
+		t.Fatalf("MarkPaid() = %v", err)
+	}
+
+	if updated.PaymentStatus != model.PaymentPaid {
+		t.Errorf("payment status %s, want %s", updated.PaymentStatus, model.PaymentPaid)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"qr-dinein-backend/event"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// RefundHook returns part of the captured payment of an order
+type RefundHook func(ctx *gofr.Context, restaurantID, orderID int, req *model.RefundRequest) (*model.Payment, error)
+
+// SetRefundHook sets how paid orders are refunded when items are voided or
+// the order is cancelled
+func (svc *Order) SetRefundHook(hook RefundHook) {
+	svc.refundHook = hook
+}
+
+// UpdateItemStatus moves a single order item through the kitchen and
+// re-derives the order status from the state of all its items.
+func (svc *Order) UpdateItemStatus(ctx *gofr.Context, restaurantID, orderID, lineID int, status string) (*model.Order, error) {
+	existing, err := svc.store.GetByID(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	switch existing.Status {
+	case statusAwaitingPayment:
+		return nil, fmt.Errorf("order is awaiting payment")
+	case "completed", "cancelled":
+		return nil, fmt.Errorf("order is already %s", existing.Status)
+	}
+
+	items := make([]model.OrderItem, len(existing.Items))
+	copy(items, existing.Items)
+
+	idx := -1
+	for i := range items {
+		if items[i].LineID == lineID {
+			idx = i
+			break
+		}
+	}
+
+	if idx < 0 {
+		return nil, fmt.Errorf("order item %d not found", lineID)
+	}
+
+	if !isValidItemTransition(items[idx].Status, status) {
+		return nil, fmt.Errorf("invalid item status transition from '%s' to '%s'", items[idx].Status, status)
+	}
+
+	now := time.Now()
+	setItemStatus(&items[idx], status, now)
+
+	// An order with nothing left is cancelled, which needs a reason and
+	// refunds the customer, so the last item cannot simply be voided
+	if status == model.ItemVoided && len(billableItems(items)) == 0 {
+		return nil, fmt.Errorf("this is the order's last item, cancel the order with a reason instead")
+	}
+
+	// A started item is re-estimated from the moment cooking began
+	if status == model.ItemCooking {
+		svc.estimateStarted(ctx, existing, items, []int{lineID})
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	setClauses := []string{"items = ?"}
+	args := []interface{}{string(itemsJSON)}
+
+	if derived := deriveOrderStatus(items); derived != existing.Status {
+		setClauses = append(setClauses, "status = ?")
+		args = append(args, derived)
+	}
+
+	if eta := latestItemETA(items); eta != nil {
+		setClauses = append(setClauses, "estimated_ready_at = ?")
+		args = append(args, *eta)
+	}
+
+	// Voided items are no longer charged
+	if status == model.ItemVoided {
+		bill, err := svc.buildBill(ctx, restaurantID, billableItems(items))
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute bill: %w", err)
+		}
+
+		billJSON, err := json.Marshal(bill)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bill: %w", err)
+		}
+
+		setClauses = append(setClauses, "bill = ?", "total = ?")
+		args = append(args, string(billJSON), bill.GrandTotal)
+
+		// What was already paid for the item goes back before it is
+		// taken off the bill
+		if err := svc.refundVoided(ctx, existing, &items[idx], bill.GrandTotal); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := svc.store.Update(ctx, restaurantID, orderID, setClauses, args)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.syncTickets(ctx, updated)
+	svc.prepTimeSvc.Record(ctx, restaurantID, orderID, existing.Items, updated.Items, lineChefs(updated.Tickets, updated.AssignedChefID))
+	svc.publishItem(ctx, updated, &items[idx])
+	svc.publishChanges(ctx, existing, updated)
+	svc.audit.Record(ctx, restaurantID, "order", orderID, AuditUpdate, existing, updated)
+	svc.notifyCustomer(ctx, existing, updated)
+
+	return updated, nil
+}
+
+// refundVoided refunds the difference a voided item makes to a paid order's
+// total. Paid orders cannot lose items without the refund being recorded.
+func (svc *Order) refundVoided(ctx *gofr.Context, o *model.Order, item *model.OrderItem, total float64) error {
+	if o.PaymentStatus != model.PaymentPaid && o.PaymentStatus != model.PaymentPartiallyRefunded {
+		return nil
+	}
+
+	amount := roundMoney(o.Total - total)
+	if amount <= 0 {
+		return nil
+	}
+
+	if svc.refundHook == nil {
+		return fmt.Errorf("items cannot be voided on a paid order without a refund")
+	}
+
+	if _, err := svc.refundHook(ctx, o.RestaurantID, o.ID, &model.RefundRequest{
+		Amount: amount,
+		Reason: "item voided: " + item.Name,
+	}); err != nil {
+		return fmt.Errorf("failed to refund the voided item, it was not voided: %w", err)
+	}
+
+	return nil
+}
+
+// publishItem pushes an item status change to subscribed screens
+func (svc *Order) publishItem(ctx *gofr.Context, o *model.Order, item *model.OrderItem) {
+	evt := &model.OrderEvent{
+		Type:             event.TypeItemStatusChanged,
+		RestaurantID:     o.RestaurantID,
+		OrderID:          o.ID,
+		Status:           o.Status,
+		AssignedChefID:   o.AssignedChefID,
+		ChefIDs:          ticketChefIDs(o.Tickets),
+		EstimatedReadyAt: o.EstimatedReadyAt,
+		Item:             item,
+	}
+
+	if err := svc.broker.Publish(ctx, ctx.Redis, evt); err != nil {
+		ctx.Logger.Errorf("failed to publish %s for order %d: %v", evt.Type, o.ID, err)
+	}
+}
+
+// applyOrderStatus carries a whole-order status change down to its items
+func applyOrderStatus(items []model.OrderItem, status string, now time.Time) {
+	for i := range items {
+		switch {
+		case status == "preparing" && items[i].Status == model.ItemQueued:
+			setItemStatus(&items[i], model.ItemCooking, now)
+		case status == "completed" && items[i].Status != model.ItemVoided && items[i].Status != model.ItemServed:
+			setItemStatus(&items[i], model.ItemServed, now)
+		case status == "cancelled" && items[i].Status != model.ItemServed:
+			setItemStatus(&items[i], model.ItemVoided, now)
+		}
+	}
+}
+
+func setItemStatus(item *model.OrderItem, status string, now time.Time) {
+	item.Status = status
+
+	switch status {
+	case model.ItemCooking:
+		item.StartedAt = &now
+	case model.ItemReady:
+		item.ReadyAt = &now
+	case model.ItemServed:
+		if item.ReadyAt == nil {
+			item.ReadyAt = &now
+		}
+		item.ServedAt = &now
+	case model.ItemVoided:
+		item.VoidedAt = &now
+	}
+}
+
+// deriveOrderStatus summarises item states: pending until the kitchen starts,
+// completed once everything is served, cancelled when every item is voided.
+func deriveOrderStatus(items []model.OrderItem) string {
+	active, queued, served := 0, 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case model.ItemVoided:
+			continue
+		case model.ItemQueued:
+			queued++
+		case model.ItemServed:
+			served++
+		}
+		active++
+	}
+
+	switch {
+	case active == 0:
+		return "cancelled"
+	case served == active:
+		return "completed"
+	case queued == active:
+		return "pending"
+	default:
+		return "preparing"
+	}
+}
+
+// latestItemETA is the order's ready time: the last estimate among items still being made
+func latestItemETA(items []model.OrderItem) *time.Time {
+	var latest *time.Time
+	for _, item := range items {
+		if item.Status != model.ItemQueued && item.Status != model.ItemCooking {
+			continue
+		}
+		if item.EstimatedReadyAt != nil && (latest == nil || item.EstimatedReadyAt.After(*latest)) {
+			latest = item.EstimatedReadyAt
+		}
+	}
+
+	return latest
+}
+
+func billableItems(items []model.OrderItem) []model.OrderItem {
+	billable := make([]model.OrderItem, 0, len(items))
+	for _, item := range items {
+		if item.Status != model.ItemVoided {
+			billable = append(billable, item)
+		}
+	}
+
+	return billable
+}
+
+// queuedLines lists the lines the kitchen has not started yet
+func queuedLines(items []model.OrderItem) []int {
+	var ids []int
+	for _, item := range items {
+		if item.Status == model.ItemQueued {
+			ids = append(ids, item.LineID)
+		}
+	}
+
+	return ids
+}
+
+func allItemsQueued(items []model.OrderItem) bool {
+	for _, item := range items {
+		if item.Status != model.ItemQueued {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidItemTransition(from, to string) bool {
+	transitions := map[string][]string{
+		model.ItemQueued:  {model.ItemCooking, model.ItemVoided},
+		model.ItemCooking: {model.ItemReady, model.ItemVoided},
+		model.ItemReady:   {model.ItemServed, model.ItemVoided},
+		model.ItemServed:  {},
+		model.ItemVoided:  {},
+	}
+
+	allowed, ok := transitions[from]
+	if !ok {
+		return false
+	}
+
+	for _, s := range allowed {
+		if s == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"testing"
+
+	"gofr.dev/pkg/gofr"
+)
+
+func TestVoidingLastItemIsRefused(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder()}
+
+	expectOrder(t, mock, &model.Order{
+		ID: 21, RestaurantID: 1, Status: "preparing", PaymentStatus: model.PaymentPaid, Total: 120,
+		Items: []model.OrderItem{
+			{LineID: 1, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemCooking},
+			{LineID: 2, Name: "Filter Coffee", Price: 40, Quantity: 1, Status: model.ItemVoided},
+		},
+	})
+
+	// Nothing is written: the order would be cancelled without a reason or refund
+	if _, err := svc.UpdateItemStatus(ctx, 1, 21, 1, model.ItemVoided); err == nil {
+		t.Fatal("voiding the last item was accepted")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRefundVoided(t *testing.T) {
+	item := &model.OrderItem{LineID: 2, Name: "Filter Coffee", Price: 40, Quantity: 1}
+
+	var refunds []model.RefundRequest
+	hook := func(_ *gofr.Context, _, _ int, req *model.RefundRequest) (*model.Payment, error) {
+		refunds = append(refunds, *req)
+		return &model.Payment{}, nil
+	}
+	failing := func(*gofr.Context, int, int, *model.RefundRequest) (*model.Payment, error) {
+		return nil, errors.New("gateway unavailable")
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		hook       RefundHook
+		wantErr    bool
+		wantRefund float64
+	}{
+		{"unpaid", model.PaymentUnpaid, nil, false, 0},
+		{"paid", model.PaymentPaid, hook, false, 40},
+		{"partially refunded", model.PaymentPartiallyRefunded, hook, false, 40},
+		{"paid without refunds", model.PaymentPaid, nil, true, 0},
+		{"refund fails", model.PaymentPaid, failing, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refunds = nil
+			svc := &Order{refundHook: tt.hook}
+			o := &model.Order{ID: 21, RestaurantID: 1, PaymentStatus: tt.status, Total: 160}
+
+			err := svc.refundVoided(nil, o, item, 120)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("refundVoided() = %v, want error %v", err, tt.wantErr)
+			}
+
+			var refunded float64
+			for _, r := range refunds {
+				refunded += r.Amount
+			}
+
+			if refunded != tt.wantRefund {
+				t.Errorf("refunded %.2f, want %.2f", refunded, tt.wantRefund)
+			}
+		})
+	}
+}
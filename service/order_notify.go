@@ -0,0 +1,109 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/notify"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Settings choosing which order updates are sent to customers; "false"
+// turns one off
+const (
+	settingNotifyOrderAccepted  = "notify_order_accepted"
+	settingNotifyOrderPreparing = "notify_order_preparing"
+	settingNotifyOrderReady     = "notify_order_ready"
+	settingNotifyOrderCancelled = "notify_order_cancelled"
+)
+
+// orderNotifications maps each customer-facing template to its setting
+var orderNotifications = map[string]string{
+	notify.TemplateOrderAccepted:  settingNotifyOrderAccepted,
+	notify.TemplateOrderPreparing: settingNotifyOrderPreparing,
+	notify.TemplateOrderReady:     settingNotifyOrderReady,
+	notify.TemplateOrderCancelled: settingNotifyOrderCancelled,
+}
+
+// notifyCustomer queues a message to the customer when an order update is
+// one they are told about: the order reaching the kitchen, being started,
+// being ready, or being cancelled. before is nil for a new order. Only
+// numbers verified by OTP are messaged, so a typo or someone else's number
+// on an order does not get texted.
+func (svc *Order) notifyCustomer(ctx *gofr.Context, before, after *model.Order) {
+	var template string
+	switch {
+	case after.Status == "cancelled":
+		if before != nil && before.Status != "cancelled" {
+			template = notify.TemplateOrderCancelled
+		}
+	case itemsReady(after.Items) && (before == nil || !itemsReady(before.Items)):
+		template = notify.TemplateOrderReady
+	case after.Status == "preparing" && (before == nil || before.Status != "preparing"):
+		template = notify.TemplateOrderPreparing
+	case after.Status == "pending" && (before == nil || before.Status != "pending"):
+		template = notify.TemplateOrderAccepted
+	}
+
+	if template == "" || after.CustomerID == nil {
+		return
+	}
+
+	if setting, err := svc.settingsStore.GetByKey(ctx, after.RestaurantID, orderNotifications[template]); err == nil && setting.Value == "false" {
+		return
+	}
+
+	// Updates go to the number the customer verified, which staff editing
+	// the order's mobile cannot change
+	customer, err := svc.customerSvc.GetByID(ctx, *after.CustomerID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			ctx.Logger.Errorf("failed to get customer of order %d: %v", after.ID, err)
+		}
+		return
+	}
+
+	if customer.NotificationsOptOut {
+		return
+	}
+
+	to := Recipient{Phone: customer.PhoneNumber, Email: customer.Email}
+
+	data := notify.Data{OrderID: after.ID}
+	if after.EstimatedReadyAt != nil {
+		if remaining := time.Until(*after.EstimatedReadyAt); remaining > 0 {
+			data.ETA = int(math.Ceil(remaining.Minutes()))
+		}
+	}
+
+	// Staff notes stay internal; customers only see the reason
+	if after.Cancellation != nil && after.Cancellation.Reason != model.CancelOther {
+		data.Reason = strings.ReplaceAll(after.Cancellation.Reason, "_", " ")
+	}
+
+	if err := svc.notifications.Queue(ctx, after.RestaurantID, &after.ID, to, template, data); err != nil {
+		ctx.Logger.Errorf("failed to queue %s notification for order %d: %v", template, after.ID, err)
+	}
+}
+
+// itemsReady reports whether every item still on the order has been
+// prepared
+func itemsReady(items []model.OrderItem) bool {
+	active := 0
+	for _, item := range items {
+		switch item.Status {
+		case model.ItemVoided:
+			continue
+		case model.ItemReady, model.ItemServed:
+			active++
+		default:
+			return false
+		}
+	}
+
+	return active > 0
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/notify"
+	"qr-dinein-backend/store"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const testCustomerByID = "SELECT c.id, c.phone_number, c.name, c.email, c.preferences, c.allergens, c.notifications_opt_out, c.created_at, c.updated_at FROM customers c WHERE c.id = ?"
+
+func newTestOrderNotify() *Order {
+	notifications := newTestNotification(notify.NewFake(notify.ChannelSMS))
+
+	return &Order{
+		store:         store.NewOrder(),
+		settingsStore: store.NewSettings(),
+		customerSvc:   &Customer{store: store.NewCustomer()},
+		notifications: notifications,
+	}
+}
+
+func readyOrder(customerID *int) *model.Order {
+	return &model.Order{
+		ID: 42, RestaurantID: testNotifyRestaurant, Status: "preparing", CustomerMobile: "+919876543210", CustomerID: customerID,
+		Items: []model.OrderItem{{LineID: 1, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemReady}},
+	}
+}
+
+func TestUnverifiedNumbersAreNotNotified(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := newTestOrderNotify()
+
+	before := readyOrder(nil)
+	before.Items[0].Status = model.ItemCooking
+
+	// Nothing is read or queued for a number typed in without an OTP
+	svc.notifyCustomer(ctx, before, readyOrder(nil))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestVerifiedNumbersAreNotified checks that updates go to the number the
+// customer verified, not one typed over it on the order
+func TestVerifiedNumbersAreNotified(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := newTestOrderNotify()
+
+	customerID := 9
+	before := readyOrder(&customerID)
+	before.Items[0].Status = model.ItemCooking
+
+	expectSetting(mock, settingNotifyOrderReady, "")
+	now := time.Now()
+	mock.ExpectQuery(testCustomerByID).
+		WithArgs(customerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phone_number", "name", "email", "preferences", "allergens", "notifications_opt_out", "created_at", "updated_at"}).
+			AddRow(customerID, "+919800000009", "", "", nil, nil, false, now, now))
+	expectSetting(mock, settingNotificationChannel, "")
+	expectRestaurantName(mock, "Saravana Bhavan")
+	expectSetting(mock, settingNotificationLanguage, "")
+	expectSetting(mock, settingNotificationSenderID, "")
+	mock.ExpectExec("INSERT INTO notification_outbox (restaurant_id, order_id, template, channel, recipient, sender, subject, body, status, next_attempt_at, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)").
+		WithArgs(testNotifyRestaurant, sqlmock.AnyArg(), notify.TemplateOrderReady, notify.ChannelSMS, "+919800000009", "",
+			"Order #42 is ready", "Saravana Bhavan: Your order #42 is ready!", model.NotificationPending,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	svc.notifyCustomer(ctx, before, readyOrder(&customerID))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
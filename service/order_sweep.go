@@ -0,0 +1,298 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/event"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/strategy"
+	"strconv"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Settings controlling the order sweeper
+const (
+	settingSweeperEnabled           = "sweeper_enabled"
+	settingSweeperUnassignedMinutes = "sweeper_unassigned_minutes"
+	settingSweeperOverdueMinutes    = "sweeper_overdue_minutes"
+)
+
+const (
+	defaultUnassignedMinutes = 5
+	defaultOverdueMinutes    = 10
+
+	// alertCooldown stops an unresolved ticket from alerting on every sweep
+	alertCooldown = 30 * time.Minute
+
+	// sweepLockTTL lets only one app instance sweep per minute
+	sweepLockKey = "order_sweeper_lock"
+	sweepLockTTL = 50 * time.Second
+)
+
+// Sweep runs as a cron job. For every active restaurant it looks for open
+// kitchen tickets that have had no chef for too long, are held by a chef who
+// is no longer active or on duty, or belong to a pending order that is
+// overdue and not yet started. It hands them to another chef through the
+// restaurant's assignment strategy and alerts admins either way.
+func (svc *Order) Sweep(ctx *gofr.Context) {
+	ok, err := ctx.Redis.SetNX(ctx, sweepLockKey, "1", sweepLockTTL).Result()
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to take the sweep lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	asSystem(ctx)
+
+	restaurants, err := svc.restaurantStore.GetAll(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to get restaurants: %v", err)
+		return
+	}
+
+	for _, r := range restaurants {
+		if !r.Active {
+			continue
+		}
+
+		if err := svc.sweepRestaurant(ctx, r.ID); err != nil {
+			ctx.Logger.Errorf("order sweep failed for restaurant %d: %v", r.ID, err)
+		}
+	}
+}
+
+func (svc *Order) sweepRestaurant(ctx *gofr.Context, restaurantID int) error {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingSweeperEnabled); err == nil && setting.Value == "false" {
+		return nil
+	}
+
+	tickets, err := svc.ticketStore.GetOpen(ctx, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to get open tickets: %w", err)
+	}
+
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	unassignedAfter := svc.settingMinutes(ctx, restaurantID, settingSweeperUnassignedMinutes, defaultUnassignedMinutes)
+	overdueAfter := svc.settingMinutes(ctx, restaurantID, settingSweeperOverdueMinutes, defaultOverdueMinutes)
+
+	available, err := svc.chefResolver.ChefPool(ctx, restaurantID).Candidates(ctx, strategy.AssignRequest{RestaurantID: restaurantID})
+	if err != nil {
+		return fmt.Errorf("failed to get available chefs: %w", err)
+	}
+
+	onDuty := make(map[int]bool, len(available))
+	for _, chef := range available {
+		onDuty[chef.ID] = true
+	}
+
+	now := time.Now()
+	orders := make(map[int]*model.Order)
+	reassigned := make(map[int]bool)
+	var assigner strategy.ChefAssigner
+
+	for i := range tickets {
+		t := &tickets[i]
+
+		o, ok := orders[t.OrderID]
+		if !ok {
+			o, err = svc.store.GetByID(ctx, restaurantID, t.OrderID)
+			if err != nil {
+				ctx.Logger.Errorf("order sweep: failed to get order %d: %v", t.OrderID, err)
+				continue
+			}
+			orders[t.OrderID] = o
+		}
+
+		if o.Status != "pending" && o.Status != "preparing" {
+			continue
+		}
+
+		reason := stuckReason(t, o, onDuty, now, unassignedAfter, overdueAfter)
+		if reason == "" {
+			continue
+		}
+
+		if assigner == nil {
+			assigner = svc.chefResolver.Resolve(ctx, restaurantID)
+		}
+
+		req := strategy.AssignRequest{RestaurantID: restaurantID, StationID: t.StationID, Items: t.Items}
+		if t.AssignedChefID != nil {
+			req.ExcludeChefs = []int{*t.AssignedChefID}
+		}
+
+		alert := &model.Alert{Reason: reason, TicketID: t.ID, StationID: t.StationID, FromChefID: t.AssignedChefID}
+
+		chefID, err := assigner.Assign(ctx, req)
+		if err != nil {
+			ctx.Logger.Errorf("order sweep: chef assignment failed for ticket %d: %v", t.ID, err)
+		} else if chefID != nil {
+			alert.ToChefID = svc.reassignTicket(ctx, t, chefID)
+		}
+
+		if alert.ToChefID != nil {
+			reassigned[o.ID] = true
+		} else if !svc.claimAlert(ctx, alert) {
+			continue
+		}
+
+		alert.Message = alertMessage(alert, unassignedAfter)
+		svc.publishAlert(ctx, o, alert)
+	}
+
+	for orderID := range reassigned {
+		svc.reassignOrder(ctx, orders[orderID])
+	}
+
+	return nil
+}
+
+// stuckReason says why a ticket needs a new chef, or "" when it is fine
+func stuckReason(t *model.Ticket, o *model.Order, onDuty map[int]bool, now time.Time, unassignedAfter, overdueAfter time.Duration) string {
+	switch {
+	case t.AssignedChefID == nil:
+		if now.Sub(t.CreatedAt) >= unassignedAfter {
+			return model.AlertUnassigned
+		}
+	case !onDuty[*t.AssignedChefID]:
+		return model.AlertOrphaned
+	case o.Status == "pending" && o.EstimatedReadyAt != nil && now.Sub(*o.EstimatedReadyAt) >= overdueAfter && allItemsQueued(t.Items):
+		return model.AlertOverdue
+	}
+
+	return ""
+}
+
+// reassignTicket moves the ticket to chefID, returning the new chef or nil
+// when the ticket could not be moved
+func (svc *Order) reassignTicket(ctx *gofr.Context, t *model.Ticket, chefID *int) *int {
+	ok, err := svc.ticketStore.SetChef(ctx, t.ID, chefID)
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to reassign ticket %d: %v", t.ID, err)
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+
+	before := *t
+	t.AssignedChefID = chefID
+	svc.audit.Record(ctx, t.RestaurantID, "ticket", t.ID, AuditUpdate, &before, t)
+
+	return chefID
+}
+
+// reassignOrder points the order at the chef now holding most of its items.
+// A pending order gets a fresh estimate so it is not immediately overdue again.
+func (svc *Order) reassignOrder(ctx *gofr.Context, o *model.Order) {
+	tickets, err := svc.ticketStore.GetByOrder(ctx, o.RestaurantID, o.ID)
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to get tickets for order %d: %v", o.ID, err)
+		return
+	}
+
+	planned := *o
+	planned.AssignedChefID = primaryChef(tickets)
+	planned.Tickets = tickets
+
+	setClauses := []string{"assigned_chef_id = ?"}
+	args := []interface{}{planned.AssignedChefID}
+
+	// Only the chef and estimate are written, and only while the order is
+	// as it was read, so items changed meanwhile are not put back
+	if o.Status == "pending" {
+		planned.Items = make([]model.OrderItem, len(o.Items))
+		copy(planned.Items, o.Items)
+		svc.calculateEstimatedReadyAt(ctx, &planned)
+
+		setClauses = append(setClauses, "estimated_ready_at = ?")
+		args = append(args, planned.EstimatedReadyAt)
+	}
+
+	updated, ok, err := svc.store.UpdateFrom(ctx, o.RestaurantID, o.ID, o.Status, o.PaymentStatus, setClauses, args)
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to update order %d: %v", o.ID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	updated.Tickets = tickets
+
+	svc.publishChanges(ctx, o, updated)
+
+	// Station chefs picking up a ticket need to hear about it even when the
+	// order's main chef stays the same
+	if equalIntPtr(o.AssignedChefID, updated.AssignedChefID) {
+		svc.publish(ctx, event.TypeChefAssigned, updated)
+	}
+
+	svc.audit.Record(ctx, o.RestaurantID, "order", o.ID, AuditUpdate, o, updated)
+}
+
+// claimAlert reports whether an unresolved alert should be sent now, so the
+// same ticket alerts at most once per cooldown
+func (svc *Order) claimAlert(ctx *gofr.Context, alert *model.Alert) bool {
+	key := "order_alert:" + strconv.Itoa(alert.TicketID) + ":" + alert.Reason
+
+	ok, err := ctx.Redis.SetNX(ctx, key, "1", alertCooldown).Result()
+	if err != nil {
+		ctx.Logger.Errorf("order sweep: failed to record alert for ticket %d: %v", alert.TicketID, err)
+		return true
+	}
+
+	return ok
+}
+
+func (svc *Order) publishAlert(ctx *gofr.Context, o *model.Order, alert *model.Alert) {
+	evt := &model.OrderEvent{
+		Type:             event.TypeOrderAlert,
+		RestaurantID:     o.RestaurantID,
+		OrderID:          o.ID,
+		Status:           o.Status,
+		AssignedChefID:   o.AssignedChefID,
+		EstimatedReadyAt: o.EstimatedReadyAt,
+		Alert:            alert,
+	}
+
+	if err := svc.broker.Publish(ctx, ctx.Redis, evt); err != nil {
+		ctx.Logger.Errorf("failed to publish %s for order %d: %v", evt.Type, o.ID, err)
+	}
+}
+
+func alertMessage(alert *model.Alert, unassignedAfter time.Duration) string {
+	var msg string
+	switch alert.Reason {
+	case model.AlertUnassigned:
+		msg = fmt.Sprintf("ticket has had no chef for over %d minutes", int(unassignedAfter.Minutes()))
+	case model.AlertOrphaned:
+		msg = fmt.Sprintf("chef %d is no longer available", *alert.FromChefID)
+	case model.AlertOverdue:
+		msg = "order is overdue and the kitchen has not started it"
+	}
+
+	if alert.ToChefID != nil {
+		return msg + fmt.Sprintf("; reassigned to chef %d", *alert.ToChefID)
+	}
+
+	return msg + "; no other chef is available"
+}
+
+// settingMinutes reads a positive number of minutes from a setting
+func (svc *Order) settingMinutes(ctx *gofr.Context, restaurantID int, key string, fallback int) time.Duration {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, key); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(setting.Value)); err == nil && n > 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+
+	return time.Duration(fallback) * time.Minute
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	testTabOpen  = "INSERT INTO table_tabs (restaurant_id, table_id, table_number, status, open_table, opened_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)"
+	testTabByID  = "SELECT id, restaurant_id, table_id, table_number, status, opened_at, closed_at FROM table_tabs WHERE id = ? AND restaurant_id = ?"
+	testOrderTab = "UPDATE orders SET tab_id = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND EXISTS (SELECT 1 FROM table_tabs WHERE id = ? AND status = ?)"
+)
+
+func expectTabOpen(mock sqlmock.Sqlmock, tabID int) {
+	mock.ExpectExec(testTabOpen).
+		WithArgs(1, 5, "12", model.TabOpen, "id:5", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(int64(tabID), 1))
+	mock.ExpectQuery(testTabByID).
+		WithArgs(tabID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "table_id", "table_number", "status", "opened_at", "closed_at"}).
+			AddRow(tabID, 1, 5, "12", model.TabOpen, time.Now(), nil))
+}
+
+func TestJoinTabSkipsTabClosedMeanwhile(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder(), tabStore: store.NewTab()}
+
+	tableID, tableNumber := 5, "12"
+	o := &model.Order{ID: 21, RestaurantID: 1, TableID: &tableID, TableNumber: &tableNumber}
+
+	// Tab 3 is closed between being found and the order joining it
+	expectTabOpen(mock, 3)
+	mock.ExpectExec(testOrderTab).
+		WithArgs(3, sqlmock.AnyArg(), 21, 1, 3, model.TabOpen).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	expectTabOpen(mock, 4)
+	mock.ExpectExec(testOrderTab).
+		WithArgs(4, sqlmock.AnyArg(), 21, 1, 4, model.TabOpen).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	svc.joinTab(ctx, o)
+
+	if o.TabID == nil || *o.TabID != 4 {
+		t.Errorf("order joined tab %v, want the new tab 4", o.TabID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateRefusesItemsOfPaidOrder(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder()}
+
+	expectOrder(t, mock, &model.Order{
+		ID: 21, RestaurantID: 1, Status: "pending", PaymentStatus: model.PaymentPaid, Total: 120,
+		Items: []model.OrderItem{{LineID: 1, ProductID: 3, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemQueued}},
+	})
+
+	_, err := svc.Update(ctx, 1, 21, &model.Order{Items: []model.OrderItem{{ProductID: 3, Quantity: 2}}})
+	if err == nil {
+		t.Fatal("items of a paid order were changed")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateConflictsWhenOrderMovedOn(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := &Order{store: store.NewOrder()}
+
+	expectOrder(t, mock, &model.Order{
+		ID: 21, RestaurantID: 1, Status: "preparing", PaymentStatus: model.PaymentPaid, Total: 120,
+		Items: []model.OrderItem{{LineID: 1, ProductID: 3, Name: "Masala Dosa", Price: 120, Quantity: 1, Status: model.ItemCooking}},
+	})
+	// The order was cancelled after it was read, so it is not completed
+	mock.ExpectExec("UPDATE orders SET status = ?, items = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND payment_status = ?").
+		WithArgs("completed", sqlmock.AnyArg(), sqlmock.AnyArg(), 21, 1, "preparing", model.PaymentPaid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := svc.Update(ctx, 1, 21, &model.Order{Status: "completed"}); err == nil {
+		t.Fatal("update applied over a change made meanwhile")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
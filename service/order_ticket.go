@@ -0,0 +1,195 @@
+package service
+
+import (
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/strategy"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// planTickets splits the order's items into one kitchen ticket per station,
+// plus a general ticket for items routed to no station, and assigns each
+// ticket a chef by running the restaurant's strategy over that station's
+// chefs. The order itself is assigned to the chef with the most items.
+func (svc *Order) planTickets(ctx *gofr.Context, o *model.Order) {
+	productIDs := make([]int, 0, len(o.Items))
+	seen := make(map[int]bool)
+	for _, item := range o.Items {
+		if !seen[item.ProductID] {
+			productIDs = append(productIDs, item.ProductID)
+			seen[item.ProductID] = true
+		}
+	}
+
+	routes, err := svc.productStore.GetStationRoutes(ctx, o.RestaurantID, productIDs)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get station routes: %v", err)
+		routes = map[int]int{}
+	}
+
+	var tickets []model.Ticket
+	positions := make(map[int]int) // station ID (0 for unrouted items) -> ticket index
+	for _, item := range o.Items {
+		stationID := routes[item.ProductID]
+
+		pos, ok := positions[stationID]
+		if !ok {
+			t := model.Ticket{RestaurantID: o.RestaurantID, Status: model.TicketOpen}
+			if stationID != 0 {
+				id := stationID
+				t.StationID = &id
+			}
+
+			tickets = append(tickets, t)
+			pos = len(tickets) - 1
+			positions[stationID] = pos
+		}
+
+		tickets[pos].LineIDs = append(tickets[pos].LineIDs, item.LineID)
+		tickets[pos].Items = append(tickets[pos].Items, item)
+	}
+
+	assigner := svc.chefResolver.Resolve(ctx, o.RestaurantID)
+	for i := range tickets {
+		req := strategy.AssignRequest{RestaurantID: o.RestaurantID, StationID: tickets[i].StationID, Items: tickets[i].Items}
+		chefID, err := assigner.Assign(ctx, req)
+		if err != nil {
+			ctx.Logger.Errorf("chef auto-assignment failed: %v", err)
+			continue
+		}
+
+		tickets[i].AssignedChefID = chefID
+	}
+
+	o.Tickets = tickets
+	o.AssignedChefID = primaryChef(tickets)
+}
+
+// PrintHook queues an order's newly created tickets for printing
+type PrintHook func(ctx *gofr.Context, o *model.Order)
+
+// SetPrintHook sets how new kitchen tickets are sent to the printers
+func (svc *Order) SetPrintHook(hook PrintHook) {
+	svc.printHook = hook
+}
+
+// saveTickets stores the tickets planned for a newly persisted order. A
+// failure leaves the order without tickets rather than failing it.
+func (svc *Order) saveTickets(ctx *gofr.Context, o *model.Order, tickets []model.Ticket) {
+	if len(tickets) == 0 {
+		return
+	}
+
+	saved, err := svc.ticketStore.CreateAll(ctx, o.ID, tickets)
+	if err != nil {
+		ctx.Logger.Errorf("failed to create tickets for order %d: %v", o.ID, err)
+		return
+	}
+
+	o.Tickets = saved
+
+	if svc.printHook != nil {
+		svc.printHook(ctx, o)
+	}
+}
+
+// syncTickets closes tickets whose items are all finished and attaches the
+// order's tickets with their current status
+func (svc *Order) syncTickets(ctx *gofr.Context, o *model.Order) {
+	tickets, err := svc.ticketStore.GetByOrder(ctx, o.RestaurantID, o.ID)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get tickets for order %d: %v", o.ID, err)
+		return
+	}
+
+	statuses := make(map[int]string, len(o.Items))
+	for _, item := range o.Items {
+		statuses[item.LineID] = item.Status
+	}
+
+	for i := range tickets {
+		status := ticketStatus(tickets[i].LineIDs, statuses)
+		if status == tickets[i].Status {
+			continue
+		}
+
+		if err := svc.ticketStore.UpdateStatus(ctx, tickets[i].ID, status); err != nil {
+			ctx.Logger.Errorf("failed to update ticket %d: %v", tickets[i].ID, err)
+			continue
+		}
+
+		tickets[i].Status = status
+	}
+
+	o.Tickets = tickets
+}
+
+// ticketStatus is done once every line is ready, served or voided, and
+// voided when every line was voided
+func ticketStatus(lineIDs []int, statuses map[int]string) string {
+	finished, voided := 0, 0
+	for _, id := range lineIDs {
+		switch statuses[id] {
+		case model.ItemVoided:
+			voided++
+			finished++
+		case model.ItemReady, model.ItemServed:
+			finished++
+		}
+	}
+
+	switch {
+	case voided == len(lineIDs):
+		return model.TicketVoided
+	case finished == len(lineIDs):
+		return model.TicketDone
+	default:
+		return model.TicketOpen
+	}
+}
+
+// primaryChef is the chef of the ticket with the most lines
+func primaryChef(tickets []model.Ticket) *int {
+	var chefID *int
+	most := 0
+	for _, t := range tickets {
+		if t.AssignedChefID != nil && len(t.LineIDs) > most {
+			chefID = t.AssignedChefID
+			most = len(t.LineIDs)
+		}
+	}
+
+	return chefID
+}
+
+// ticketChefIDs lists the chefs holding a ticket on the order
+func ticketChefIDs(tickets []model.Ticket) []int {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, t := range tickets {
+		if t.AssignedChefID != nil && !seen[*t.AssignedChefID] {
+			ids = append(ids, *t.AssignedChefID)
+			seen[*t.AssignedChefID] = true
+		}
+	}
+
+	return ids
+}
+
+// lineChefs maps each order line to the chef of its ticket, falling back to
+// the order's chef for lines on an unassigned ticket
+func lineChefs(tickets []model.Ticket, fallback *int) map[int]*int {
+	chefs := make(map[int]*int)
+	for _, t := range tickets {
+		chefID := t.AssignedChefID
+		if chefID == nil {
+			chefID = fallback
+		}
+
+		for _, id := range t.LineIDs {
+			chefs[id] = chefID
+		}
+	}
+
+	return chefs
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/payment"
+	"qr-dinein-backend/store"
+	"strconv"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Audit actions of payments
+const (
+	AuditRefund          = "refund"           // money returned to a customer
+	AuditPaymentMismatch = "payment_mismatch" // a capture that did not match its order's total
+)
+
+type Payment struct {
+	store           *store.Payment
+	orderStore      *store.Order
+	restaurantStore *store.Restaurant
+	orderSvc        *Order
+	provider        payment.Provider
+	audit           *Audit
+}
+
+func NewPayment(s *store.Payment, orderStore *store.Order, restaurantStore *store.Restaurant, orderSvc *Order, provider payment.Provider, audit *Audit) *Payment {
+	return &Payment{
+		store:           s,
+		orderStore:      orderStore,
+		restaurantStore: restaurantStore,
+		orderSvc:        orderSvc,
+		provider:        provider,
+		audit:           audit,
+	}
+}
+
+func (svc *Payment) GetByOrder(ctx *gofr.Context, restaurantID, orderID int) ([]model.Payment, error) {
+	return svc.store.GetByOrder(ctx, restaurantID, orderID)
+}
+
+// CreateIntent starts collecting the order total. An open intent for the same
+// amount is reused so retries from the client don't create duplicate charges.
+func (svc *Payment) CreateIntent(ctx *gofr.Context, restaurantID, orderID int) (*model.PaymentIntent, error) {
+	order, err := svc.orderStore.GetByID(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	if order.PaymentStatus != model.PaymentUnpaid {
+		return nil, fmt.Errorf("order is already %s", order.PaymentStatus)
+	}
+
+	if order.Status == "cancelled" {
+		return nil, fmt.Errorf("cannot pay for a cancelled order")
+	}
+
+	existing, err := svc.store.GetByOrder(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range existing {
+		if p.Status == model.PaymentRecordCreated && p.Provider == svc.provider.Name() && p.Amount == order.Total && p.ProviderOrderID != "" {
+			return &model.PaymentIntent{
+				PaymentID:       p.ID,
+				Provider:        p.Provider,
+				ProviderOrderID: p.ProviderOrderID,
+				ClientKey:       svc.provider.ClientKey(),
+				Amount:          p.Amount,
+				Currency:        p.Currency,
+			}, nil
+		}
+	}
+
+	restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("restaurant not found: %w", err)
+	}
+
+	// Record the attempt first so the gateway receipt can reference it
+	p, err := svc.store.Create(ctx, &model.Payment{
+		RestaurantID: restaurantID,
+		OrderID:      orderID,
+		Provider:     svc.provider.Name(),
+		Amount:       order.Total,
+		Currency:     restaurant.Currency,
+		Status:       model.PaymentRecordCreated,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	intent, err := svc.provider.CreateIntent(ctx, payment.IntentRequest{
+		Reference: "payment_" + strconv.Itoa(p.ID),
+		Amount:    payment.ToMinorUnits(order.Total),
+		Currency:  restaurant.Currency,
+	})
+	if err != nil {
+		if _, uerr := svc.store.UpdateStatus(ctx, p.ID, model.PaymentRecordCreated, model.PaymentRecordFailed, ""); uerr != nil {
+			ctx.Logger.Errorf("failed to mark payment %d failed: %v", p.ID, uerr)
+		}
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	if err := svc.store.SetProviderOrderID(ctx, p.ID, intent.ProviderOrderID); err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	return &model.PaymentIntent{
+		PaymentID:       p.ID,
+		Provider:        p.Provider,
+		ProviderOrderID: intent.ProviderOrderID,
+		ClientKey:       svc.provider.ClientKey(),
+		Amount:          order.Total,
+		Currency:        restaurant.Currency,
+	}, nil
+}
+
+// HandleWebhook applies a signed gateway notification. Replayed notifications
+// are acknowledged without being applied twice.
+func (svc *Payment) HandleWebhook(ctx *gofr.Context, payload []byte, signature string) error {
+	evt, err := svc.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	if evt.Type != payment.EventCaptured && evt.Type != payment.EventFailed {
+		return nil
+	}
+
+	p, err := svc.store.GetByProviderOrderID(ctx, svc.provider.Name(), evt.ProviderOrderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Logger.Errorf("webhook for unknown %s order %s", svc.provider.Name(), evt.ProviderOrderID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if evt.Type == payment.EventFailed {
+		_, err := svc.store.UpdateStatus(ctx, p.ID, model.PaymentRecordCreated, model.PaymentRecordFailed, evt.ProviderPaymentID)
+		return err
+	}
+
+	order, err := svc.orderStore.GetByID(ctx, p.RestaurantID, p.OrderID)
+	if err != nil {
+		return fmt.Errorf("order %d of payment %d not found: %w", p.OrderID, p.ID, err)
+	}
+
+	// Record what the gateway actually took. A failed attempt can still be
+	// captured later by the gateway.
+	amount := payment.FromMinorUnits(evt.Amount)
+	captured, err := svc.store.Capture(ctx, p.ID, model.PaymentRecordCreated, evt.ProviderPaymentID, amount)
+	if err == nil && !captured {
+		captured, err = svc.store.Capture(ctx, p.ID, model.PaymentRecordFailed, evt.ProviderPaymentID, amount)
+	}
+	if err != nil {
+		return err
+	}
+	if !captured {
+		return nil
+	}
+
+	// The order's total is what it owes, whatever the intent was opened for
+	if evt.Amount != payment.ToMinorUnits(order.Total) {
+		p.Status = model.PaymentRecordCaptured
+		p.ProviderPaymentID = evt.ProviderPaymentID
+		p.Amount = amount
+		svc.refundMismatch(ctx, p, order)
+
+		return nil
+	}
+
+	if _, err := svc.orderSvc.MarkPaid(ctx, p.RestaurantID, p.OrderID); err != nil {
+		return fmt.Errorf("failed to mark order %d paid: %w", p.OrderID, err)
+	}
+
+	return nil
+}
+
+// refundMismatch hands back a capture that does not match the order total,
+// leaving the order unpaid so the customer can pay what it now owes. The
+// mismatch is audited either way; a refund the gateway refuses is left for
+// staff to make from the payment.
+func (svc *Payment) refundMismatch(ctx *gofr.Context, p *model.Payment, order *model.Order) {
+	ctx.Logger.Errorf("payment %d captured %.2f but order %d totals %.2f, refunding it", p.ID, p.Amount, order.ID, order.Total)
+
+	refunded := svc.refundCapture(ctx, p, "amount mismatch")
+
+	svc.audit.Record(ctx, p.RestaurantID, "payment", p.ID, AuditPaymentMismatch, nil, map[string]interface{}{
+		"payment":    p,
+		"orderTotal": order.Total,
+		"refunded":   refunded,
+	})
+}
+
+// refundCapture returns the whole of a capture through the gateway and
+// reports whether it was refunded
+func (svc *Payment) refundCapture(ctx *gofr.Context, p *model.Payment, reason string) bool {
+	reserved, err := svc.store.AddRefund(ctx, p.ID, p.Amount)
+	if err != nil || !reserved {
+		ctx.Logger.Errorf("failed to reserve refund of payment %d, refund it manually: %v", p.ID, err)
+		return false
+	}
+
+	if _, err := svc.provider.Refund(ctx, payment.RefundRequest{
+		ProviderPaymentID: p.ProviderPaymentID,
+		Amount:            payment.ToMinorUnits(p.Amount),
+		Reference:         reason,
+	}); err != nil {
+		if _, rerr := svc.store.AddRefund(ctx, p.ID, -p.Amount); rerr != nil {
+			ctx.Logger.Errorf("failed to release refund reservation on payment %d: %v", p.ID, rerr)
+		}
+		ctx.Logger.Errorf("refund of payment %d failed, refund it manually: %v", p.ID, err)
+
+		return false
+	}
+
+	p.RefundedAmount = p.Amount
+
+	return true
+}
+
+var settleMethods = map[string]bool{
+	model.SettleCash: true,
+	model.SettleCard: true,
+	model.SettleUPI:  true,
+}
+
+// Settle records an unpaid order as paid at the counter. The order is marked
+// paid before the payment is recorded, so settling twice records it once.
+func (svc *Payment) Settle(ctx *gofr.Context, restaurantID, orderID int, req *model.SettleRequest) (*model.Payment, error) {
+	if !settleMethods[req.Method] {
+		return nil, fmt.Errorf("settlement method must be '%s', '%s' or '%s'", model.SettleCash, model.SettleCard, model.SettleUPI)
+	}
+
+	restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("restaurant not found: %w", err)
+	}
+
+	for attempt := 0; attempt < maxMarkPaidAttempts; attempt++ {
+		order, err := svc.orderStore.GetByID(ctx, restaurantID, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("order not found: %w", err)
+		}
+
+		if order.PaymentStatus != model.PaymentUnpaid {
+			return nil, fmt.Errorf("order is already %s", order.PaymentStatus)
+		}
+
+		if order.Status == "cancelled" {
+			return nil, fmt.Errorf("cannot settle a cancelled order")
+		}
+
+		_, ok, err := svc.orderSvc.markPaid(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		p, err := svc.store.Create(ctx, &model.Payment{
+			RestaurantID:      restaurantID,
+			OrderID:           orderID,
+			Provider:          req.Method,
+			ProviderPaymentID: strings.TrimSpace(req.Reference),
+			Amount:            order.Total,
+			Currency:          restaurant.Currency,
+			Status:            model.PaymentRecordCaptured,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("order %d was marked paid but its settlement was not recorded: %w", orderID, err)
+		}
+
+		svc.audit.Record(ctx, restaurantID, "payment", p.ID, AuditCreate, nil, p)
+
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("order %d kept changing while it was being settled", orderID)
+}
+
+// Refund returns part or all of the captured payment for an order
+func (svc *Payment) Refund(ctx *gofr.Context, restaurantID, orderID int, req *model.RefundRequest) (*model.Payment, error) {
+	payments, err := svc.store.GetByOrder(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var captured *model.Payment
+	for i := range payments {
+		if payments[i].Status == model.PaymentRecordCaptured {
+			captured = &payments[i]
+			break
+		}
+	}
+
+	if captured == nil {
+		return nil, fmt.Errorf("order has no captured payment")
+	}
+
+	remaining := roundMoney(captured.Amount - captured.RefundedAmount)
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+
+	if amount <= 0 || amount > remaining {
+		return nil, fmt.Errorf("refund amount must be between 0 and %.2f", remaining)
+	}
+
+	// Reserve the amount before calling the gateway so concurrent refunds can't exceed the capture
+	ok, err := svc.store.AddRefund(ctx, captured.ID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("refund exceeds the captured amount")
+	}
+
+	// Counter settlements are handed back at the counter; only gateway
+	// captures are refunded through the gateway
+	if !settleMethods[captured.Provider] {
+		if _, err := svc.provider.Refund(ctx, payment.RefundRequest{
+			ProviderPaymentID: captured.ProviderPaymentID,
+			Amount:            payment.ToMinorUnits(amount),
+			Reference:         req.Reason,
+		}); err != nil {
+			if _, rerr := svc.store.AddRefund(ctx, captured.ID, -amount); rerr != nil {
+				ctx.Logger.Errorf("failed to release refund reservation on payment %d: %v", captured.ID, rerr)
+			}
+			return nil, fmt.Errorf("refund failed: %w", err)
+		}
+	}
+
+	before := *captured
+
+	// Concurrent refunds each reserved their own amount; the status follows
+	// the total refunded once they are all in
+	if captured, err = svc.store.GetByID(ctx, before.ID); err != nil {
+		return nil, fmt.Errorf("failed to get refunded payment: %w", err)
+	}
+
+	status := model.PaymentPartiallyRefunded
+	if captured.RefundedAmount >= captured.Amount {
+		status = model.PaymentRefunded
+	}
+
+	if err := svc.orderSvc.SetPaymentStatus(ctx, restaurantID, orderID, status); err != nil {
+		return nil, fmt.Errorf("failed to update order payment status: %w", err)
+	}
+
+	svc.audit.Record(ctx, restaurantID, "payment", captured.ID, AuditRefund, &before, map[string]interface{}{
+		"payment": captured,
+		"amount":  amount,
+		"reason":  req.Reason,
+	})
+
+	return captured, nil
+}
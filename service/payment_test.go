@@ -0,0 +1,235 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/payment"
+	"qr-dinein-backend/store"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	testPaymentByProviderOrder = "SELECT id, restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at FROM payments WHERE provider = ? AND provider_order_id = ?"
+	testPaymentByID            = "SELECT id, restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at FROM payments WHERE id = ?"
+	testPaymentsByOrder        = "SELECT id, restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at FROM payments WHERE restaurant_id = ? AND order_id = ? ORDER BY created_at DESC, id DESC"
+	testPaymentUpdateStatus    = "UPDATE payments SET status = ?, provider_payment_id = ?, updated_at = ? WHERE id = ? AND status = ?"
+	testPaymentCapture         = "UPDATE payments SET status = ?, provider_payment_id = ?, amount = ?, updated_at = ? WHERE id = ? AND status = ?"
+	testPaymentAddRefund       = "UPDATE payments SET refunded_amount = refunded_amount + ?, updated_at = ? WHERE id = ? AND status = ? AND refunded_amount + ? <= amount"
+	testAuditInsert            = "INSERT INTO audit_log (restaurant_id, actor_type, actor_id, actor_name, resource, resource_id, action, before_data, after_data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+)
+
+func newTestPayment(t *testing.T) (*Payment, *payment.Fake, *gofr.Context, sqlmock.Sqlmock) {
+	t.Helper()
+
+	ctx, mock := newTestContext(t)
+
+	provider := payment.NewFake("test-webhook-secret")
+	svc := NewPayment(store.NewPayment(), store.NewOrder(), store.NewRestaurant(), &Order{store: store.NewOrder()}, provider, NewAudit(store.NewAudit()))
+
+	return svc, provider, ctx, mock
+}
+
+func signedWebhook(t *testing.T, provider *payment.Fake, evt payment.WebhookEvent) ([]byte, string) {
+	t.Helper()
+
+	payload, signature, err := provider.SignWebhook(evt)
+	if err != nil {
+		t.Fatalf("sign webhook: %v", err)
+	}
+
+	return payload, signature
+}
+
+// expectPayment returns the payment opened for an order through the fake gateway
+func expectPayment(mock sqlmock.Sqlmock, amount float64, status string) {
+	mock.ExpectQuery(testPaymentByProviderOrder).
+		WithArgs("fake", "fake_order_1").
+		WillReturnRows(paymentRows(amount, 0, status))
+}
+
+// paymentRows returns the fake gateway payment of order 21 as the database would
+func paymentRows(amount, refunded float64, status string) *sqlmock.Rows {
+	now := time.Now()
+
+	return sqlmock.NewRows([]string{"id", "restaurant_id", "order_id", "provider", "provider_order_id", "provider_payment_id", "amount", "refunded_amount", "currency", "status", "created_at", "updated_at"}).
+		AddRow(11, 1, 21, "fake", "fake_order_1", "pay_1", amount, refunded, "INR", status, now, now)
+}
+
+// expectPaymentOrder expects the order the payment is for, now totalling total
+func expectPaymentOrder(t *testing.T, mock sqlmock.Sqlmock, total float64) {
+	expectOrder(t, mock, &model.Order{
+		ID: 21, RestaurantID: 1, CustomerMobile: "+919800000000", Items: []model.OrderItem{},
+		Status: statusAwaitingPayment, PaymentStatus: model.PaymentUnpaid, Total: total,
+	})
+}
+
+func TestHandleWebhookRejectsBadSignature(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	payload, _ := signedWebhook(t, provider, payment.WebhookEvent{Type: payment.EventCaptured, ProviderOrderID: "fake_order_1", Amount: 50000})
+	forged := payment.NewFake("guessed-secret")
+	_, signature := signedWebhook(t, forged, payment.WebhookEvent{Type: payment.EventCaptured, ProviderOrderID: "fake_order_1", Amount: 50000})
+
+	if err := svc.HandleWebhook(ctx, payload, signature); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("HandleWebhook() = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestHandleWebhookRefundsAmountMismatch checks that a capture of the wrong
+// amount is recorded as taken, refunded and left for staff to see, while the
+// order stays unpaid
+func TestHandleWebhookRefundsAmountMismatch(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	// The intent was opened for 500.00 but the order now totals 450.00
+	expectPayment(mock, 500, model.PaymentRecordCreated)
+	expectPaymentOrder(t, mock, 450)
+	mock.ExpectExec(testPaymentCapture).
+		WithArgs(model.PaymentRecordCaptured, "pay_1", 500.0, sqlmock.AnyArg(), 11, model.PaymentRecordCreated).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(testPaymentAddRefund).
+		WithArgs(500.0, sqlmock.AnyArg(), 11, model.PaymentRecordCaptured, 500.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(testAuditInsert).
+		WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "payment", "11", AuditPaymentMismatch,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	payload, signature := signedWebhook(t, provider, payment.WebhookEvent{
+		Type: payment.EventCaptured, ProviderOrderID: "fake_order_1", ProviderPaymentID: "pay_1", Amount: 50000,
+	})
+
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() = %v", err)
+	}
+
+	if len(provider.Refunds) != 1 || provider.Refunds[0].ProviderPaymentID != "pay_1" || provider.Refunds[0].Amount != 50000 {
+		t.Errorf("got refunds %+v, want the whole 500.00 of pay_1", provider.Refunds)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandleWebhookReplayIsNotAppliedTwice(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	// The first delivery already moved the payment to captured
+	expectPayment(mock, 450, model.PaymentRecordCaptured)
+	expectPaymentOrder(t, mock, 450)
+	mock.ExpectExec(testPaymentCapture).
+		WithArgs(model.PaymentRecordCaptured, "pay_1", 450.0, sqlmock.AnyArg(), 11, model.PaymentRecordCreated).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(testPaymentCapture).
+		WithArgs(model.PaymentRecordCaptured, "pay_1", 450.0, sqlmock.AnyArg(), 11, model.PaymentRecordFailed).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	payload, signature := signedWebhook(t, provider, payment.WebhookEvent{
+		Type: payment.EventCaptured, ProviderOrderID: "fake_order_1", ProviderPaymentID: "pay_1", Amount: 45000,
+	})
+
+	// Acknowledged, and the order is not marked paid again
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandleWebhookIgnoresUnknownOrders(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	mock.ExpectQuery(testPaymentByProviderOrder).
+		WithArgs("fake", "fake_order_1").
+		WillReturnError(sql.ErrNoRows)
+
+	payload, signature := signedWebhook(t, provider, payment.WebhookEvent{
+		Type: payment.EventCaptured, ProviderOrderID: "fake_order_1", Amount: 45000,
+	})
+
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandleWebhookRecordsFailure(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	expectPayment(mock, 450, model.PaymentRecordCreated)
+	mock.ExpectExec(testPaymentUpdateStatus).
+		WithArgs(model.PaymentRecordFailed, "pay_1", sqlmock.AnyArg(), 11, model.PaymentRecordCreated).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, signature := signedWebhook(t, provider, payment.WebhookEvent{
+		Type: payment.EventFailed, ProviderOrderID: "fake_order_1", ProviderPaymentID: "pay_1",
+	})
+
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestRefundStatusFollowsTotalRefunded checks that a partial refund which
+// completes the refunds made alongside it marks the order fully refunded
+func TestRefundStatusFollowsTotalRefunded(t *testing.T) {
+	svc, provider, ctx, mock := newTestPayment(t)
+
+	mock.ExpectQuery(testPaymentsByOrder).
+		WithArgs(1, 21).
+		WillReturnRows(paymentRows(500, 0, model.PaymentRecordCaptured))
+	mock.ExpectExec(testPaymentAddRefund).
+		WithArgs(100.0, sqlmock.AnyArg(), 11, model.PaymentRecordCaptured, 100.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Another refund of 400.00 landed after the payment was first read
+	mock.ExpectQuery(testPaymentByID).
+		WithArgs(11).
+		WillReturnRows(paymentRows(500, 500, model.PaymentRecordCaptured))
+	mock.ExpectExec("UPDATE orders SET payment_status = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?").
+		WithArgs(model.PaymentRefunded, sqlmock.AnyArg(), 21, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectOrder(t, mock, &model.Order{
+		ID: 21, RestaurantID: 1, Items: []model.OrderItem{},
+		Status: "served", PaymentStatus: model.PaymentRefunded, Total: 500,
+	})
+	mock.ExpectExec(testAuditInsert).
+		WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "payment", "11", AuditRefund,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	p, err := svc.Refund(ctx, 1, 21, &model.RefundRequest{Amount: 100, Reason: "cold food"})
+	if err != nil {
+		t.Fatalf("Refund() = %v", err)
+	}
+
+	if p.RefundedAmount != 500 {
+		t.Errorf("got refunded amount %.2f, want 500.00", p.RefundedAmount)
+	}
+
+	if len(provider.Refunds) != 1 || provider.Refunds[0].Amount != 10000 {
+		t.Errorf("got refunds %+v, want one of 100.00", provider.Refunds)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const totpIssuer = "QR Dine-In"
+
+type PlatformUser struct {
+	store       *store.PlatformUser
+	revocations *auth.Revocations
+}
+
+func NewPlatformUser(s *store.PlatformUser, revocations *auth.Revocations) *PlatformUser {
+	return &PlatformUser{store: s, revocations: revocations}
+}
+
+func (svc *PlatformUser) GetAll(ctx *gofr.Context) ([]model.PlatformUser, error) {
+	return svc.store.GetAll(ctx)
+}
+
+func (svc *PlatformUser) GetByID(ctx *gofr.Context, id int) (*model.PlatformUser, error) {
+	return svc.store.GetByID(ctx, id)
+}
+
+func (svc *PlatformUser) Create(ctx *gofr.Context, u *model.PlatformUser) (*model.PlatformUser, error) {
+	if strings.TrimSpace(u.Username) == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	hash, err := auth.HashPassword(u.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	u.PasswordHash = hash
+	u.Active = true
+
+	return svc.store.Create(ctx, u)
+}
+
+func (svc *PlatformUser) Update(ctx *gofr.Context, callerID, id int, u *model.PlatformUser) (*model.PlatformUser, error) {
+	if _, err := svc.store.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("platform user not found: %w", err)
+	}
+
+	if strings.TrimSpace(u.Username) == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	if id == callerID && !u.Active {
+		return nil, fmt.Errorf("cannot deactivate your own account")
+	}
+
+	if u.Password != "" {
+		hash, err := auth.HashPassword(u.Password)
+		if err != nil {
+			return nil, err
+		}
+		u.PasswordHash = hash
+	}
+
+	// Deactivation or a password change ends every existing session
+	revoke := !u.Active || u.Password != ""
+
+	result, err := svc.store.Update(ctx, id, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoke {
+		if err := svc.revocations.RevokePlatformUser(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (svc *PlatformUser) Delete(ctx *gofr.Context, callerID, id int) error {
+	if id == callerID {
+		return fmt.Errorf("cannot delete your own account")
+	}
+
+	if _, err := svc.store.GetByID(ctx, id); err != nil {
+		return fmt.Errorf("platform user not found: %w", err)
+	}
+
+	if err := svc.store.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return svc.revocations.RevokePlatformUser(ctx, id)
+}
+
+// SetupTOTP generates a new secret for the caller's own account. It is not
+// enforced at login until confirmed through VerifyTOTP.
+func (svc *PlatformUser) SetupTOTP(ctx *gofr.Context, callerID, id int) (*model.TOTPSetupResponse, error) {
+	if id != callerID {
+		return nil, fmt.Errorf("you can only enrol two-factor authentication for your own account")
+	}
+
+	u, err := svc.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("platform user not found: %w", err)
+	}
+
+	if u.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.store.SetTOTP(ctx, id, secret, false); err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPSetupResponse{
+		Secret: secret,
+		URI:    auth.TOTPURI(totpIssuer, u.Username, secret),
+	}, nil
+}
+
+// VerifyTOTP confirms enrolment with a code from the authenticator app
+func (svc *PlatformUser) VerifyTOTP(ctx *gofr.Context, callerID, id int, code string) (*model.PlatformUser, error) {
+	if id != callerID {
+		return nil, fmt.Errorf("you can only enrol two-factor authentication for your own account")
+	}
+
+	u, err := svc.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("platform user not found: %w", err)
+	}
+
+	if u.TOTPSecret == "" {
+		return nil, fmt.Errorf("two-factor setup has not been started")
+	}
+
+	if !auth.ValidateTOTP(u.TOTPSecret, code, time.Now()) {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	if err := svc.store.SetTOTP(ctx, id, u.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+
+	u.TOTPEnabled = true
+
+	return u, nil
+}
+
+// DisableTOTP removes two-factor authentication, e.g. for a lost device
+func (svc *PlatformUser) DisableTOTP(ctx *gofr.Context, id int) error {
+	if _, err := svc.store.GetByID(ctx, id); err != nil {
+		return fmt.Errorf("platform user not found: %w", err)
+	}
+
+	return svc.store.SetTOTP(ctx, id, "", false)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"sort"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	// prepWindow is how many recent samples the rolling statistics cover
+	prepWindow = 50
+
+	// priorWeight is how many samples the prior counts for, so the first few
+	// measurements of a product or chef only nudge its estimate
+	priorWeight = 5
+
+	maxReportRange = 93 * 24 * time.Hour
+)
+
+// PrepTime learns how long products take to prepare from the time items
+// actually spend cooking, per product and per chef, and estimates prep
+// times from it. A product's static prep time is only the starting prior.
+type PrepTime struct {
+	store        *store.PrepTime
+	productStore *store.Product
+}
+
+func NewPrepTime(s *store.PrepTime, productStore *store.Product) *PrepTime {
+	return &PrepTime{store: s, productStore: productStore}
+}
+
+// Estimate returns the expected prep minutes of each item, keyed by line ID.
+// The static prep time is blended with the median of the product's recent
+// samples, and that with the median of the chef cooking the line; each
+// median is weighted by the number of samples behind it.
+func (svc *PrepTime) Estimate(ctx *gofr.Context, restaurantID int, items []model.OrderItem, chefs map[int]*int) map[int]int {
+	productIDs := make([]int, 0, len(items))
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			productIDs = append(productIDs, item.ProductID)
+			seen[item.ProductID] = true
+		}
+	}
+
+	prepTimes, err := svc.productStore.GetPrepTimes(ctx, restaurantID, productIDs)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get prep times: %v", err)
+	}
+
+	stats, err := svc.store.GetStats(ctx, restaurantID, productIDs)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get prep time statistics: %v", err)
+	}
+
+	overall := make(map[int]model.PrepStat)
+	byChef := make(map[[2]int]model.PrepStat)
+	for _, st := range stats {
+		if st.ChefID == 0 {
+			overall[st.ProductID] = st
+		} else {
+			byChef[[2]int{st.ProductID, st.ChefID}] = st
+		}
+	}
+
+	minutes := make(map[int]int, len(items))
+	for _, item := range items {
+		prior := defaultPrepTime
+		if t, ok := prepTimes[item.ProductID]; ok && t > 0 {
+			prior = t
+		}
+
+		seconds := blend(float64(prior*60), overall[item.ProductID])
+		if chefID := chefs[item.LineID]; chefID != nil {
+			seconds = blend(seconds, byChef[[2]int{item.ProductID, *chefID}])
+		}
+
+		minutes[item.LineID] = int(math.Ceil(seconds / 60))
+	}
+
+	return minutes
+}
+
+// Record stores a sample for every item that became ready between before and
+// after, then refreshes the statistics of its product. Failures are logged
+// and never fail the status change that triggered them.
+func (svc *PrepTime) Record(ctx *gofr.Context, restaurantID, orderID int, before, after []model.OrderItem, chefs map[int]*int) {
+	wasReady := make(map[int]bool, len(before))
+	for _, item := range before {
+		wasReady[item.LineID] = item.ReadyAt != nil
+	}
+
+	for _, item := range after {
+		if item.ReadyAt == nil || item.StartedAt == nil || wasReady[item.LineID] {
+			continue
+		}
+
+		actual := int(item.ReadyAt.Sub(*item.StartedAt).Seconds())
+		if actual <= 0 {
+			continue
+		}
+
+		sample := &model.PrepSample{
+			RestaurantID:  restaurantID,
+			OrderID:       orderID,
+			LineID:        item.LineID,
+			ProductID:     item.ProductID,
+			ChefID:        chefs[item.LineID],
+			Quantity:      item.Quantity,
+			ActualSeconds: actual,
+		}
+
+		if item.EstimatedReadyAt != nil && item.EstimatedReadyAt.After(*item.StartedAt) {
+			sample.EstimatedSeconds = int(item.EstimatedReadyAt.Sub(*item.StartedAt).Seconds())
+		}
+
+		if err := svc.store.AddSample(ctx, sample); err != nil {
+			ctx.Logger.Errorf("failed to record prep time for order %d line %d: %v", orderID, item.LineID, err)
+			continue
+		}
+
+		svc.refreshStats(ctx, restaurantID, item.ProductID, nil)
+		if sample.ChefID != nil {
+			svc.refreshStats(ctx, restaurantID, item.ProductID, sample.ChefID)
+		}
+	}
+}
+
+// Report compares estimated with actual prep times for items that became
+// ready between from and to (inclusive dates), overall and per product
+func (svc *PrepTime) Report(ctx *gofr.Context, restaurantID int, from, to time.Time) (*model.ETAReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("from date must not be after to date")
+	}
+
+	end := to.AddDate(0, 0, 1)
+	if end.Sub(from) > maxReportRange {
+		return nil, fmt.Errorf("report range cannot exceed 93 days")
+	}
+
+	samples, err := svc.store.GetSamples(ctx, restaurantID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := svc.productStore.GetAll(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue := make(map[int]model.Product, len(products))
+	for _, p := range products {
+		catalogue[p.ID] = p
+	}
+
+	var total etaTally
+	byProduct := make(map[int]*etaTally)
+	for _, s := range samples {
+		total.add(s)
+
+		t, ok := byProduct[s.ProductID]
+		if !ok {
+			t = &etaTally{}
+			byProduct[s.ProductID] = t
+		}
+		t.add(s)
+	}
+
+	report := &model.ETAReport{
+		From:     from.Format(timesheetDateFmt),
+		To:       to.Format(timesheetDateFmt),
+		Samples:  len(samples),
+		Products: make([]model.ETAProductReport, 0, len(byProduct)),
+	}
+	report.MeanEstimatedMinutes, report.MeanActualMinutes, report.MeanAbsErrorMinutes, report.OnTimePercent = total.summary()
+
+	for productID, t := range byProduct {
+		sort.Ints(t.actual)
+
+		row := model.ETAProductReport{
+			ProductID:     productID,
+			Name:          catalogue[productID].Name,
+			PrepTime:      catalogue[productID].PrepTime,
+			Samples:       len(t.actual),
+			MedianMinutes: toMinutes(percentile(t.actual, 50)),
+			P90Minutes:    toMinutes(percentile(t.actual, 90)),
+		}
+		row.MeanEstimatedMinutes, row.MeanActualMinutes, row.MeanAbsErrorMinutes, row.OnTimePercent = t.summary()
+
+		report.Products = append(report.Products, row)
+	}
+
+	sort.Slice(report.Products, func(i, j int) bool {
+		if report.Products[i].Samples != report.Products[j].Samples {
+			return report.Products[i].Samples > report.Products[j].Samples
+		}
+		return report.Products[i].Name < report.Products[j].Name
+	})
+
+	return report, nil
+}
+
+// refreshStats recomputes the rolling median and p90 of a product, across
+// all chefs or for one chef
+func (svc *PrepTime) refreshStats(ctx *gofr.Context, restaurantID, productID int, chefID *int) {
+	durations, err := svc.store.GetRecentDurations(ctx, restaurantID, productID, chefID, prepWindow)
+	if err != nil {
+		ctx.Logger.Errorf("failed to get prep times for product %d: %v", productID, err)
+		return
+	}
+
+	if len(durations) == 0 {
+		return
+	}
+
+	sort.Ints(durations)
+
+	st := model.PrepStat{
+		ProductID:     productID,
+		Samples:       len(durations),
+		MedianSeconds: int(math.Round(percentile(durations, 50))),
+		P90Seconds:    int(math.Round(percentile(durations, 90))),
+	}
+	if chefID != nil {
+		st.ChefID = *chefID
+	}
+
+	if err := svc.store.SaveStat(ctx, restaurantID, st); err != nil {
+		ctx.Logger.Errorf("failed to save prep time statistics for product %d: %v", productID, err)
+	}
+}
+
+// blend weighs the prior against the median of st by the samples behind it
+func blend(prior float64, st model.PrepStat) float64 {
+	if st.Samples == 0 {
+		return prior
+	}
+
+	return (prior*priorWeight + float64(st.MedianSeconds)*float64(st.Samples)) / float64(priorWeight+st.Samples)
+}
+
+// percentile interpolates the p-th percentile of sorted values
+func percentile(sorted []int, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return float64(sorted[lower]) + (rank-float64(lower))*float64(sorted[upper]-sorted[lower])
+}
+
+func toMinutes(seconds float64) float64 {
+	return math.Round(seconds/60*10) / 10
+}
+
+// etaTally accumulates samples for the report. Estimates and errors only
+// count samples that had an estimate.
+type etaTally struct {
+	actual    []int
+	estimated int
+	absError  int
+	withETA   int
+	onTime    int
+}
+
+func (t *etaTally) add(s model.PrepSample) {
+	t.actual = append(t.actual, s.ActualSeconds)
+
+	if s.EstimatedSeconds == 0 {
+		return
+	}
+
+	t.withETA++
+	t.estimated += s.EstimatedSeconds
+
+	diff := s.ActualSeconds - s.EstimatedSeconds
+	if diff <= 0 {
+		t.onTime++
+		diff = -diff
+	}
+	t.absError += diff
+}
+
+// summary returns the mean estimated, actual and absolute error minutes and
+// the percentage of items ready within their estimate
+func (t *etaTally) summary() (float64, float64, float64, float64) {
+	if len(t.actual) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	actual := 0
+	for _, a := range t.actual {
+		actual += a
+	}
+	meanActual := toMinutes(float64(actual) / float64(len(t.actual)))
+
+	if t.withETA == 0 {
+		return 0, meanActual, 0, 0
+	}
+
+	n := float64(t.withETA)
+	onTime := math.Round(float64(t.onTime)/n*1000) / 10
+
+	return toMinutes(float64(t.estimated) / n), meanActual, toMinutes(float64(t.absError) / n), onTime
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/receipt"
+	"qr-dinein-backend/store"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+)
+
+// Settings controlling automatic kitchen printing
+const (
+	settingAutoPrint         = "auto_print_kitchen_tickets" // "false" turns it off
+	settingPrinterPaperWidth = "printer_paper_width"        // 58 or 80
+	defaultPrinterPaperWidth = 80
+)
+
+const (
+	printMaxAttempts  = 5
+	printRetryBase    = 15 * time.Second
+	printRetryMax     = 10 * time.Minute
+	printClaimTimeout = 2 * time.Minute // an agent must report back on a job within this
+
+	maxClaimBatch     = 10
+	maxClaimWait      = 30 * time.Second
+	claimPollInterval = 5 * time.Second // catches retries coming due while an agent waits
+	notifyCheckEvery  = 250 * time.Millisecond
+	notifyTTL         = time.Minute
+	maxPrintJobList   = 200
+
+	keyPrefixPrintNotify = "print_jobs_notify:"
+)
+
+// Print queues printouts for the print agents on restaurants' networks,
+// which the backend cannot reach directly. Agents long-poll for jobs,
+// print them and report back; failed prints are retried with backoff.
+type Print struct {
+	store         *store.Print
+	orderStore    *store.Order
+	settingsStore *store.Settings
+	receiptSvc    *Receipt
+	audit         *Audit
+}
+
+func NewPrint(s *store.Print, orderStore *store.Order, settingsStore *store.Settings, receiptSvc *Receipt, audit *Audit) *Print {
+	return &Print{store: s, orderStore: orderStore, settingsStore: settingsStore, receiptSvc: receiptSvc, audit: audit}
+}
+
+// QueueTickets queues a kitchen ticket for each station ticket of the order.
+// It runs whenever an order's tickets are created, which is when the order
+// reaches the kitchen or its items are routed to stations again. Nothing is
+// queued while the restaurant has no print agent.
+func (svc *Print) QueueTickets(ctx *gofr.Context, o *model.Order) {
+	if setting, err := svc.settingsStore.GetByKey(ctx, o.RestaurantID, settingAutoPrint); err == nil && setting.Value == "false" {
+		return
+	}
+
+	hasAgent, err := svc.store.HasActiveAgent(ctx, o.RestaurantID)
+	if err != nil {
+		ctx.Logger.Errorf("failed to check print agents for restaurant %d: %v", o.RestaurantID, err)
+		return
+	}
+
+	if !hasAgent {
+		return
+	}
+
+	width := svc.paperWidth(ctx, o.RestaurantID)
+	for _, t := range o.Tickets {
+		// Items routed to no station are printed as station 0
+		stationID := 0
+		if t.StationID != nil {
+			stationID = *t.StationID
+		}
+
+		job := &model.PrintJob{
+			RestaurantID: o.RestaurantID,
+			OrderID:      o.ID,
+			StationID:    &stationID,
+			Kind:         model.PrintKitchen,
+			Format:       model.FormatESCPOS,
+			Width:        width,
+		}
+
+		if err := svc.store.CreateJob(ctx, job); err != nil {
+			ctx.Logger.Errorf("failed to queue kitchen ticket for order %d: %v", o.ID, err)
+		}
+	}
+
+	svc.notify(ctx, o.RestaurantID)
+}
+
+// Queue queues a printout of an order on staff request, such as a customer
+// receipt or a reprinted kitchen ticket. ESC/POS on the restaurant's paper
+// width is the default.
+func (svc *Print) Queue(ctx *gofr.Context, restaurantID int, job *model.PrintJob) (*model.PrintJob, error) {
+	if _, err := svc.orderStore.GetByID(ctx, restaurantID, job.OrderID); err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	if job.Kind == "" {
+		job.Kind = model.PrintReceipt
+	}
+	if job.Format == "" {
+		job.Format = model.FormatESCPOS
+	}
+	if job.Width == 0 {
+		job.Width = svc.paperWidth(ctx, restaurantID)
+	}
+
+	if job.Kind != model.PrintReceipt && job.Kind != model.PrintKitchen {
+		return nil, fmt.Errorf("kind must be '%s' or '%s'", model.PrintReceipt, model.PrintKitchen)
+	}
+
+	switch job.Format {
+	case model.FormatESCPOS, model.FormatPDF, model.FormatHTML:
+	default:
+		return nil, fmt.Errorf("format must be '%s', '%s' or '%s'", model.FormatESCPOS, model.FormatPDF, model.FormatHTML)
+	}
+
+	if _, err := receipt.PaperFor(job.Width); err != nil {
+		return nil, err
+	}
+
+	job.RestaurantID = restaurantID
+	if err := svc.store.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	svc.notify(ctx, restaurantID)
+
+	return job, nil
+}
+
+func (svc *Print) GetJobs(ctx *gofr.Context, restaurantID int, status string) ([]model.PrintJob, error) {
+	switch status {
+	case "", model.PrintPending, model.PrintClaimed, model.PrintPrinted, model.PrintFailed:
+	default:
+		return nil, fmt.Errorf("invalid print job status '%s'", status)
+	}
+
+	return svc.store.GetJobs(ctx, restaurantID, status, maxPrintJobList)
+}
+
+// Retry puts a failed job back in the queue with a fresh set of attempts
+func (svc *Print) Retry(ctx *gofr.Context, restaurantID, id int) (*model.PrintJob, error) {
+	requeued, err := svc.store.Requeue(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !requeued {
+		return nil, fmt.Errorf("only failed print jobs can be retried")
+	}
+
+	svc.notify(ctx, restaurantID)
+
+	return svc.store.GetJob(ctx, restaurantID, id)
+}
+
+// Claim hands the calling agent up to limit jobs with their rendered
+// content. When none are due it waits up to wait for one to be queued, so
+// agents can long-poll without hammering the backend.
+func (svc *Print) Claim(ctx *gofr.Context, key string, limit int, wait time.Duration) ([]model.PrintJob, error) {
+	agent, err := svc.authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > maxClaimBatch {
+		limit = maxClaimBatch
+	}
+	wait = max(0, min(wait, maxClaimWait))
+
+	if err := svc.store.TouchAgent(ctx, agent.ID, time.Now()); err != nil {
+		ctx.Logger.Errorf("failed to update print agent %d: %v", agent.ID, err)
+	}
+
+	notifyKey := keyPrefixPrintNotify + strconv.Itoa(agent.RestaurantID)
+	deadline := time.Now().Add(wait)
+	for {
+		// Read the counter before claiming so a job queued in between is
+		// not missed
+		seen, err := printNotifications(ctx, ctx.Redis, notifyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait for print jobs: %w", err)
+		}
+
+		jobs, err := svc.claim(ctx, agent, limit)
+		if err != nil || len(jobs) > 0 {
+			return jobs, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return jobs, nil
+		}
+
+		// Claim again as soon as a job is queued for the restaurant
+		if err := waitForPrintJobs(ctx, ctx.Redis, notifyKey, seen, min(remaining, claimPollInterval)); err != nil {
+			return nil, fmt.Errorf("failed to wait for print jobs: %w", err)
+		}
+	}
+}
+
+func (svc *Print) claim(ctx *gofr.Context, agent *model.PrintAgent, limit int) ([]model.PrintJob, error) {
+	staleBefore := time.Now().Add(-printClaimTimeout)
+
+	// Claims whose agent went quiet count as failed attempts
+	if err := svc.store.ExpireClaims(ctx, agent.RestaurantID, staleBefore, printMaxAttempts); err != nil {
+		return nil, fmt.Errorf("failed to expire print jobs: %w", err)
+	}
+
+	jobs, err := svc.store.ClaimJobs(ctx, agent.RestaurantID, agent.ID, uuid.NewString(), staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim print jobs: %w", err)
+	}
+
+	claimed := make([]model.PrintJob, 0, len(jobs))
+	for _, job := range jobs {
+		req := &model.PrintRequest{Kind: job.Kind, Format: job.Format, Width: job.Width, StationID: job.StationID}
+
+		job.Content, job.ContentType, err = svc.receiptSvc.Render(ctx, agent.RestaurantID, job.OrderID, req)
+		if err != nil {
+			// A job that cannot be rendered will not render on retry either
+			if _, ferr := svc.store.FinishAttempt(ctx, agent.RestaurantID, job.ID, agent.ID, model.PrintFailed, err.Error(), time.Now(), nil); ferr != nil {
+				ctx.Logger.Errorf("failed to fail print job %d: %v", job.ID, ferr)
+			}
+			continue
+		}
+
+		claimed = append(claimed, job)
+	}
+
+	return claimed, nil
+}
+
+// Ack records whether the agent printed a job it claimed. Failed prints are
+// retried with exponential backoff until they run out of attempts.
+func (svc *Print) Ack(ctx *gofr.Context, key string, id int, ack *model.PrintAck) (*model.PrintJob, error) {
+	agent, err := svc.authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := svc.store.GetJob(ctx, agent.RestaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("print job not found: %w", err)
+	}
+
+	if job.Status != model.PrintClaimed || job.AgentID == nil || *job.AgentID != agent.ID {
+		return nil, fmt.Errorf("print job is not claimed by this agent")
+	}
+
+	now := time.Now()
+	status, next, lastError := model.PrintPrinted, now, ""
+	var printedAt *time.Time
+
+	switch ack.Status {
+	case model.PrintPrinted:
+		printedAt = &now
+
+	case model.PrintFailed:
+		lastError = strings.TrimSpace(ack.Error)
+		if lastError == "" {
+			lastError = "print failed"
+		}
+
+		status = model.PrintPending
+		next = now.Add(printBackoff(job.Attempts))
+		if job.Attempts >= printMaxAttempts {
+			status = model.PrintFailed
+		}
+
+	default:
+		return nil, fmt.Errorf("status must be '%s' or '%s'", model.PrintPrinted, model.PrintFailed)
+	}
+
+	finished, err := svc.store.FinishAttempt(ctx, agent.RestaurantID, id, agent.ID, status, lastError, next, printedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !finished {
+		return nil, fmt.Errorf("print job is not claimed by this agent")
+	}
+
+	return svc.store.GetJob(ctx, agent.RestaurantID, id)
+}
+
+// printBackoff doubles the wait after each failed attempt, up to printRetryMax
+func printBackoff(attempts int) time.Duration {
+	backoff := printRetryBase
+	for i := 1; i < attempts && backoff < printRetryMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, printRetryMax)
+}
+
+func (svc *Print) GetAgents(ctx *gofr.Context, restaurantID int) ([]model.PrintAgent, error) {
+	return svc.store.GetAgents(ctx, restaurantID)
+}
+
+// RegisterAgent adds a print agent and returns it with its key. The key is
+// shown only here; a lost key means registering the agent again.
+func (svc *Print) RegisterAgent(ctx *gofr.Context, restaurantID int, a *model.PrintAgent) (*model.PrintAgent, error) {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		return nil, fmt.Errorf("agent name is required")
+	}
+
+	key, err := auth.NewAgentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	a.RestaurantID = restaurantID
+	if err := svc.store.CreateAgent(ctx, a, auth.HashAgentKey(key)); err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "print_agent", a.ID, AuditCreate, nil, a)
+
+	a.Key = key
+
+	return a, nil
+}
+
+func (svc *Print) DeleteAgent(ctx *gofr.Context, restaurantID, id int) error {
+	agents, err := svc.store.GetAgents(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	var before *model.PrintAgent
+	for i := range agents {
+		if agents[i].ID == id {
+			before = &agents[i]
+		}
+	}
+
+	if before == nil {
+		return fmt.Errorf("print agent not found")
+	}
+
+	if _, err := svc.store.DeleteAgent(ctx, restaurantID, id); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "print_agent", id, AuditDelete, before, nil)
+
+	return nil
+}
+
+func (svc *Print) authenticate(ctx *gofr.Context, key string) (*model.PrintAgent, error) {
+	if key == "" {
+		return nil, fmt.Errorf("missing %s header", auth.AgentKeyHeader)
+	}
+
+	agent, err := svc.store.GetAgentByKeyHash(ctx, auth.HashAgentKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid print agent key")
+	}
+
+	if !agent.Active {
+		return nil, fmt.Errorf("print agent is disabled")
+	}
+
+	return agent, nil
+}
+
+// notify wakes every agent waiting in Claim by bumping the restaurant's
+// notification counter. The counter only needs to outlive the agents' poll
+// interval.
+func (svc *Print) notify(ctx *gofr.Context, restaurantID int) {
+	if err := notifyPrintAgents(ctx, ctx.Redis, keyPrefixPrintNotify+strconv.Itoa(restaurantID)); err != nil {
+		ctx.Logger.Errorf("failed to notify print agents: %v", err)
+	}
+}
+
+func notifyPrintAgents(ctx context.Context, rdb redis.Cmdable, key string) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, notifyTTL)
+		return nil
+	})
+
+	return err
+}
+
+// printNotifications reads the notification counter, 0 when it has expired
+func printNotifications(ctx context.Context, rdb redis.Cmdable, key string) (int64, error) {
+	n, err := rdb.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return n, err
+}
+
+// waitForPrintJobs returns once the notification counter moves on from seen
+// or wait has passed. Every waiting agent sees the change, unlike a list
+// where each push wakes a single waiter.
+func waitForPrintJobs(ctx context.Context, rdb redis.Cmdable, key string, seen int64, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(notifyCheckEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-ticker.C:
+			n, err := printNotifications(ctx, rdb, key)
+			if err != nil {
+				return err
+			}
+
+			if n != seen {
+				return nil
+			}
+		}
+	}
+}
+
+func (svc *Print) paperWidth(ctx *gofr.Context, restaurantID int) int {
+	if setting, err := svc.settingsStore.GetByKey(ctx, restaurantID, settingPrinterPaperWidth); err == nil {
+		if width, err := strconv.Atoi(strings.TrimSpace(setting.Value)); err == nil {
+			if _, err := receipt.PaperFor(width); err == nil {
+				return width
+			}
+		}
+	}
+
+	return defaultPrinterPaperWidth
+}
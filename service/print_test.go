@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNotifyWakesEveryWaitingAgent(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+	key := keyPrefixPrintNotify + "1"
+
+	seen, err := printNotifications(ctx, rdb, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const agents = 3
+	woken := make(chan time.Duration, agents)
+	for i := 0; i < agents; i++ {
+		go func() {
+			start := time.Now()
+			if err := waitForPrintJobs(ctx, rdb, key, seen, 10*time.Second); err != nil {
+				t.Error(err)
+			}
+			woken <- time.Since(start)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := notifyPrintAgents(ctx, rdb, key); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < agents; i++ {
+		select {
+		case waited := <-woken:
+			if waited >= claimPollInterval {
+				t.Errorf("agent waited %v, want it woken by the notification", waited)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d agents were woken", i, agents)
+		}
+	}
+}
+
+func TestNotificationsDoNotPileUp(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+	key := keyPrefixPrintNotify + "1"
+
+	for i := 0; i < 5; i++ {
+		if err := notifyPrintAgents(ctx, rdb, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ttl := rdb.TTL(ctx, key).Val(); ttl <= 0 || ttl > notifyTTL {
+		t.Errorf("got TTL %v, want the key to expire within %v", ttl, notifyTTL)
+	}
+
+	// An agent arriving after the pushes waits for the next one rather than
+	// returning straight away for each stale notification
+	seen, err := printNotifications(ctx, rdb, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := waitForPrintJobs(ctx, rdb, key, seen, 600*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if waited := time.Since(start); waited < 500*time.Millisecond {
+		t.Errorf("agent returned after %v without a new job being queued", waited)
+	}
+}
+
+func TestWaitForPrintJobsStopsWithTheRequest(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := waitForPrintJobs(ctx, rdb, keyPrefixPrintNotify+"1", 0, 10*time.Second); err == nil {
+		t.Error("wait outlived the request")
+	}
+}
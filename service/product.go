@@ -12,11 +12,13 @@ import (
 )
 
 type Product struct {
-	store *store.Product
+	store            *store.Product
+	optionGroupStore *store.OptionGroup
+	audit            *Audit
 }
 
-func NewProduct(s *store.Product) *Product {
-	return &Product{store: s}
+func NewProduct(s *store.Product, optionGroupStore *store.OptionGroup, audit *Audit) *Product {
+	return &Product{store: s, optionGroupStore: optionGroupStore, audit: audit}
 }
 
 func (svc *Product) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Product, error) {
@@ -35,6 +37,13 @@ func (svc *Product) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Product
 		return nil, err
 	}
 
+	// Options are part of the cached menu
+	groups, err := svc.optionGroupStore.GetByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	attachOptionGroups(products, groups)
+
 	if data, err := json.Marshal(products); err == nil {
 		ctx.Redis.Set(ctx, cacheKey, string(data), 0)
 	}
@@ -43,11 +52,42 @@ func (svc *Product) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Product
 }
 
 func (svc *Product) GetByCategory(ctx *gofr.Context, restaurantID, categoryID int) ([]model.Product, error) {
-	return svc.store.GetByCategory(ctx, restaurantID, categoryID)
+	products, err := svc.store.GetByCategory(ctx, restaurantID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]int, 0, len(products))
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	groups, err := svc.optionGroupStore.GetByProductIDs(ctx, restaurantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	attachOptionGroups(products, groups)
+
+	return products, nil
 }
 
 func (svc *Product) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Product, error) {
-	return svc.store.GetByID(ctx, restaurantID, id)
+	p, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := svc.optionGroupStore.GetByProductIDs(ctx, restaurantID, []int{id})
+	if err != nil {
+		return nil, err
+	}
+
+	p.OptionGroups = groups[id]
+	if p.OptionGroups == nil {
+		p.OptionGroups = []model.OptionGroup{}
+	}
+
+	return p, nil
 }
 
 func (svc *Product) Create(ctx *gofr.Context, restaurantID int, p *model.Product) (*model.Product, error) {
@@ -64,38 +104,91 @@ func (svc *Product) Create(ctx *gofr.Context, restaurantID int, p *model.Product
 	}
 
 	p.RestaurantID = restaurantID
+	p.SkillTags = normalizeTags(p.SkillTags)
 
 	result, err := svc.store.Create(ctx, p)
 	if err != nil {
 		return nil, err
 	}
 
+	// Option groups are added separately once the product exists
+	result.OptionGroups = []model.OptionGroup{}
+
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "product", result.ID, AuditCreate, nil, result)
 
 	return result, nil
 }
 
 func (svc *Product) Update(ctx *gofr.Context, restaurantID, id int, p *model.Product) (*model.Product, error) {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	p.SkillTags = normalizeTags(p.SkillTags)
+
 	result, err := svc.store.Update(ctx, restaurantID, id, p)
 	if err != nil {
 		return nil, err
 	}
 
+	groups, err := svc.optionGroupStore.GetByProductIDs(ctx, restaurantID, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	result.OptionGroups = groups[id]
+	if result.OptionGroups == nil {
+		result.OptionGroups = []model.OptionGroup{}
+	}
+
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "product", id, AuditUpdate, before, result)
 
 	return result, nil
 }
 
 func (svc *Product) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return fmt.Errorf("product not found: %w", err)
+	}
+
 	if err := svc.store.Delete(ctx, restaurantID, id); err != nil {
 		return err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "product", id, AuditDelete, before, nil)
 
 	return nil
 }
 
+// normalizeTags lower-cases and trims skill tags and drops blanks and duplicates
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
+
+func attachOptionGroups(products []model.Product, groups map[int][]model.OptionGroup) {
+	for i := range products {
+		products[i].OptionGroups = groups[products[i].ID]
+		if products[i].OptionGroups == nil {
+			products[i].OptionGroups = []model.OptionGroup{}
+		}
+	}
+}
+
 func (svc *Product) invalidateCache(ctx *gofr.Context, restaurantID int) {
 	cacheKey := "products:" + strconv.Itoa(restaurantID)
 	ctx.Redis.Del(ctx, cacheKey)
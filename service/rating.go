@@ -25,6 +25,10 @@ func (svc *RatingService) GetAllByRestaurant(ctx *gofr.Context, restaurantID int
 	return svc.store.GetAllByRestaurant(ctx, restaurantID)
 }
 
+func (svc *RatingService) GetSummary(ctx *gofr.Context, restaurantID int) (*model.RatingSummary, error) {
+	return svc.store.GetSummary(ctx, restaurantID)
+}
+
 func (svc *RatingService) Create(ctx *gofr.Context, restaurantID, orderID int, r *model.Rating) (*model.Rating, error) {
 	// Validate rating value
 	if r.Rating < 1 || r.Rating > 5 {
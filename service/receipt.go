@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // logo formats
+	_ "image/jpeg" // logo formats
+	_ "image/png"  // logo formats
+	"io"
+	"net/http"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/receipt"
+	"qr-dinein-backend/store"
+	"strings"
+	"sync"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const maxLogoBytes = 2 << 20
+
+// Receipt renders orders as customer receipts and kitchen tickets for
+// thermal printers, PDF and HTML
+type Receipt struct {
+	orderSvc        *Order
+	restaurantStore *store.Restaurant
+	stationStore    *store.Station
+	client          *http.Client
+
+	// logos caches decoded restaurant logos by URL
+	logos sync.Map
+}
+
+func NewReceipt(orderSvc *Order, restaurantStore *store.Restaurant, stationStore *store.Station) *Receipt {
+	return &Receipt{
+		orderSvc:        orderSvc,
+		restaurantStore: restaurantStore,
+		stationStore:    stationStore,
+		client:          &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Render returns the order rendered as req asks, with its content type.
+// Receipts are the default kind, HTML the default format and 80mm the
+// default paper width.
+func (svc *Receipt) Render(ctx *gofr.Context, restaurantID, id int, req *model.PrintRequest) ([]byte, string, error) {
+	if req.Kind == "" {
+		req.Kind = model.PrintReceipt
+	}
+	if req.Format == "" {
+		req.Format = model.FormatHTML
+	}
+	if req.Width == 0 {
+		req.Width = 80
+	}
+
+	if req.Kind != model.PrintReceipt && req.Kind != model.PrintKitchen {
+		return nil, "", fmt.Errorf("kind must be '%s' or '%s'", model.PrintReceipt, model.PrintKitchen)
+	}
+
+	paper, err := receipt.PaperFor(req.Width)
+	if err != nil {
+		return nil, "", err
+	}
+
+	o, err := svc.orderSvc.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("order not found: %w", err)
+	}
+
+	restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, "", fmt.Errorf("restaurant not found: %w", err)
+	}
+
+	var doc *receipt.Document
+	if req.Kind == model.PrintKitchen {
+		stations, err := svc.stationStore.GetAll(ctx, restaurantID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get stations: %w", err)
+		}
+
+		names := make(map[int]string, len(stations))
+		for _, st := range stations {
+			names[st.ID] = st.Name
+		}
+
+		doc = receipt.NewKitchen(restaurant, o, names, req.StationID)
+		if len(doc.Tickets) == 0 {
+			return nil, "", fmt.Errorf("order has no items for the kitchen")
+		}
+	} else {
+		doc = receipt.NewReceipt(restaurant, o)
+
+		// HTML links the logo instead
+		if req.Format == model.FormatESCPOS || req.Format == model.FormatPDF {
+			doc.Logo = svc.logo(ctx, restaurant.Logo)
+		}
+	}
+
+	switch req.Format {
+	case model.FormatESCPOS:
+		return receipt.ESCPOS(doc, paper), "application/octet-stream", nil
+
+	case model.FormatPDF:
+		return receipt.PDF(doc), "application/pdf", nil
+
+	case model.FormatHTML:
+		html, err := receipt.HTML(doc)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return html, "text/html; charset=utf-8", nil
+
+	default:
+		return nil, "", fmt.Errorf("format must be '%s', '%s' or '%s'", model.FormatESCPOS, model.FormatPDF, model.FormatHTML)
+	}
+}
+
+// logo downloads and decodes the restaurant logo for printing. A logo that
+// cannot be fetched is left off the printout rather than failing it.
+func (svc *Receipt) logo(ctx *gofr.Context, url string) image.Image {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil
+	}
+
+	if cached, ok := svc.logos.Load(url); ok {
+		return cached.(image.Image)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		ctx.Logger.Errorf("invalid logo url %s: %v", url, err)
+		return nil
+	}
+
+	resp, err := svc.client.Do(req)
+	if err != nil {
+		ctx.Logger.Errorf("failed to fetch logo %s: %v", url, err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		ctx.Logger.Errorf("failed to fetch logo %s: status %d", url, resp.StatusCode)
+		return nil
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxLogoBytes))
+	if err != nil {
+		ctx.Logger.Errorf("failed to decode logo %s: %v", url, err)
+		return nil
+	}
+
+	svc.logos.Store(url, img)
+
+	return img
+}
@@ -11,10 +11,11 @@ import (
 
 type Restaurant struct {
 	store *store.Restaurant
+	audit *Audit
 }
 
-func NewRestaurant(s *store.Restaurant) *Restaurant {
-	return &Restaurant{store: s}
+func NewRestaurant(s *store.Restaurant, audit *Audit) *Restaurant {
+	return &Restaurant{store: s, audit: audit}
 }
 
 func (svc *Restaurant) GetAll(ctx *gofr.Context) ([]model.Restaurant, error) {
@@ -44,23 +45,45 @@ func (svc *Restaurant) Create(ctx *gofr.Context, r *model.Restaurant) (*model.Re
 
 	r.Active = true
 
-	return svc.store.Create(ctx, r)
+	result, err := svc.store.Create(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, result.ID, "restaurant", result.ID, AuditCreate, nil, result)
+
+	return result, nil
 }
 
 func (svc *Restaurant) Update(ctx *gofr.Context, id int, r *model.Restaurant) (*model.Restaurant, error) {
-	if _, err := svc.store.GetByID(ctx, id); err != nil {
+	before, err := svc.store.GetByID(ctx, id)
+	if err != nil {
 		return nil, fmt.Errorf("restaurant not found: %w", err)
 	}
 
-	return svc.store.Update(ctx, id, r)
+	result, err := svc.store.Update(ctx, id, r)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, id, "restaurant", id, AuditUpdate, before, result)
+
+	return result, nil
 }
 
 func (svc *Restaurant) Delete(ctx *gofr.Context, id int) error {
-	if _, err := svc.store.GetByID(ctx, id); err != nil {
+	before, err := svc.store.GetByID(ctx, id)
+	if err != nil {
 		return fmt.Errorf("restaurant not found: %w", err)
 	}
 
-	return svc.store.Delete(ctx, id)
+	if err := svc.store.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, id, "restaurant", id, AuditDelete, before, nil)
+
+	return nil
 }
 
 func generateSlug(name string) string {
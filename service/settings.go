@@ -13,10 +13,11 @@ import (
 
 type Settings struct {
 	store *store.Settings
+	audit *Audit
 }
 
-func NewSettings(s *store.Settings) *Settings {
-	return &Settings{store: s}
+func NewSettings(s *store.Settings, audit *Audit) *Settings {
+	return &Settings{store: s, audit: audit}
 }
 
 func (svc *Settings) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Setting, error) {
@@ -51,33 +52,62 @@ func (svc *Settings) Upsert(ctx *gofr.Context, restaurantID int, key, value stri
 		return nil, fmt.Errorf("setting key is required")
 	}
 
+	// A missing row means this upsert creates the setting
+	before, err := svc.store.GetByKey(ctx, restaurantID, key)
+	action := AuditUpdate
+	if err != nil {
+		before = nil
+		action = AuditCreate
+	}
+
 	result, err := svc.store.Upsert(ctx, restaurantID, key, value)
 	if err != nil {
 		return nil, err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "setting", key, action, before, result)
 
 	return result, nil
 }
 
 func (svc *Settings) BulkUpsert(ctx *gofr.Context, restaurantID int, settings map[string]string) ([]model.Setting, error) {
+	existing, err := svc.store.GetAll(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audit only the keys touched by this request
+	before := make(map[string]string)
+	for _, st := range existing {
+		if _, ok := settings[st.Key]; ok {
+			before[st.Key] = st.Value
+		}
+	}
+
 	result, err := svc.store.BulkUpsert(ctx, restaurantID, settings)
 	if err != nil {
 		return nil, err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "setting", "", AuditUpdate, before, settings)
 
 	return result, nil
 }
 
 func (svc *Settings) Delete(ctx *gofr.Context, restaurantID int, key string) error {
+	before, err := svc.store.GetByKey(ctx, restaurantID, key)
+	if err != nil {
+		return fmt.Errorf("setting not found: %w", err)
+	}
+
 	if err := svc.store.Delete(ctx, restaurantID, key); err != nil {
 		return err
 	}
 
 	svc.invalidateCache(ctx, restaurantID)
+	svc.audit.Record(ctx, restaurantID, "setting", key, AuditDelete, before, nil)
 
 	return nil
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
 	"qr-dinein-backend/store"
 	"strings"
@@ -10,11 +11,14 @@ import (
 )
 
 type Staff struct {
-	store *store.Staff
+	store        *store.Staff
+	loginLimiter *LoginLimiter
+	revocations  *auth.Revocations
+	audit        *Audit
 }
 
-func NewStaff(s *store.Staff) *Staff {
-	return &Staff{store: s}
+func NewStaff(s *store.Staff, loginLimiter *LoginLimiter, revocations *auth.Revocations, audit *Audit) *Staff {
+	return &Staff{store: s, loginLimiter: loginLimiter, revocations: revocations, audit: audit}
 }
 
 func (svc *Staff) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Staff, error) {
@@ -46,10 +50,23 @@ func (svc *Staff) Create(ctx *gofr.Context, restaurantID int, st *model.Staff) (
 		st.Role = "chef"
 	}
 
+	if st.MaxTickets < 0 {
+		return nil, fmt.Errorf("max concurrent tickets cannot be negative")
+	}
+
+	st.Skills = normalizeTags(st.Skills)
+
 	st.RestaurantID = restaurantID
 	st.Active = true
 
-	return svc.store.Create(ctx, st)
+	result, err := svc.store.Create(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "staff", result.ID, AuditCreate, nil, result)
+
+	return result, nil
 }
 
 func (svc *Staff) Update(ctx *gofr.Context, restaurantID, id int, st *model.Staff) (*model.Staff, error) {
@@ -57,17 +74,67 @@ func (svc *Staff) Update(ctx *gofr.Context, restaurantID, id int, st *model.Staf
 		return nil, fmt.Errorf("cannot assign superuser role to staff")
 	}
 
-	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
 		return nil, fmt.Errorf("staff member not found: %w", err)
 	}
 
-	return svc.store.Update(ctx, restaurantID, id, st)
+	if st.Pin != "" && len(st.Pin) != 6 {
+		return nil, fmt.Errorf("invalid pin length")
+	}
+
+	if st.MaxTickets < 0 {
+		return nil, fmt.Errorf("max concurrent tickets cannot be negative")
+	}
+
+	st.Skills = normalizeTags(st.Skills)
+
+	// Deactivation or a PIN change ends every existing session
+	revoke := !st.Active || st.Pin != ""
+
+	result, err := svc.store.Update(ctx, restaurantID, id, st)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoke {
+		if err := svc.revocations.RevokeStaff(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to revoke staff sessions: %w", err)
+		}
+	}
+
+	svc.audit.Record(ctx, restaurantID, "staff", id, AuditUpdate, before, result)
+
+	return result, nil
+}
+
+// Unlock clears the failed-login lockout for a staff member
+func (svc *Staff) Unlock(ctx *gofr.Context, restaurantID, id int) error {
+	st, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return fmt.Errorf("staff member not found: %w", err)
+	}
+
+	if err := svc.loginLimiter.Reset(ctx, st.Username); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "staff", id, AuditUnlock, nil, nil)
+
+	return nil
 }
 
 func (svc *Staff) Delete(ctx *gofr.Context, restaurantID, id int) error {
-	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
 		return fmt.Errorf("staff member not found: %w", err)
 	}
 
-	return svc.store.Delete(ctx, restaurantID, id)
+	if err := svc.store.Delete(ctx, restaurantID, id); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "staff", id, AuditDelete, before, nil)
+
+	return svc.revocations.RevokeStaff(ctx, id)
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Station struct {
+	store       *store.Station
+	staffStore  *store.Staff
+	ticketStore *store.Ticket
+	audit       *Audit
+}
+
+func NewStation(s *store.Station, staffStore *store.Staff, ticketStore *store.Ticket, audit *Audit) *Station {
+	return &Station{store: s, staffStore: staffStore, ticketStore: ticketStore, audit: audit}
+}
+
+func (svc *Station) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Station, error) {
+	return svc.store.GetAll(ctx, restaurantID)
+}
+
+func (svc *Station) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Station, error) {
+	return svc.store.GetByID(ctx, restaurantID, id)
+}
+
+func (svc *Station) Create(ctx *gofr.Context, restaurantID int, st *model.Station) (*model.Station, error) {
+	if strings.TrimSpace(st.Name) == "" {
+		return nil, fmt.Errorf("station name is required")
+	}
+
+	st.RestaurantID = restaurantID
+	st.Active = true
+
+	result, err := svc.store.Create(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "station", result.ID, AuditCreate, nil, result)
+
+	return result, nil
+}
+
+func (svc *Station) Update(ctx *gofr.Context, restaurantID, id int, st *model.Station) (*model.Station, error) {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("station not found: %w", err)
+	}
+
+	if strings.TrimSpace(st.Name) == "" {
+		return nil, fmt.Errorf("station name is required")
+	}
+
+	result, err := svc.store.Update(ctx, restaurantID, id, st)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "station", id, AuditUpdate, before, result)
+
+	return result, nil
+}
+
+// Delete removes a station. Categories and products routed to it fall back
+// to the general ticket; its existing tickets keep their items.
+func (svc *Station) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return fmt.Errorf("station not found: %w", err)
+	}
+
+	if err := svc.store.Delete(ctx, restaurantID, id); err != nil {
+		return err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "station", id, AuditDelete, before, nil)
+
+	return nil
+}
+
+// SetChefs replaces the chefs working a station. Every ID must be a chef of
+// the same restaurant.
+func (svc *Station) SetChefs(ctx *gofr.Context, restaurantID, id int, chefIDs []int) (*model.Station, error) {
+	before, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("station not found: %w", err)
+	}
+
+	unique := make([]int, 0, len(chefIDs))
+	seen := make(map[int]bool)
+	for _, chefID := range chefIDs {
+		if seen[chefID] {
+			continue
+		}
+		seen[chefID] = true
+
+		staff, err := svc.staffStore.GetByID(ctx, restaurantID, chefID)
+		if err != nil {
+			return nil, fmt.Errorf("staff member %d not found", chefID)
+		}
+
+		if staff.Role != "chef" {
+			return nil, fmt.Errorf("staff member '%s' is not a chef", staff.Username)
+		}
+
+		unique = append(unique, chefID)
+	}
+
+	if err := svc.store.SetChefs(ctx, id, unique); err != nil {
+		return nil, err
+	}
+
+	result, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.audit.Record(ctx, restaurantID, "station", id, AuditUpdate, before, result)
+
+	return result, nil
+}
+
+// GetTickets returns the station's open tickets, oldest first
+func (svc *Station) GetTickets(ctx *gofr.Context, restaurantID, id int) ([]model.Ticket, error) {
+	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+		return nil, fmt.Errorf("station not found: %w", err)
+	}
+
+	return svc.ticketStore.GetOpenByStation(ctx, restaurantID, id)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strconv"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const maxSplitShares = 50
+
+// Tab keeps the orders placed from one table on a running tab so they can be
+// billed, split and settled together. Tabs are opened by the first order
+// from a table and closed once the bill is settled.
+type Tab struct {
+	store      *store.Tab
+	orderStore *store.Order
+	orderSvc   *Order
+	paymentSvc *Payment
+	audit      *Audit
+}
+
+func NewTab(s *store.Tab, orderStore *store.Order, orderSvc *Order, paymentSvc *Payment, audit *Audit) *Tab {
+	return &Tab{store: s, orderStore: orderStore, orderSvc: orderSvc, paymentSvc: paymentSvc, audit: audit}
+}
+
+func (svc *Tab) GetAll(ctx *gofr.Context, restaurantID int, status string) ([]model.Tab, error) {
+	if status != "" && status != model.TabOpen && status != model.TabClosed {
+		return nil, fmt.Errorf("invalid tab status '%s'", status)
+	}
+
+	return svc.store.GetAll(ctx, restaurantID, status)
+}
+
+func (svc *Tab) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Tab, error) {
+	tab, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("tab not found: %w", err)
+	}
+
+	if tab.Orders, err = svc.orderStore.GetByTab(ctx, restaurantID, id); err != nil {
+		return nil, fmt.Errorf("failed to get tab orders: %w", err)
+	}
+
+	return tab, nil
+}
+
+// GetBill builds one bill over the items of every order on the tab that
+// were not cancelled or voided, so taxes, service charge and rounding are
+// applied once for the table
+func (svc *Tab) GetBill(ctx *gofr.Context, restaurantID, id int) (*model.TabBill, error) {
+	tab, err := svc.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.TabBill{TabID: tab.ID, OrderIDs: []int{}}
+
+	var items []model.OrderItem
+	for _, o := range tab.Orders {
+		if o.Status == "cancelled" {
+			continue
+		}
+
+		result.OrderIDs = append(result.OrderIDs, o.ID)
+		items = append(items, billableItems(o.Items)...)
+
+		if o.PaymentStatus == model.PaymentPaid {
+			result.Paid += o.Total
+		}
+	}
+
+	if result.Bill, err = svc.orderSvc.buildBill(ctx, restaurantID, items); err != nil {
+		return nil, fmt.Errorf("failed to compute bill: %w", err)
+	}
+
+	result.Paid = roundMoney(result.Paid)
+	result.Due = roundMoney(result.Bill.GrandTotal - result.Paid)
+	if result.Due < 0 {
+		result.Due = 0
+	}
+
+	return result, nil
+}
+
+// Split divides what is still due on the tab into equal shares or by the
+// items each guest had. Shares always add up to the amount due; the last
+// share takes any rounding difference.
+func (svc *Tab) Split(ctx *gofr.Context, restaurantID, id int, req *model.SplitRequest) (*model.TabSplit, error) {
+	bill, err := svc.GetBill(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	total := bill.Due
+	split := &model.TabSplit{TabID: id, Mode: req.Mode, Total: total}
+
+	switch req.Mode {
+	case model.SplitEqual:
+		if req.Shares < 2 || req.Shares > maxSplitShares {
+			return nil, fmt.Errorf("shares must be between 2 and %d", maxSplitShares)
+		}
+
+		each := roundMoney(total / float64(req.Shares))
+		for i := 0; i < req.Shares; i++ {
+			split.Shares = append(split.Shares, model.TabShare{Amount: each})
+		}
+
+	case model.SplitByItem:
+		if split.Shares, err = svc.splitByItem(ctx, restaurantID, id, req.Groups); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("split mode must be '%s' or '%s'", model.SplitEqual, model.SplitByItem)
+	}
+
+	assigned := 0.0
+	for _, share := range split.Shares[:len(split.Shares)-1] {
+		assigned += share.Amount
+	}
+	split.Shares[len(split.Shares)-1].Amount = roundMoney(total - assigned)
+
+	return split, nil
+}
+
+// splitByItem bills each group of items on its own. Every billable item of
+// the tab's unpaid orders must be in exactly one group.
+func (svc *Tab) splitByItem(ctx *gofr.Context, restaurantID, id int, groups [][]model.TabItemRef) ([]model.TabShare, error) {
+	if len(groups) < 2 || len(groups) > maxSplitShares {
+		return nil, fmt.Errorf("groups must have between 2 and %d entries", maxSplitShares)
+	}
+
+	orders, err := svc.orderStore.GetByTab(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tab orders: %w", err)
+	}
+
+	items := make(map[model.TabItemRef]model.OrderItem)
+	for _, o := range orders {
+		if o.Status == "cancelled" || o.PaymentStatus == model.PaymentPaid {
+			continue
+		}
+
+		for _, item := range billableItems(o.Items) {
+			items[model.TabItemRef{OrderID: o.ID, LineID: item.LineID}] = item
+		}
+	}
+
+	used := make(map[model.TabItemRef]bool, len(items))
+	shares := make([]model.TabShare, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("each group must have at least one item")
+		}
+
+		var groupItems []model.OrderItem
+		for _, ref := range group {
+			item, ok := items[ref]
+			if !ok {
+				return nil, fmt.Errorf("item %d of order %d is not on this tab", ref.LineID, ref.OrderID)
+			}
+
+			if used[ref] {
+				return nil, fmt.Errorf("item %d of order %d is in more than one group", ref.LineID, ref.OrderID)
+			}
+
+			used[ref] = true
+			groupItems = append(groupItems, item)
+		}
+
+		bill, err := svc.orderSvc.buildBill(ctx, restaurantID, groupItems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute bill: %w", err)
+		}
+
+		shares = append(shares, model.TabShare{Items: group, Bill: bill, Amount: bill.GrandTotal})
+	}
+
+	if len(used) != len(items) {
+		return nil, fmt.Errorf("every item on the tab must be in a group")
+	}
+
+	return shares, nil
+}
+
+// Close settles the tab and frees the table for the next guests. Orders
+// still in the kitchen keep the tab open. Unpaid orders need the settlement
+// taken at the counter, which is recorded as their payment.
+func (svc *Tab) Close(ctx *gofr.Context, restaurantID, id int, req *model.SettleRequest) (*model.Tab, error) {
+	tab, err := svc.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tab.Status != model.TabOpen {
+		return nil, fmt.Errorf("tab is already closed")
+	}
+
+	var unpaid []int
+	for _, o := range tab.Orders {
+		switch o.Status {
+		case "pending", "preparing", statusAwaitingPayment:
+			return nil, fmt.Errorf("order %d is still %s", o.ID, o.Status)
+		case "cancelled":
+			continue
+		}
+
+		if o.PaymentStatus == model.PaymentUnpaid {
+			unpaid = append(unpaid, o.ID)
+		}
+	}
+
+	if len(unpaid) > 0 && req.Method == "" {
+		return nil, fmt.Errorf("%d orders on the tab are unpaid, settle the bill to close it", len(unpaid))
+	}
+
+	// Each order is settled on its own, so a failure part way leaves the
+	// ones before it paid. The error names them so the counter collects
+	// only what is still owed; closing again skips them.
+	var settled []int
+	for _, orderID := range unpaid {
+		if _, err := svc.paymentSvc.Settle(ctx, restaurantID, orderID, req); err != nil {
+			return nil, fmt.Errorf("failed to settle order %d%s: %w", orderID, settledNote(settled), err)
+		}
+		settled = append(settled, orderID)
+	}
+
+	now := time.Now()
+	closed, err := svc.store.Close(ctx, restaurantID, id, len(tab.Orders), now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !closed {
+		return nil, fmt.Errorf("the tab was closed or had an order added meanwhile%s, check the bill and try again", settledNote(settled))
+	}
+
+	after, err := svc.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *tab
+	before.Orders = nil
+
+	snapshot := *after
+	snapshot.Orders = nil
+
+	svc.audit.Record(ctx, restaurantID, "tab", id, AuditUpdate, &before, &snapshot)
+
+	return after, nil
+}
+
+// settledNote tells the counter which orders a failed close already settled
+func settledNote(settled []int) string {
+	if len(settled) == 0 {
+		return ""
+	}
+
+	ids := make([]string, len(settled))
+	for i, id := range settled {
+		ids[i] = strconv.Itoa(id)
+	}
+
+	if len(ids) == 1 {
+		return fmt.Sprintf(" (order %s was settled and stays paid)", ids[0])
+	}
+
+	return fmt.Sprintf(" (orders %s were settled and stay paid)", strings.Join(ids, ", "))
+}
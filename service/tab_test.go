@@ -0,0 +1,115 @@
+package service
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const testOrdersByTab = "SELECT id, restaurant_id, table_id, table_number, tab_id, customer_mobile, customer_name, customer_id, items, status, payment_status, special_instructions, total, bill, assigned_chef_id, estimated_ready_at, cancellation, created_at, updated_at FROM orders WHERE restaurant_id = ? AND tab_id = ? ORDER BY created_at ASC"
+
+func expectTab(t *testing.T, mock sqlmock.Sqlmock, status string, orders ...*model.Order) {
+	t.Helper()
+
+	mock.ExpectQuery(testTabByID).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "table_id", "table_number", "status", "opened_at", "closed_at"}).
+			AddRow(3, 1, 5, "12", status, time.Now(), nil))
+	mock.ExpectQuery(testOrdersByTab).
+		WithArgs(1, 3).
+		WillReturnRows(orderRows(t, orders...))
+}
+
+func TestCloseTabNeedsUnpaidOrdersSettled(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := NewTab(store.NewTab(), store.NewOrder(), nil, nil, nil)
+
+	tabID := 3
+	expectTab(t, mock, model.TabOpen,
+		&model.Order{ID: 21, RestaurantID: 1, TabID: &tabID, Status: "completed", PaymentStatus: model.PaymentPaid, Total: 120, Items: []model.OrderItem{}},
+		&model.Order{ID: 22, RestaurantID: 1, TabID: &tabID, Status: "completed", PaymentStatus: model.PaymentUnpaid, Total: 80, Items: []model.OrderItem{}},
+	)
+
+	// Nothing is marked paid and the tab stays open
+	if _, err := svc.Close(ctx, 1, 3, &model.SettleRequest{}); err == nil {
+		t.Fatal("closed a tab with an unpaid order and no settlement")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloseTabRefusesOrderAddedMeanwhile(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	svc := NewTab(store.NewTab(), store.NewOrder(), nil, nil, nil)
+
+	tabID := 3
+	expectTab(t, mock, model.TabOpen,
+		&model.Order{ID: 21, RestaurantID: 1, TabID: &tabID, Status: "completed", PaymentStatus: model.PaymentPaid, Total: 120, Items: []model.OrderItem{}},
+	)
+
+	// A second order joined after the tab was checked
+	mock.ExpectExec("UPDATE table_tabs SET status = ?, open_table = NULL, closed_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND (SELECT COUNT(*) FROM orders WHERE tab_id = ?) = ?").
+		WithArgs(model.TabClosed, sqlmock.AnyArg(), 3, 1, model.TabOpen, 3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := svc.Close(ctx, 1, 3, &model.SettleRequest{}); err == nil {
+		t.Fatal("closed a tab that had an order added")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCloseTabNamesOrdersSettledBeforeAFailure checks that the counter is
+// told which orders were already collected when settling stops part way
+func TestCloseTabNamesOrdersSettledBeforeAFailure(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	audit := NewAudit(store.NewAudit())
+	orderSvc := &Order{store: store.NewOrder(), audit: audit}
+	paymentSvc := NewPayment(store.NewPayment(), store.NewOrder(), store.NewRestaurant(), orderSvc, nil, audit)
+	svc := NewTab(store.NewTab(), store.NewOrder(), orderSvc, paymentSvc, audit)
+
+	tabID := 3
+	first := &model.Order{ID: 21, RestaurantID: 1, TabID: &tabID, Status: "completed", PaymentStatus: model.PaymentUnpaid, Total: 120, Items: []model.OrderItem{}}
+	second := &model.Order{ID: 22, RestaurantID: 1, TabID: &tabID, Status: "completed", PaymentStatus: model.PaymentUnpaid, Total: 80, Items: []model.OrderItem{}}
+	expectTab(t, mock, model.TabOpen, first, second)
+
+	// Order 21 is settled
+	expectRestaurantName(mock, "Saravana Bhavan")
+	expectOrder(t, mock, first)
+	mock.ExpectExec("UPDATE orders SET payment_status = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND payment_status = ?").
+		WithArgs(model.PaymentPaid, sqlmock.AnyArg(), 21, 1, "completed", model.PaymentUnpaid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	paid := *first
+	paid.PaymentStatus = model.PaymentPaid
+	expectOrder(t, mock, &paid)
+	mock.ExpectExec(testAuditInsert).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO payments (restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)").
+		WillReturnResult(sqlmock.NewResult(31, 1))
+	mock.ExpectExec(testAuditInsert).WillReturnResult(sqlmock.NewResult(2, 1))
+
+	// and order 22 cannot be read
+	expectRestaurantName(mock, "Saravana Bhavan")
+	mock.ExpectQuery(testOrderByID).WithArgs(22, 1).WillReturnError(sql.ErrConnDone)
+
+	_, err := svc.Close(ctx, 1, 3, &model.SettleRequest{Method: model.SettleCash})
+	if err == nil {
+		t.Fatal("closed a tab whose settlement failed")
+	}
+
+	if !strings.Contains(err.Error(), "order 21 was settled") {
+		t.Errorf("got %q, want it to name order 21 as settled", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
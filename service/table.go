@@ -0,0 +1,149 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"qr-dinein-backend/auth"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"gofr.dev/pkg/gofr"
+)
+
+const qrImageSize = 512 // pixels
+
+type Table struct {
+	store           *store.Table
+	restaurantStore *store.Restaurant
+	signer          *auth.TableTokenSigner
+	menuBaseURL     string
+}
+
+func NewTable(s *store.Table, restaurantStore *store.Restaurant, signer *auth.TableTokenSigner, menuBaseURL string) *Table {
+	return &Table{
+		store:           s,
+		restaurantStore: restaurantStore,
+		signer:          signer,
+		menuBaseURL:     strings.TrimRight(menuBaseURL, "/"),
+	}
+}
+
+func (svc *Table) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Table, error) {
+	return svc.store.GetAll(ctx, restaurantID)
+}
+
+func (svc *Table) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Table, error) {
+	return svc.store.GetByID(ctx, restaurantID, id)
+}
+
+func (svc *Table) Create(ctx *gofr.Context, restaurantID int, t *model.Table) (*model.Table, error) {
+	if strings.TrimSpace(t.Number) == "" {
+		return nil, fmt.Errorf("table number is required")
+	}
+
+	if t.Capacity < 0 {
+		return nil, fmt.Errorf("table capacity cannot be negative")
+	}
+
+	t.RestaurantID = restaurantID
+	t.Active = true
+
+	return svc.store.Create(ctx, t)
+}
+
+func (svc *Table) Update(ctx *gofr.Context, restaurantID, id int, t *model.Table) (*model.Table, error) {
+	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+		return nil, fmt.Errorf("table not found: %w", err)
+	}
+
+	if strings.TrimSpace(t.Number) == "" {
+		return nil, fmt.Errorf("table number is required")
+	}
+
+	if t.Capacity < 0 {
+		return nil, fmt.Errorf("table capacity cannot be negative")
+	}
+
+	return svc.store.Update(ctx, restaurantID, id, t)
+}
+
+func (svc *Table) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+		return fmt.Errorf("table not found: %w", err)
+	}
+
+	return svc.store.Delete(ctx, restaurantID, id)
+}
+
+// GetQR builds the signed payload for a table's QR code. The URL points at
+// the slug-based menu landing page with the table token attached.
+func (svc *Table) GetQR(ctx *gofr.Context, restaurantID, id int) (*model.TableQR, error) {
+	table, err := svc.store.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("table not found: %w", err)
+	}
+
+	restaurant, err := svc.restaurantStore.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("restaurant not found: %w", err)
+	}
+
+	token := svc.signer.Sign(restaurantID, table.ID)
+
+	query := url.Values{}
+	query.Set("table", strconv.Itoa(table.ID))
+	query.Set("token", token)
+
+	return &model.TableQR{
+		TableID: table.ID,
+		Number:  table.Number,
+		Token:   token,
+		URL:     svc.menuBaseURL + "/" + url.PathEscape(restaurant.Slug) + "?" + query.Encode(),
+	}, nil
+}
+
+// GetQRImage renders the table's QR payload as a PNG
+func (svc *Table) GetQRImage(ctx *gofr.Context, restaurantID, id int) ([]byte, error) {
+	qr, err := svc.GetQR(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(qr.URL, qrcode.Medium, qrImageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+
+	return png, nil
+}
+
+// Verify checks that a table token is genuine, was issued for this
+// restaurant, and that the table is still active.
+func (svc *Table) Verify(ctx *gofr.Context, restaurantID int, token string) (*model.Table, error) {
+	if token == "" {
+		return nil, fmt.Errorf("table token is required")
+	}
+
+	tokenRestaurantID, tableID, err := svc.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenRestaurantID != restaurantID {
+		return nil, fmt.Errorf("table token is not valid for this restaurant")
+	}
+
+	table, err := svc.store.GetByID(ctx, restaurantID, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("table not found: %w", err)
+	}
+
+	if !table.Active {
+		return nil, fmt.Errorf("table is not active")
+	}
+
+	return table, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Tax struct {
+	store *store.Tax
+}
+
+func NewTax(s *store.Tax) *Tax {
+	return &Tax{store: s}
+}
+
+func (svc *Tax) GetAll(ctx *gofr.Context, restaurantID int) ([]model.TaxComponent, error) {
+	return svc.store.GetAll(ctx, restaurantID)
+}
+
+func (svc *Tax) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.TaxComponent, error) {
+	return svc.store.GetByID(ctx, restaurantID, id)
+}
+
+func (svc *Tax) Create(ctx *gofr.Context, restaurantID int, t *model.TaxComponent) (*model.TaxComponent, error) {
+	if err := validateTaxComponent(t); err != nil {
+		return nil, err
+	}
+
+	t.RestaurantID = restaurantID
+
+	return svc.store.Create(ctx, t)
+}
+
+func (svc *Tax) Update(ctx *gofr.Context, restaurantID, id int, t *model.TaxComponent) (*model.TaxComponent, error) {
+	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+		return nil, fmt.Errorf("tax component not found: %w", err)
+	}
+
+	if err := validateTaxComponent(t); err != nil {
+		return nil, err
+	}
+
+	return svc.store.Update(ctx, restaurantID, id, t)
+}
+
+func (svc *Tax) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	if _, err := svc.store.GetByID(ctx, restaurantID, id); err != nil {
+		return fmt.Errorf("tax component not found: %w", err)
+	}
+
+	return svc.store.Delete(ctx, restaurantID, id)
+}
+
+func validateTaxComponent(t *model.TaxComponent) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("tax name is required")
+	}
+
+	if t.Rate < 0 || t.Rate > 100 {
+		return fmt.Errorf("tax rate must be between 0 and 100")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Audit struct{}
+
+func NewAudit() *Audit {
+	return &Audit{}
+}
+
+func (s *Audit) Create(ctx *gofr.Context, e *model.AuditEntry) error {
+	now := time.Now()
+
+	var before, after *string
+	if len(e.Before) > 0 {
+		str := string(e.Before)
+		before = &str
+	}
+	if len(e.After) > 0 {
+		str := string(e.After)
+		after = &str
+	}
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO audit_log (restaurant_id, actor_type, actor_id, actor_name, resource, resource_id, action, before_data, after_data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		e.RestaurantID, e.ActorType, e.ActorID, e.ActorName, e.Resource, e.ResourceID, e.Action, before, after, now)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	e.ID = int(id)
+	e.CreatedAt = now
+
+	return nil
+}
+
+func (s *Audit) Query(ctx *gofr.Context, f *model.AuditFilter) ([]model.AuditEntry, error) {
+	var where []string
+	var args []interface{}
+
+	if f.RestaurantID != nil {
+		where = append(where, "restaurant_id = ?")
+		args = append(args, *f.RestaurantID)
+	}
+	if f.Actor != "" {
+		where = append(where, "actor_name = ?")
+		args = append(args, f.Actor)
+	}
+	if f.Resource != "" {
+		where = append(where, "resource = ?")
+		args = append(args, f.Resource)
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		where = append(where, "created_at <= ?")
+		args = append(args, *f.To)
+	}
+
+	query := "SELECT id, restaurant_id, actor_type, actor_id, actor_name, resource, resource_id, action, before_data, after_data, created_at FROM audit_log"
+	if len(where) > 0 {
+		query += " WHERE " + joinConditions(where)
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	rows, err := ctx.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AuditEntry
+	for rows.Next() {
+		var e model.AuditEntry
+		var restaurantID sql.NullInt64
+		var before, after []byte
+
+		if err := rows.Scan(&e.ID, &restaurantID, &e.ActorType, &e.ActorID, &e.ActorName, &e.Resource, &e.ResourceID, &e.Action, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if restaurantID.Valid {
+			id := int(restaurantID.Int64)
+			e.RestaurantID = &id
+		}
+
+		e.Before = before
+		e.After = after
+
+		list = append(list, e)
+	}
+
+	if list == nil {
+		list = []model.AuditEntry{}
+	}
+
+	return list, nil
+}
+
+func joinConditions(conditions []string) string {
+	result := ""
+	for i, c := range conditions {
+		if i > 0 {
+			result += " AND "
+		}
+		result += c
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"database/sql"
 	"qr-dinein-backend/model"
 	"time"
 
@@ -15,7 +16,7 @@ func NewCategory() *Category {
 
 func (s *Category) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Category, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, name, `order`, image, created_at, updated_at FROM categories WHERE restaurant_id = ? ORDER BY `order` ASC",
+		"SELECT id, restaurant_id, name, `order`, image, station_id, created_at, updated_at FROM categories WHERE restaurant_id = ? ORDER BY `order` ASC",
 		restaurantID)
 	if err != nil {
 		return nil, err
@@ -25,9 +26,16 @@ func (s *Category) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Category
 	var list []model.Category
 	for rows.Next() {
 		var c model.Category
-		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.Name, &c.Order, &c.Image, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		var stationID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.Name, &c.Order, &c.Image, &stationID, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, err
 		}
+
+		if stationID.Valid {
+			id := int(stationID.Int64)
+			c.StationID = &id
+		}
+
 		list = append(list, c)
 	}
 
@@ -40,14 +48,20 @@ func (s *Category) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Category
 
 func (s *Category) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Category, error) {
 	var c model.Category
+	var stationID sql.NullInt64
 	err := ctx.SQL.QueryRowContext(ctx,
-		"SELECT id, restaurant_id, name, `order`, image, created_at, updated_at FROM categories WHERE id = ? AND restaurant_id = ?",
+		"SELECT id, restaurant_id, name, `order`, image, station_id, created_at, updated_at FROM categories WHERE id = ? AND restaurant_id = ?",
 		id, restaurantID).
-		Scan(&c.ID, &c.RestaurantID, &c.Name, &c.Order, &c.Image, &c.CreatedAt, &c.UpdatedAt)
+		Scan(&c.ID, &c.RestaurantID, &c.Name, &c.Order, &c.Image, &stationID, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if stationID.Valid {
+		id := int(stationID.Int64)
+		c.StationID = &id
+	}
+
 	return &c, nil
 }
 
@@ -55,8 +69,8 @@ func (s *Category) Create(ctx *gofr.Context, c *model.Category) (*model.Category
 	now := time.Now()
 
 	result, err := ctx.SQL.ExecContext(ctx,
-		"INSERT INTO categories (restaurant_id, name, `order`, image, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
-		c.RestaurantID, c.Name, c.Order, c.Image, now, now)
+		"INSERT INTO categories (restaurant_id, name, `order`, image, station_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		c.RestaurantID, c.Name, c.Order, c.Image, c.StationID, now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +87,8 @@ func (s *Category) Update(ctx *gofr.Context, restaurantID, id int, c *model.Cate
 	now := time.Now()
 
 	_, err := ctx.SQL.ExecContext(ctx,
-		"UPDATE categories SET name = ?, `order` = ?, image = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
-		c.Name, c.Order, c.Image, now, id, restaurantID)
+		"UPDATE categories SET name = ?, `order` = ?, image = ?, station_id = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+		c.Name, c.Order, c.Image, c.StationID, now, id, restaurantID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Customer struct{}
+
+func NewCustomer() *Customer {
+	return &Customer{}
+}
+
+const customerColumns = "c.id, c.phone_number, c.name, c.email, c.preferences, c.allergens, c.notifications_opt_out, c.created_at, c.updated_at"
+
+// Upsert returns the customer with the phone number, creating them on their
+// first verification
+func (s *Customer) Upsert(ctx *gofr.Context, phone string) (*model.Customer, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO customers (phone_number, created_at, updated_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)",
+		phone, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+
+	return s.GetByID(ctx, int(id))
+}
+
+func (s *Customer) GetByID(ctx *gofr.Context, id int) (*model.Customer, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+customerColumns+" FROM customers c WHERE c.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return firstCustomer(rows)
+}
+
+func (s *Customer) GetByPhone(ctx *gofr.Context, phone string) (*model.Customer, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+customerColumns+" FROM customers c WHERE c.phone_number = ?", phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return firstCustomer(rows)
+}
+
+// UpdateProfile saves the details the customer keeps for later visits
+func (s *Customer) UpdateProfile(ctx *gofr.Context, c *model.Customer) error {
+	preferences, err := json.Marshal(c.Preferences)
+	if err != nil {
+		return err
+	}
+
+	allergens, err := json.Marshal(c.Allergens)
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.SQL.ExecContext(ctx,
+		"UPDATE customers SET name = ?, email = ?, preferences = ?, allergens = ?, notifications_opt_out = ?, updated_at = ? WHERE id = ?",
+		c.Name, c.Email, preferences, allergens, c.NotificationsOptOut, time.Now(), c.ID)
+
+	return err
+}
+
+func firstCustomer(rows rowScanner) (*model.Customer, error) {
+	list, err := scanCustomers(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+func scanCustomers(rows rowScanner) ([]model.Customer, error) {
+	list := []model.Customer{}
+	for rows.Next() {
+		var c model.Customer
+		var preferences, allergens []byte
+		if err := rows.Scan(&c.ID, &c.PhoneNumber, &c.Name, &c.Email, &preferences, &allergens, &c.NotificationsOptOut, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if len(preferences) > 0 {
+			if err := json.Unmarshal(preferences, &c.Preferences); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.Preferences == nil {
+			c.Preferences = map[string]string{}
+		}
+
+		var err error
+		if c.Allergens, err = decodeTags(allergens); err != nil {
+			return nil, err
+		}
+
+		list = append(list, c)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+)
+
+// Key prefixes of the customer state
+const (
+	keyPrefixOTP             = "customer_otp:"
+	keyPrefixOTPCooldown     = "otp_cooldown:"
+	keyPrefixOTPRate         = "otp_rate:"
+	keyPrefixCustomerSession = "customer_session:"
+)
+
+// CustomerStateStore keeps the short-lived state of customer sign-in: OTPs
+// and their failed attempts, the resend cooldown, the hourly OTP limit and
+// the sessions issued on verification. Missing or expired state is reported
+// as sql.ErrNoRows; any other error comes from the backend and must not be
+// mistaken for missing state.
+type CustomerStateStore interface {
+	// SaveOTP replaces the phone's OTP and resets its attempts
+	SaveOTP(ctx *gofr.Context, phone, otp string, ttl time.Duration) error
+
+	// AddOTPAttempt atomically counts an attempt against the phone's OTP and
+	// returns the OTP with the attempts so far, this one included
+	AddOTPAttempt(ctx *gofr.Context, phone string) (*model.OTPData, error)
+
+	// DeleteOTP removes the phone's OTP and reports whether it was still
+	// there, so a correct OTP verifies only once
+	DeleteOTP(ctx *gofr.Context, phone string) (bool, error)
+
+	// StartOTPCooldown starts the phone's resend cooldown. While one is
+	// already running it returns the time left instead.
+	StartOTPCooldown(ctx *gofr.Context, phone string, cooldown time.Duration) (time.Duration, error)
+
+	ClearOTPCooldown(ctx *gofr.Context, phone string) error
+
+	// CountOTPRequest counts an OTP request for the phone in a window that
+	// starts with its first request, returning the requests so far
+	CountOTPRequest(ctx *gofr.Context, phone string, window time.Duration) (int, error)
+
+	CreateSession(ctx *gofr.Context, tokenHash string, session *model.CustomerSession) error
+
+	// GetSession returns the unexpired session a token was issued for
+	GetSession(ctx *gofr.Context, tokenHash string) (*model.CustomerSession, error)
+
+	DeleteSession(ctx *gofr.Context, tokenHash string) error
+}
+
+// NewCustomerStateStore returns the customer state backend called kind:
+// redis (kept in rdb), sql or memory (single instance only; for tests and
+// local runs)
+func NewCustomerStateStore(kind string, rdb redis.Cmdable) (CustomerStateStore, error) {
+	switch kind {
+	case "redis":
+		return NewCustomerStateRedis(rdb), nil
+	case "sql":
+		return NewCustomerStateSQL(), nil
+	case "memory":
+		return NewCustomerStateMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown customer state store '%s'", kind)
+	}
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"sync"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// CustomerStateMemory keeps customer state in process memory. It is lost on
+// restart and not shared between instances, so it is meant for tests and
+// local runs.
+type CustomerStateMemory struct {
+	mu       sync.Mutex
+	otps     map[string]*memoryOTP
+	limits   map[string]*memoryLimit
+	sessions map[string]model.CustomerSession
+}
+
+type memoryOTP struct {
+	data      model.OTPData
+	expiresAt time.Time
+}
+
+type memoryLimit struct {
+	count     int
+	expiresAt time.Time
+}
+
+func NewCustomerStateMemory() *CustomerStateMemory {
+	return &CustomerStateMemory{
+		otps:     map[string]*memoryOTP{},
+		limits:   map[string]*memoryLimit{},
+		sessions: map[string]model.CustomerSession{},
+	}
+}
+
+func (s *CustomerStateMemory) SaveOTP(_ *gofr.Context, phone, otp string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.otps[phone] = &memoryOTP{data: model.OTPData{OTP: otp}, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s *CustomerStateMemory) AddOTPAttempt(_ *gofr.Context, phone string) (*model.OTPData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.otps[phone]
+	if !ok || !time.Now().Before(otp.expiresAt) {
+		delete(s.otps, phone)
+		return nil, sql.ErrNoRows
+	}
+
+	otp.data.Attempts++
+	data := otp.data
+
+	return &data, nil
+}
+
+func (s *CustomerStateMemory) DeleteOTP(_ *gofr.Context, phone string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.otps[phone]
+	delete(s.otps, phone)
+
+	return ok && time.Now().Before(otp.expiresAt), nil
+}
+
+func (s *CustomerStateMemory) StartOTPCooldown(_ *gofr.Context, phone string, cooldown time.Duration) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := keyPrefixOTPCooldown + phone
+	if l, ok := s.limits[key]; ok {
+		if left := time.Until(l.expiresAt); left > 0 {
+			return left, nil
+		}
+	}
+
+	s.limits[key] = &memoryLimit{count: 1, expiresAt: time.Now().Add(cooldown)}
+
+	return 0, nil
+}
+
+func (s *CustomerStateMemory) ClearOTPCooldown(_ *gofr.Context, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.limits, keyPrefixOTPCooldown+phone)
+
+	return nil
+}
+
+func (s *CustomerStateMemory) CountOTPRequest(_ *gofr.Context, phone string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := keyPrefixOTPRate + phone
+	l, ok := s.limits[key]
+	if !ok || !time.Now().Before(l.expiresAt) {
+		l = &memoryLimit{expiresAt: time.Now().Add(window)}
+		s.limits[key] = l
+	}
+
+	l.count++
+
+	return l.count, nil
+}
+
+func (s *CustomerStateMemory) CreateSession(_ *gofr.Context, tokenHash string, session *model.CustomerSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[tokenHash] = *session
+
+	return nil
+}
+
+func (s *CustomerStateMemory) GetSession(_ *gofr.Context, tokenHash string) (*model.CustomerSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[tokenHash]
+	if !ok || !time.Now().Before(session.ExpiresAt) {
+		delete(s.sessions, tokenHash)
+		return nil, sql.ErrNoRows
+	}
+
+	return &session, nil
+}
+
+func (s *CustomerStateMemory) DeleteSession(_ *gofr.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, tokenHash)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+//go:build mysql
+
+package store
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// The SQL customer state is also checked against a real MySQL database, as
+// its counters rely on LAST_INSERT_ID and ON DUPLICATE KEY UPDATE:
+//
+//	TEST_MYSQL_DSN='user:pass@tcp(localhost:3306)/dinein_test' go test -tags mysql ./store
+func init() {
+	customerStateBackends["mysql"] = func(t *testing.T) customerStateBackend {
+		dsn := os.Getenv("TEST_MYSQL_DSN")
+		if dsn == "" {
+			t.Skip("TEST_MYSQL_DSN is not set")
+		}
+
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.ParseTime = true
+
+		db, err := sql.Open("mysql", cfg.FormatDSN())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		// The tables as the migrations leave them, customers trimmed to what
+		// sessions join on
+		for _, stmt := range []string{
+			`CREATE TABLE IF NOT EXISTS customers (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				phone_number VARCHAR(20) NOT NULL,
+				UNIQUE KEY unique_customer_phone (phone_number)
+			)`,
+			`CREATE TABLE IF NOT EXISTS customer_tokens (
+				token_hash CHAR(64) PRIMARY KEY,
+				customer_id INT NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				last_used_at TIMESTAMP NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS customer_otps (
+				phone_number VARCHAR(20) PRIMARY KEY,
+				code VARCHAR(10) NOT NULL,
+				attempts INT NOT NULL DEFAULT 0,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS customer_limits (
+				limit_key VARCHAR(64) PRIMARY KEY,
+				count INT NOT NULL DEFAULT 0,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+			"DELETE FROM customer_tokens",
+			"DELETE FROM customer_otps",
+			"DELETE FROM customer_limits",
+		} {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ctx, _ := newTestContext(t)
+
+		return customerStateBackend{
+			store: &CustomerStateSQL{db: db},
+			ctx:   ctx,
+			customer: func(phone string) int {
+				result, err := db.Exec("INSERT INTO customers (phone_number) VALUES (?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)", phone)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				id, _ := result.LastInsertId()
+
+				return int(id)
+			},
+			// TIMESTAMP columns keep whole seconds
+			expire: func(d time.Duration) { time.Sleep(d + time.Second) },
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"qr-dinein-backend/model"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+)
+
+// addOTPAttempt counts an attempt against an OTP hash that still exists and
+// returns its code with the new attempt count. Running it as a script keeps
+// the check and the increment atomic, and HINCRBY never recreates an OTP
+// that expired in between without a TTL.
+var addOTPAttempt = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+return {redis.call('HGET', KEYS[1], 'otp'), attempts}
+`)
+
+// CustomerStateRedis keeps customer state in Redis. OTPs are hashes so
+// their attempts can be counted in place.
+type CustomerStateRedis struct {
+	rdb redis.Cmdable
+}
+
+func NewCustomerStateRedis(rdb redis.Cmdable) *CustomerStateRedis {
+	return &CustomerStateRedis{rdb: rdb}
+}
+
+func (s *CustomerStateRedis) SaveOTP(ctx *gofr.Context, phone, otp string, ttl time.Duration) error {
+	key := keyPrefixOTP + phone
+
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "otp", otp, "attempts", 0)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+
+	return err
+}
+
+func (s *CustomerStateRedis) AddOTPAttempt(ctx *gofr.Context, phone string) (*model.OTPData, error) {
+	result, err := addOTPAttempt.Run(ctx, s.rdb, []string{keyPrefixOTP + phone}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 2 {
+		otp, ok := result[0].(string)
+		attempts, ok2 := result[1].(int64)
+		if ok && ok2 {
+			return &model.OTPData{OTP: otp, Attempts: int(attempts)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected OTP data for %s", phone)
+}
+
+func (s *CustomerStateRedis) DeleteOTP(ctx *gofr.Context, phone string) (bool, error) {
+	n, err := s.rdb.Del(ctx, keyPrefixOTP+phone).Result()
+	return n > 0, err
+}
+
+func (s *CustomerStateRedis) StartOTPCooldown(ctx *gofr.Context, phone string, cooldown time.Duration) (time.Duration, error) {
+	key := keyPrefixOTPCooldown + phone
+
+	started, err := s.rdb.SetNX(ctx, key, "1", cooldown).Result()
+	if err != nil || started {
+		return 0, err
+	}
+
+	ttl, err := s.rdb.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// The cooldown may have ended between the two calls; the caller still
+	// did not start one
+	return max(ttl, time.Second), nil
+}
+
+func (s *CustomerStateRedis) ClearOTPCooldown(ctx *gofr.Context, phone string) error {
+	return s.rdb.Del(ctx, keyPrefixOTPCooldown+phone).Err()
+}
+
+func (s *CustomerStateRedis) CountOTPRequest(ctx *gofr.Context, phone string, window time.Duration) (int, error) {
+	key := keyPrefixOTPRate + phone
+
+	// The first request creates the counter with the window as its expiry in
+	// the same transaction, so it can never be left without one
+	var count *redis.IntCmd
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, window)
+		count = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count.Val()), nil
+}
+
+func (s *CustomerStateRedis) CreateSession(ctx *gofr.Context, tokenHash string, session *model.CustomerSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return s.rdb.Set(ctx, keyPrefixCustomerSession+tokenHash, string(data), time.Until(session.ExpiresAt)).Err()
+}
+
+func (s *CustomerStateRedis) GetSession(ctx *gofr.Context, tokenHash string) (*model.CustomerSession, error) {
+	data, err := s.rdb.Get(ctx, keyPrefixCustomerSession+tokenHash).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session model.CustomerSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (s *CustomerStateRedis) DeleteSession(ctx *gofr.Context, tokenHash string) error {
+	return s.rdb.Del(ctx, keyPrefixCustomerSession+tokenHash).Err()
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// CustomerStateSQL keeps customer state in the database, for deployments
+// that would rather not depend on Redis for sign-in. Counters are updated
+// in a single statement and read back through LAST_INSERT_ID, so concurrent
+// requests never lose a count; OTP attempts are read and counted under a
+// row lock.
+type CustomerStateSQL struct {
+	db *sql.DB // used instead of the request's database when set, by tests
+}
+
+// sqlConn is the part of a database connection the customer state uses
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlTx is a transaction on either connection
+type sqlTx interface {
+	sqlConn
+	Commit() error
+	Rollback() error
+}
+
+func NewCustomerStateSQL() *CustomerStateSQL {
+	return &CustomerStateSQL{}
+}
+
+func (s *CustomerStateSQL) conn(ctx *gofr.Context) sqlConn {
+	if s.db != nil {
+		return s.db
+	}
+
+	return ctx.SQL
+}
+
+func (s *CustomerStateSQL) begin(ctx *gofr.Context) (sqlTx, error) {
+	if s.db != nil {
+		return s.db.BeginTx(ctx, nil)
+	}
+
+	return ctx.SQL.Begin()
+}
+
+func (s *CustomerStateSQL) SaveOTP(ctx *gofr.Context, phone, otp string, ttl time.Duration) error {
+	_, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT INTO customer_otps (phone_number, code, attempts, expires_at) VALUES (?, ?, 0, ?) ON DUPLICATE KEY UPDATE code = VALUES(code), attempts = 0, expires_at = VALUES(expires_at)",
+		phone, otp, time.Now().Add(ttl))
+
+	return err
+}
+
+// AddOTPAttempt reads the OTP and counts the attempt in one transaction,
+// holding the row so an OTP saved meanwhile is never returned with the
+// count of the one it replaced
+func (s *CustomerStateSQL) AddOTPAttempt(ctx *gofr.Context, phone string) (*model.OTPData, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var data model.OTPData
+	err = tx.QueryRowContext(ctx,
+		"SELECT code, attempts FROM customer_otps WHERE phone_number = ? AND expires_at > ? FOR UPDATE", phone, time.Now()).
+		Scan(&data.OTP, &data.Attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Attempts++
+
+	if _, err := tx.ExecContext(ctx, "UPDATE customer_otps SET attempts = ? WHERE phone_number = ?", data.Attempts, phone); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (s *CustomerStateSQL) DeleteOTP(ctx *gofr.Context, phone string) (bool, error) {
+	result, err := s.conn(ctx).ExecContext(ctx,
+		"DELETE FROM customer_otps WHERE phone_number = ? AND expires_at > ?", phone, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+func (s *CustomerStateSQL) StartOTPCooldown(ctx *gofr.Context, phone string, cooldown time.Duration) (time.Duration, error) {
+	key := keyPrefixOTPCooldown + phone
+
+	count, err := s.countLimit(ctx, key, cooldown)
+	if err != nil || count == 1 {
+		return 0, err
+	}
+
+	var expiresAt time.Time
+	err = s.conn(ctx).QueryRowContext(ctx, "SELECT expires_at FROM customer_limits WHERE limit_key = ?", key).
+		Scan(&expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(time.Until(expiresAt), time.Second), nil
+}
+
+func (s *CustomerStateSQL) ClearOTPCooldown(ctx *gofr.Context, phone string) error {
+	_, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM customer_limits WHERE limit_key = ?", keyPrefixOTPCooldown+phone)
+	return err
+}
+
+func (s *CustomerStateSQL) CountOTPRequest(ctx *gofr.Context, phone string, window time.Duration) (int, error) {
+	return s.countLimit(ctx, keyPrefixOTPRate+phone, window)
+}
+
+// countLimit counts an event against key in a window that starts with the
+// first event, returning the events so far. An expired window starts over.
+func (s *CustomerStateSQL) countLimit(ctx *gofr.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	result, err := s.conn(ctx).ExecContext(ctx,
+		`INSERT INTO customer_limits (limit_key, count, expires_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE count = LAST_INSERT_ID(IF(expires_at > ?, count + 1, 1)),
+			expires_at = IF(expires_at > ?, expires_at, VALUES(expires_at))`,
+		key, now.Add(window), now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// A new row is the first event; an updated one reports its count
+	if n, _ := result.RowsAffected(); n == 1 {
+		return 1, nil
+	}
+
+	count, err := result.LastInsertId()
+
+	return int(count), err
+}
+
+func (s *CustomerStateSQL) CreateSession(ctx *gofr.Context, tokenHash string, session *model.CustomerSession) error {
+	_, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT INTO customer_tokens (token_hash, customer_id, expires_at, created_at) VALUES (?, ?, ?, ?)",
+		tokenHash, session.CustomerID, session.ExpiresAt, time.Now())
+
+	return err
+}
+
+// GetSession also records when the token was last used
+func (s *CustomerStateSQL) GetSession(ctx *gofr.Context, tokenHash string) (*model.CustomerSession, error) {
+	now := time.Now()
+
+	var session model.CustomerSession
+	err := s.conn(ctx).QueryRowContext(ctx,
+		"SELECT c.id, c.phone_number, t.expires_at FROM customer_tokens t JOIN customers c ON c.id = t.customer_id WHERE t.token_hash = ? AND t.expires_at > ?",
+		tokenHash, now).
+		Scan(&session.CustomerID, &session.PhoneNumber, &session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only informational, so a failure does not sign the customer out
+	if _, err := s.conn(ctx).ExecContext(ctx, "UPDATE customer_tokens SET last_used_at = ? WHERE token_hash = ?", now, tokenHash); err != nil {
+		ctx.Logger.Errorf("failed to record use of customer session: %v", err)
+	}
+
+	session.Verified = true
+
+	return &session, nil
+}
+
+func (s *CustomerStateSQL) DeleteSession(ctx *gofr.Context, tokenHash string) error {
+	_, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM customer_tokens WHERE token_hash = ?", tokenHash)
+	return err
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// The statements of the SQL customer state, which the shared tests in
+// customer_state_test.go expect on the mock database
+const (
+	testSaveOTP       = "INSERT INTO customer_otps (phone_number, code, attempts, expires_at) VALUES (?, ?, 0, ?) ON DUPLICATE KEY UPDATE code = VALUES(code), attempts = 0, expires_at = VALUES(expires_at)"
+	testOTPForAttempt = "SELECT code, attempts FROM customer_otps WHERE phone_number = ? AND expires_at > ? FOR UPDATE"
+	testOTPAttempt    = "UPDATE customer_otps SET attempts = ? WHERE phone_number = ?"
+	testDeleteOTP     = "DELETE FROM customer_otps WHERE phone_number = ? AND expires_at > ?"
+	testCountLimit    = `INSERT INTO customer_limits (limit_key, count, expires_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE count = LAST_INSERT_ID(IF(expires_at > ?, count + 1, 1)),
+			expires_at = IF(expires_at > ?, expires_at, VALUES(expires_at))`
+	testCreateSession = "INSERT INTO customer_tokens (token_hash, customer_id, expires_at, created_at) VALUES (?, ?, ?, ?)"
+	testSession       = "SELECT c.id, c.phone_number, t.expires_at FROM customer_tokens t JOIN customers c ON c.id = t.customer_id WHERE t.token_hash = ? AND t.expires_at > ?"
+	testSessionUsed   = "UPDATE customer_tokens SET last_used_at = ? WHERE token_hash = ?"
+)
+
+func expectSaveOTP(mock sqlmock.Sqlmock, phone, otp string) {
+	mock.ExpectExec(testSaveOTP).
+		WithArgs(phone, otp, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectOTPAttempt expects the attempt that brings the OTP to attempts
+func expectOTPAttempt(mock sqlmock.Sqlmock, phone, otp string, attempts int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(testOTPForAttempt).
+		WithArgs(phone, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "attempts"}).AddRow(otp, attempts-1))
+	mock.ExpectExec(testOTPAttempt).
+		WithArgs(attempts, phone).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+// expectNoOTP expects an attempt on a phone without a live OTP
+func expectNoOTP(mock sqlmock.Sqlmock, phone string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(testOTPForAttempt).
+		WithArgs(phone, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "attempts"}))
+	mock.ExpectRollback()
+}
+
+func expectDeleteOTP(mock sqlmock.Sqlmock, phone string, deleted bool) {
+	var n int64
+	if deleted {
+		n = 1
+	}
+
+	mock.ExpectExec(testDeleteOTP).
+		WithArgs(phone, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, n))
+}
+
+// expectCountLimit expects the count-th event in a window. MySQL reports a
+// new row as one affected and an updated one as two.
+func expectCountLimit(mock sqlmock.Sqlmock, key string, count int) {
+	result := sqlmock.NewResult(int64(count), 2)
+	if count == 1 {
+		result = sqlmock.NewResult(0, 1)
+	}
+
+	mock.ExpectExec(testCountLimit).
+		WithArgs(key, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(result)
+}
+
+// expectWindowRestart expects an event on a row whose window has run out
+func expectWindowRestart(mock sqlmock.Sqlmock, key string) {
+	mock.ExpectExec(testCountLimit).
+		WithArgs(key, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+}
+
+// expectSession expects a session to be looked up; a zero customerID means
+// there is none
+func expectSession(mock sqlmock.Sqlmock, tokenHash string, customerID int, phone string) {
+	rows := sqlmock.NewRows([]string{"id", "phone_number", "expires_at"})
+	if customerID != 0 {
+		rows.AddRow(customerID, phone, time.Now().Add(time.Hour))
+	}
+
+	mock.ExpectQuery(testSession).
+		WithArgs(tokenHash, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+}
+
+// TestSessionOutlivesLastUsedFailure checks that failing to record when a
+// token was used does not sign the customer out
+func TestSessionOutlivesLastUsedFailure(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	s := NewCustomerStateSQL()
+
+	expectSession(mock, "token-hash", 42, "+919800000010")
+	mock.ExpectExec(testSessionUsed).
+		WithArgs(sqlmock.AnyArg(), "token-hash").
+		WillReturnError(errors.New("lock wait timeout exceeded"))
+
+	session, err := s.GetSession(ctx, "token-hash")
+	if err != nil {
+		t.Fatalf("GetSession() = %v", err)
+	}
+
+	if session.CustomerID != 42 || !session.Verified {
+		t.Errorf("got %+v, want the verified session of customer 42", session)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
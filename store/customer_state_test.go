@@ -0,0 +1,412 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"qr-dinein-backend/model"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+)
+
+// customerStateBackend sets up one CustomerStateStore for a test. customer
+// returns the ID of a customer with the phone number, for sessions; expire
+// lets d pass as far as the store's expiries are concerned. mock is set when
+// the store runs on a mock database, and each test then expects the
+// statements the store should send for it.
+type customerStateBackend struct {
+	store    CustomerStateStore
+	ctx      *gofr.Context
+	customer func(phone string) int
+	expire   func(d time.Duration)
+	mock     sqlmock.Sqlmock
+}
+
+// customerStateBackends are the stores every behaviour below is checked
+// against. The SQL store also runs against MySQL in
+// customer_state_mysql_test.go, built with -tags mysql.
+var customerStateBackends = map[string]func(t *testing.T) customerStateBackend{
+	"memory": func(t *testing.T) customerStateBackend {
+		ctx, _ := newTestContext(t)
+
+		return customerStateBackend{
+			store:    NewCustomerStateMemory(),
+			ctx:      ctx,
+			customer: func(string) int { return 42 },
+			expire:   time.Sleep,
+		}
+	},
+	"redis": func(t *testing.T) customerStateBackend {
+		mr := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+
+		ctx, _ := newTestContext(t)
+
+		return customerStateBackend{
+			store:    NewCustomerStateRedis(rdb),
+			ctx:      ctx,
+			customer: func(string) int { return 42 },
+			expire:   mr.FastForward,
+		}
+	},
+	"sql": func(t *testing.T) customerStateBackend {
+		ctx, mock := newTestContext(t)
+
+		return customerStateBackend{
+			store:    NewCustomerStateSQL(),
+			ctx:      ctx,
+			customer: func(string) int { return 42 },
+			expire:   func(time.Duration) {},
+			mock:     mock,
+		}
+	},
+}
+
+// forEachCustomerState runs test against every backend
+func forEachCustomerState(t *testing.T, test func(t *testing.T, b customerStateBackend)) {
+	for name, backend := range customerStateBackends {
+		t.Run(name, func(t *testing.T) {
+			b := backend(t)
+			test(t, b)
+
+			if b.mock != nil {
+				if err := b.mock.ExpectationsWereMet(); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestOTPAttemptsAreCounted(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+		phone := "+919800000001"
+
+		if b.mock != nil {
+			expectNoOTP(b.mock, phone)
+			expectSaveOTP(b.mock, phone, "482913")
+			for attempts := 1; attempts <= 3; attempts++ {
+				expectOTPAttempt(b.mock, phone, "482913", attempts)
+			}
+			expectSaveOTP(b.mock, phone, "105377")
+			expectOTPAttempt(b.mock, phone, "105377", 1)
+		}
+
+		if _, err := b.store.AddOTPAttempt(ctx, phone); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("attempt without an OTP: got %v, want sql.ErrNoRows", err)
+		}
+
+		if err := b.store.SaveOTP(ctx, phone, "482913", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+
+		for want := 1; want <= 3; want++ {
+			data, err := b.store.AddOTPAttempt(ctx, phone)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.OTP != "482913" || data.Attempts != want {
+				t.Errorf("got %+v, want OTP 482913 with %d attempts", data, want)
+			}
+		}
+
+		// A new OTP starts its attempts over
+		if err := b.store.SaveOTP(ctx, phone, "105377", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := b.store.AddOTPAttempt(ctx, phone)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.OTP != "105377" || data.Attempts != 1 {
+			t.Errorf("got %+v after a new OTP, want OTP 105377 with 1 attempt", data)
+		}
+	})
+}
+
+func TestOTPVerifiesOnce(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+		phone := "+919800000002"
+
+		if b.mock != nil {
+			expectSaveOTP(b.mock, phone, "482913")
+			expectDeleteOTP(b.mock, phone, true)
+			expectDeleteOTP(b.mock, phone, false)
+			expectNoOTP(b.mock, phone)
+		}
+
+		if err := b.store.SaveOTP(ctx, phone, "482913", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+
+		if deleted, err := b.store.DeleteOTP(ctx, phone); err != nil || !deleted {
+			t.Fatalf("first delete: got %v, %v, want true", deleted, err)
+		}
+
+		if deleted, err := b.store.DeleteOTP(ctx, phone); err != nil || deleted {
+			t.Errorf("second delete: got %v, %v, want false", deleted, err)
+		}
+
+		if _, err := b.store.AddOTPAttempt(ctx, phone); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("attempt after delete: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestOTPCooldown(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+		phone := "+919800000003"
+
+		if b.mock != nil {
+			key := keyPrefixOTPCooldown + phone
+			expectCountLimit(b.mock, key, 1)
+			expectCountLimit(b.mock, key, 2)
+			b.mock.ExpectQuery("SELECT expires_at FROM customer_limits WHERE limit_key = ?").
+				WithArgs(key).
+				WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(time.Now().Add(50 * time.Second)))
+			b.mock.ExpectExec("DELETE FROM customer_limits WHERE limit_key = ?").
+				WithArgs(key).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectCountLimit(b.mock, key, 1)
+		}
+
+		if left, err := b.store.StartOTPCooldown(ctx, phone, time.Minute); err != nil || left != 0 {
+			t.Fatalf("first start: got %v, %v, want a new cooldown", left, err)
+		}
+
+		left, err := b.store.StartOTPCooldown(ctx, phone, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if left <= 0 || left > time.Minute {
+			t.Errorf("second start: got %v left, want the running cooldown", left)
+		}
+
+		if err := b.store.ClearOTPCooldown(ctx, phone); err != nil {
+			t.Fatal(err)
+		}
+
+		if left, err := b.store.StartOTPCooldown(ctx, phone, time.Minute); err != nil || left != 0 {
+			t.Errorf("start after clear: got %v, %v, want a new cooldown", left, err)
+		}
+	})
+}
+
+func TestOTPRequestsAreCountedPerPhone(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+
+		if b.mock != nil {
+			for count := 1; count <= 3; count++ {
+				expectCountLimit(b.mock, keyPrefixOTPRate+"+919800000004", count)
+			}
+			expectCountLimit(b.mock, keyPrefixOTPRate+"+919800000005", 1)
+		}
+
+		for want := 1; want <= 3; want++ {
+			count, err := b.store.CountOTPRequest(ctx, "+919800000004", time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != want {
+				t.Errorf("got count %d, want %d", count, want)
+			}
+		}
+
+		if count, err := b.store.CountOTPRequest(ctx, "+919800000005", time.Hour); err != nil || count != 1 {
+			t.Errorf("another phone: got %d, %v, want 1", count, err)
+		}
+	})
+}
+
+// TestConcurrentOTPRequestsAreAllCounted checks that no request is lost or
+// counted twice when they arrive together
+func TestConcurrentOTPRequestsAreAllCounted(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+
+		const requests = 20
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			counts []int
+		)
+
+		// The mock hands out the counts in the order the requests reach it
+		if b.mock != nil {
+			for count := 1; count <= requests; count++ {
+				expectCountLimit(b.mock, keyPrefixOTPRate+"+919800000006", count)
+			}
+		}
+
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				count, err := b.store.CountOTPRequest(ctx, "+919800000006", time.Hour)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				mu.Lock()
+				counts = append(counts, count)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		sort.Ints(counts)
+		for i, count := range counts {
+			if count != i+1 {
+				t.Fatalf("got counts %v, want 1 to %d", counts, requests)
+			}
+		}
+	})
+}
+
+func TestCustomerStateExpires(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+		phone := "+919800000007"
+		ttl := time.Second
+
+		// The mock's rows expire when it says so
+		if b.mock != nil {
+			expectSaveOTP(b.mock, phone, "482913")
+			expectCountLimit(b.mock, keyPrefixOTPCooldown+phone, 1)
+			expectCountLimit(b.mock, keyPrefixOTPRate+phone, 1)
+			expectCountLimit(b.mock, keyPrefixOTPRate+phone, 2)
+
+			expectNoOTP(b.mock, phone)
+			expectDeleteOTP(b.mock, phone, false)
+			expectWindowRestart(b.mock, keyPrefixOTPCooldown+phone)
+			expectWindowRestart(b.mock, keyPrefixOTPRate+phone)
+		}
+
+		if err := b.store.SaveOTP(ctx, phone, "482913", ttl); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := b.store.StartOTPCooldown(ctx, phone, ttl); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := b.store.CountOTPRequest(ctx, phone, ttl); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		b.expire(ttl + 100*time.Millisecond)
+
+		if _, err := b.store.AddOTPAttempt(ctx, phone); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("attempt on an expired OTP: got %v, want sql.ErrNoRows", err)
+		}
+
+		if deleted, err := b.store.DeleteOTP(ctx, phone); err != nil || deleted {
+			t.Errorf("delete of an expired OTP: got %v, %v, want false", deleted, err)
+		}
+
+		if left, err := b.store.StartOTPCooldown(ctx, phone, ttl); err != nil || left != 0 {
+			t.Errorf("cooldown after expiry: got %v, %v, want a new cooldown", left, err)
+		}
+
+		if count, err := b.store.CountOTPRequest(ctx, phone, ttl); err != nil || count != 1 {
+			t.Errorf("count after the window: got %d, %v, want a new window", count, err)
+		}
+	})
+}
+
+func TestCustomerSessions(t *testing.T) {
+	forEachCustomerState(t, func(t *testing.T, b customerStateBackend) {
+		ctx := b.ctx
+		phone := "+919800000008"
+		customerID := b.customer(phone)
+
+		if b.mock != nil {
+			b.mock.ExpectExec(testCreateSession).
+				WithArgs("token-hash", customerID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSession(b.mock, "token-hash", customerID, phone)
+			b.mock.ExpectExec(testSessionUsed).
+				WithArgs(sqlmock.AnyArg(), "token-hash").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSession(b.mock, "other-hash", 0, "")
+			b.mock.ExpectExec("DELETE FROM customer_tokens WHERE token_hash = ?").
+				WithArgs("token-hash").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSession(b.mock, "token-hash", 0, "")
+		}
+
+		session := &model.CustomerSession{
+			CustomerID:  customerID,
+			PhoneNumber: phone,
+			Verified:    true,
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+
+		if err := b.store.CreateSession(ctx, "token-hash", session); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := b.store.GetSession(ctx, "token-hash")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.CustomerID != customerID || got.PhoneNumber != phone || !got.Verified {
+			t.Errorf("got %+v, want the verified session of customer %d", got, customerID)
+		}
+
+		if _, err := b.store.GetSession(ctx, "other-hash"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("unknown token: got %v, want sql.ErrNoRows", err)
+		}
+
+		if err := b.store.DeleteSession(ctx, "token-hash"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := b.store.GetSession(ctx, "token-hash"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("deleted session: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+// TestOTPRateWindowAlwaysExpires checks that the counter is created with
+// its window, so a failure between two commands cannot leave a phone
+// limited for good
+func TestOTPRateWindowAlwaysExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	ctx, _ := newTestContext(t)
+	s := NewCustomerStateRedis(rdb)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.CountOTPRequest(ctx, "+919800000009", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ttl := mr.TTL(keyPrefixOTPRate + "+919800000009"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("got TTL %v, want the one hour window", ttl)
+	}
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Notification struct{}
+
+func NewNotification() *Notification {
+	return &Notification{}
+}
+
+const notificationColumns = "id, restaurant_id, order_id, template, channel, recipient, sender, subject, body, status, attempts, last_error, next_attempt_at, expires_at, sent_at, dead_at, created_at, updated_at"
+
+func (s *Notification) Create(ctx *gofr.Context, n *model.Notification) error {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO notification_outbox (restaurant_id, order_id, template, channel, recipient, sender, subject, body, status, next_attempt_at, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		n.RestaurantID, n.OrderID, n.Template, n.Channel, n.Recipient, n.Sender, n.Subject, n.Body, model.NotificationPending, now, n.ExpiresAt, now, now)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	n.ID = int(id)
+	n.Status = model.NotificationPending
+	n.NextAttemptAt = now
+	n.CreatedAt = now
+	n.UpdatedAt = now
+
+	return nil
+}
+
+// GetByRestaurant returns the restaurant's latest messages, optionally only
+// those in status
+func (s *Notification) GetByRestaurant(ctx *gofr.Context, restaurantID int, status string, limit int) ([]model.Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notification_outbox WHERE restaurant_id = ?"
+	args := []interface{}{restaurantID}
+
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := ctx.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+func (s *Notification) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Notification, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+notificationColumns+" FROM notification_outbox WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanNotifications(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+// Claim hands up to limit notifications that are due, or whose worker
+// stopped before reporting back since claimedBefore, to one worker. The
+// claim is a single UPDATE so concurrent workers never send the same
+// message; token identifies the claimed rows afterwards.
+func (s *Notification) Claim(ctx *gofr.Context, token string, claimedBefore time.Time, limit int) ([]model.Notification, error) {
+	now := time.Now()
+
+	_, err := ctx.SQL.ExecContext(ctx,
+		`UPDATE notification_outbox SET status = ?, claim_token = ?, claimed_at = ?, attempts = attempts + 1
+		WHERE (status = ? AND next_attempt_at <= ?) OR (status = ? AND claimed_at < ?)
+		ORDER BY next_attempt_at LIMIT ?`,
+		model.NotificationSending, token, now, model.NotificationPending, now, model.NotificationSending, claimedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+notificationColumns+" FROM notification_outbox WHERE claim_token = ? ORDER BY id", token)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+// MarkSent records a delivered message, dropping its body when clearBody is
+// set. It reports false when the claim identified by token has since been
+// taken over by another worker.
+func (s *Notification) MarkSent(ctx *gofr.Context, id int, token string, sentAt time.Time, clearBody bool) (bool, error) {
+	return s.release(ctx,
+		"UPDATE notification_outbox SET status = ?, last_error = NULL, sent_at = ?, body = IF(?, '', body), claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?",
+		model.NotificationSent, sentAt, clearBody, time.Now(), id, token)
+}
+
+// MarkRetry puts a message whose attempt failed back in the queue, unless
+// its claim has been taken over
+func (s *Notification) MarkRetry(ctx *gofr.Context, id int, token, lastError string, nextAttemptAt time.Time) (bool, error) {
+	return s.release(ctx,
+		"UPDATE notification_outbox SET status = ?, last_error = ?, next_attempt_at = ?, claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?",
+		model.NotificationPending, lastError, nextAttemptAt, time.Now(), id, token)
+}
+
+// MarkDead dead-letters a message that will not be retried, dropping its
+// body when clearBody is set, unless its claim has been taken over
+func (s *Notification) MarkDead(ctx *gofr.Context, id int, token, lastError string, deadAt time.Time, clearBody bool) (bool, error) {
+	return s.release(ctx,
+		"UPDATE notification_outbox SET status = ?, last_error = ?, dead_at = ?, body = IF(?, '', body), claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?",
+		model.NotificationDead, lastError, deadAt, clearBody, time.Now(), id, token)
+}
+
+// release applies a claimed message's outcome, reporting whether the claim
+// was still held
+func (s *Notification) release(ctx *gofr.Context, query string, args ...interface{}) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+// Requeue gives a dead-lettered message that has not expired and still has
+// its body a fresh set of attempts, reporting false when there is no such
+// message
+func (s *Notification) Requeue(ctx *gofr.Context, restaurantID, id int) (bool, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE notification_outbox SET status = ?, attempts = 0, dead_at = NULL, next_attempt_at = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND body <> '' AND (expires_at IS NULL OR expires_at > ?)",
+		model.NotificationPending, now, now, id, restaurantID, model.NotificationDead, now)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+func scanNotifications(rows rowScanner) ([]model.Notification, error) {
+	list := []model.Notification{}
+	for rows.Next() {
+		var n model.Notification
+		var orderID sql.NullInt64
+		var lastError sql.NullString
+		var expiresAt, sentAt, deadAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.RestaurantID, &orderID, &n.Template, &n.Channel, &n.Recipient, &n.Sender, &n.Subject, &n.Body,
+			&n.Status, &n.Attempts, &lastError, &n.NextAttemptAt, &expiresAt, &sentAt, &deadAt, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if orderID.Valid {
+			id := int(orderID.Int64)
+			n.OrderID = &id
+		}
+
+		if expiresAt.Valid {
+			n.ExpiresAt = &expiresAt.Time
+		}
+
+		if sentAt.Valid {
+			n.SentAt = &sentAt.Time
+		}
+
+		if deadAt.Valid {
+			n.DeadAt = &deadAt.Time
+		}
+
+		n.LastError = lastError.String
+		list = append(list, n)
+	}
+
+	return list, nil
+}
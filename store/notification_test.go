@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+)
+
+func newTestContext(t *testing.T) (*gofr.Context, sqlmock.Sqlmock) {
+	t.Helper()
+
+	c, mocks := container.NewMockContainer(t)
+
+	return &gofr.Context{Context: context.Background(), Container: c}, mocks.SQL
+}
+
+// TestOutcomesNeedTheClaim checks that a worker whose claim was taken over
+// cannot overwrite what the new claimant records
+func TestOutcomesNeedTheClaim(t *testing.T) {
+	ctx, mock := newTestContext(t)
+	s := NewNotification()
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE notification_outbox SET status = ?, last_error = NULL, sent_at = ?, body = IF(?, '', body), claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?").
+		WithArgs("sent", now, false, sqlmock.AnyArg(), 7, "old-claim").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE notification_outbox SET status = ?, last_error = ?, next_attempt_at = ?, claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?").
+		WithArgs("pending", "timeout", now, sqlmock.AnyArg(), 7, "old-claim").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE notification_outbox SET status = ?, last_error = ?, dead_at = ?, body = IF(?, '', body), claim_token = NULL, updated_at = ? WHERE id = ? AND claim_token = ?").
+		WithArgs("dead", "expired", now, true, sqlmock.AnyArg(), 7, "new-claim").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if held, err := s.MarkSent(ctx, 7, "old-claim", now, false); err != nil || held {
+		t.Errorf("MarkSent with a lost claim: held %v, err %v", held, err)
+	}
+
+	if held, err := s.MarkRetry(ctx, 7, "old-claim", "timeout", now); err != nil || held {
+		t.Errorf("MarkRetry with a lost claim: held %v, err %v", held, err)
+	}
+
+	if held, err := s.MarkDead(ctx, 7, "new-claim", "expired", now, true); err != nil || !held {
+		t.Errorf("MarkDead with the claim: held %v, err %v", held, err)
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type OptionGroup struct{}
+
+func NewOptionGroup() *OptionGroup {
+	return &OptionGroup{}
+}
+
+const optionGroupSelect = `SELECT g.id, g.restaurant_id, g.product_id, g.name, g.selection_type, g.min_select, g.max_select, g.sort_order, g.created_at, g.updated_at,
+  o.id, o.name, o.price_delta, o.available, o.sort_order
+FROM product_option_groups g
+LEFT JOIN product_options o ON o.group_id = g.id
+WHERE g.restaurant_id = ?`
+
+const optionGroupOrder = " ORDER BY g.product_id ASC, g.sort_order ASC, g.id ASC, o.sort_order ASC, o.id ASC"
+
+// GetByRestaurant returns every option group of the restaurant keyed by product ID
+func (s *OptionGroup) GetByRestaurant(ctx *gofr.Context, restaurantID int) (map[int][]model.OptionGroup, error) {
+	rows, err := ctx.SQL.QueryContext(ctx, optionGroupSelect+optionGroupOrder, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOptionGroups(rows)
+}
+
+// GetByProductIDs returns the option groups of the given products keyed by product ID
+func (s *OptionGroup) GetByProductIDs(ctx *gofr.Context, restaurantID int, productIDs []int) (map[int][]model.OptionGroup, error) {
+	if len(productIDs) == 0 {
+		return map[int][]model.OptionGroup{}, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range productIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		optionGroupSelect+" AND g.product_id IN ("+placeholders+")"+optionGroupOrder,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOptionGroups(rows)
+}
+
+func (s *OptionGroup) GetByID(ctx *gofr.Context, restaurantID, productID, id int) (*model.OptionGroup, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		optionGroupSelect+" AND g.product_id = ? AND g.id = ?"+optionGroupOrder,
+		restaurantID, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups, err := scanOptionGroups(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups[productID]) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &groups[productID][0], nil
+}
+
+func (s *OptionGroup) Create(ctx *gofr.Context, g *model.OptionGroup) (*model.OptionGroup, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO product_option_groups (restaurant_id, product_id, name, selection_type, min_select, max_select, sort_order, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		g.RestaurantID, g.ProductID, g.Name, g.SelectionType, g.MinSelect, g.MaxSelect, g.SortOrder, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	g.ID = int(id)
+
+	for i := range g.Options {
+		if err := s.insertOption(ctx, g.ID, &g.Options[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetByID(ctx, g.RestaurantID, g.ProductID, g.ID)
+}
+
+// Update replaces the group's fields and synchronises its options: options
+// with a known ID are updated, options without an ID are added and the rest
+// are removed.
+func (s *OptionGroup) Update(ctx *gofr.Context, restaurantID, productID, id int, g *model.OptionGroup) (*model.OptionGroup, error) {
+	existing, err := s.GetByID(ctx, restaurantID, productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ctx.SQL.ExecContext(ctx,
+		"UPDATE product_option_groups SET name = ?, selection_type = ?, min_select = ?, max_select = ?, sort_order = ?, updated_at = ? WHERE id = ? AND product_id = ? AND restaurant_id = ?",
+		g.Name, g.SelectionType, g.MinSelect, g.MaxSelect, g.SortOrder, time.Now(), id, productID, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[int]bool)
+	for i := range g.Options {
+		opt := &g.Options[i]
+		if opt.ID == 0 {
+			if err := s.insertOption(ctx, id, opt); err != nil {
+				return nil, err
+			}
+			kept[opt.ID] = true
+			continue
+		}
+
+		_, err := ctx.SQL.ExecContext(ctx,
+			"UPDATE product_options SET name = ?, price_delta = ?, available = ?, sort_order = ? WHERE id = ? AND group_id = ?",
+			opt.Name, opt.PriceDelta, opt.Available, opt.SortOrder, opt.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		kept[opt.ID] = true
+	}
+
+	for _, opt := range existing.Options {
+		if kept[opt.ID] {
+			continue
+		}
+
+		if _, err := ctx.SQL.ExecContext(ctx, "DELETE FROM product_options WHERE id = ? AND group_id = ?", opt.ID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetByID(ctx, restaurantID, productID, id)
+}
+
+func (s *OptionGroup) Delete(ctx *gofr.Context, restaurantID, productID, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"DELETE FROM product_option_groups WHERE id = ? AND product_id = ? AND restaurant_id = ?",
+		id, productID, restaurantID)
+	return err
+}
+
+func (s *OptionGroup) insertOption(ctx *gofr.Context, groupID int, opt *model.Option) error {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO product_options (group_id, name, price_delta, available, sort_order) VALUES (?, ?, ?, ?, ?)",
+		groupID, opt.Name, opt.PriceDelta, opt.Available, opt.SortOrder)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	opt.ID = int(id)
+	opt.GroupID = groupID
+
+	return nil
+}
+
+// scanOptionGroups folds the joined group/option rows into groups keyed by product ID
+func scanOptionGroups(rows productRows) (map[int][]model.OptionGroup, error) {
+	result := make(map[int][]model.OptionGroup)
+
+	for rows.Next() {
+		var g model.OptionGroup
+		var optID sql.NullInt64
+		var optName sql.NullString
+		var optPriceDelta sql.NullFloat64
+		var optAvailable sql.NullBool
+		var optSortOrder sql.NullInt64
+
+		if err := rows.Scan(&g.ID, &g.RestaurantID, &g.ProductID, &g.Name, &g.SelectionType, &g.MinSelect, &g.MaxSelect, &g.SortOrder, &g.CreatedAt, &g.UpdatedAt,
+			&optID, &optName, &optPriceDelta, &optAvailable, &optSortOrder); err != nil {
+			return nil, err
+		}
+
+		groups := result[g.ProductID]
+		if len(groups) == 0 || groups[len(groups)-1].ID != g.ID {
+			g.Options = []model.Option{}
+			groups = append(groups, g)
+		}
+
+		if optID.Valid {
+			last := &groups[len(groups)-1]
+			last.Options = append(last.Options, model.Option{
+				ID:         int(optID.Int64),
+				GroupID:    g.ID,
+				Name:       optName.String,
+				PriceDelta: optPriceDelta.Float64,
+				Available:  optAvailable.Bool,
+				SortOrder:  int(optSortOrder.Int64),
+			})
+		}
+
+		result[g.ProductID] = groups
+	}
+
+	return result, nil
+}
@@ -11,13 +11,15 @@ import (
 
 type Order struct{}
 
+const orderColumns = "id, restaurant_id, table_id, table_number, tab_id, customer_mobile, customer_name, customer_id, items, status, payment_status, special_instructions, total, bill, assigned_chef_id, estimated_ready_at, cancellation, created_at, updated_at"
+
 func NewOrder() *Order {
 	return &Order{}
 }
 
 func (s *Order) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Order, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, table_number, customer_mobile, customer_name, items, status, special_instructions, total, assigned_chef_id, estimated_ready_at, created_at, updated_at FROM orders WHERE restaurant_id = ? ORDER BY created_at DESC",
+		"SELECT "+orderColumns+" FROM orders WHERE restaurant_id = ? ORDER BY created_at DESC",
 		restaurantID)
 	if err != nil {
 		return nil, err
@@ -29,7 +31,7 @@ func (s *Order) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Order, erro
 
 func (s *Order) GetByStatus(ctx *gofr.Context, restaurantID int, status string) ([]model.Order, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, table_number, customer_mobile, customer_name, items, status, special_instructions, total, assigned_chef_id, estimated_ready_at, created_at, updated_at FROM orders WHERE restaurant_id = ? AND status = ? ORDER BY created_at DESC",
+		"SELECT "+orderColumns+" FROM orders WHERE restaurant_id = ? AND status = ? ORDER BY created_at DESC",
 		restaurantID, status)
 	if err != nil {
 		return nil, err
@@ -41,7 +43,7 @@ func (s *Order) GetByStatus(ctx *gofr.Context, restaurantID int, status string)
 
 func (s *Order) GetByPhone(ctx *gofr.Context, restaurantID int, phone string) ([]model.Order, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, table_number, customer_mobile, customer_name, items, status, special_instructions, total, assigned_chef_id, estimated_ready_at, created_at, updated_at FROM orders WHERE restaurant_id = ? AND customer_mobile = ? ORDER BY created_at DESC",
+		"SELECT "+orderColumns+" FROM orders WHERE restaurant_id = ? AND customer_mobile = ? ORDER BY created_at DESC",
 		restaurantID, phone)
 	if err != nil {
 		return nil, err
@@ -51,25 +53,70 @@ func (s *Order) GetByPhone(ctx *gofr.Context, restaurantID int, phone string) ([
 	return scanOrders(rows)
 }
 
+// GetHistory returns a customer's latest orders across all restaurants
+func (s *Order) GetHistory(ctx *gofr.Context, phone string, limit int) ([]model.Order, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+orderColumns+" FROM orders WHERE customer_mobile = ? ORDER BY created_at DESC LIMIT ?",
+		phone, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+func (s *Order) GetByTab(ctx *gofr.Context, restaurantID, tabID int) ([]model.Order, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+orderColumns+" FROM orders WHERE restaurant_id = ? AND tab_id = ? ORDER BY created_at ASC",
+		restaurantID, tabID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
 func (s *Order) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Order, error) {
 	var o model.Order
 	var itemsJSON []byte
+	var billJSON []byte
+	var cancellationJSON []byte
+	var tableID sql.NullInt64
 	var tableNumber sql.NullString
+	var tabID sql.NullInt64
+	var customerID sql.NullInt64
 	var chefID sql.NullInt64
 	var estimatedReadyAt sql.NullTime
 
 	err := ctx.SQL.QueryRowContext(ctx,
-		"SELECT id, restaurant_id, table_number, customer_mobile, customer_name, items, status, special_instructions, total, assigned_chef_id, estimated_ready_at, created_at, updated_at FROM orders WHERE id = ? AND restaurant_id = ?",
+		"SELECT "+orderColumns+" FROM orders WHERE id = ? AND restaurant_id = ?",
 		id, restaurantID).
-		Scan(&o.ID, &o.RestaurantID, &tableNumber, &o.CustomerMobile, &o.CustomerName, &itemsJSON, &o.Status, &o.SpecialInstructions, &o.Total, &chefID, &estimatedReadyAt, &o.CreatedAt, &o.UpdatedAt)
+		Scan(&o.ID, &o.RestaurantID, &tableID, &tableNumber, &tabID, &o.CustomerMobile, &o.CustomerName, &customerID, &itemsJSON, &o.Status, &o.PaymentStatus, &o.SpecialInstructions, &o.Total, &billJSON, &chefID, &estimatedReadyAt, &cancellationJSON, &o.CreatedAt, &o.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if tableID.Valid {
+		id := int(tableID.Int64)
+		o.TableID = &id
+	}
+
 	if tableNumber.Valid {
 		o.TableNumber = &tableNumber.String
 	}
 
+	if tabID.Valid {
+		id := int(tabID.Int64)
+		o.TabID = &id
+	}
+
+	if customerID.Valid {
+		id := int(customerID.Int64)
+		o.CustomerID = &id
+	}
+
 	if chefID.Valid {
 		id := int(chefID.Int64)
 		o.AssignedChefID = &id
@@ -82,6 +129,19 @@ func (s *Order) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Order,
 	if err := json.Unmarshal(itemsJSON, &o.Items); err != nil {
 		return nil, err
 	}
+	backfillItems(&o)
+
+	if len(billJSON) > 0 {
+		if err := json.Unmarshal(billJSON, &o.Bill); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cancellationJSON) > 0 {
+		if err := json.Unmarshal(cancellationJSON, &o.Cancellation); err != nil {
+			return nil, err
+		}
+	}
 
 	return &o, nil
 }
@@ -94,9 +154,19 @@ func (s *Order) Create(ctx *gofr.Context, o *model.Order) (*model.Order, error)
 		return nil, err
 	}
 
+	var billJSON *string
+	if o.Bill != nil {
+		data, err := json.Marshal(o.Bill)
+		if err != nil {
+			return nil, err
+		}
+		str := string(data)
+		billJSON = &str
+	}
+
 	result, err := ctx.SQL.ExecContext(ctx,
-		"INSERT INTO orders (restaurant_id, table_number, customer_mobile, customer_name, items, status, special_instructions, total, assigned_chef_id, estimated_ready_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		o.RestaurantID, o.TableNumber, o.CustomerMobile, o.CustomerName, string(itemsJSON), o.Status, o.SpecialInstructions, o.Total, o.AssignedChefID, o.EstimatedReadyAt, now, now)
+		"INSERT INTO orders (restaurant_id, table_id, table_number, tab_id, customer_mobile, customer_name, customer_id, items, status, payment_status, special_instructions, total, bill, assigned_chef_id, estimated_ready_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		o.RestaurantID, o.TableID, o.TableNumber, o.TabID, o.CustomerMobile, o.CustomerName, o.CustomerID, string(itemsJSON), o.Status, o.PaymentStatus, o.SpecialInstructions, o.Total, billJSON, o.AssignedChefID, o.EstimatedReadyAt, now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -125,69 +195,58 @@ func (s *Order) Update(ctx *gofr.Context, restaurantID, id int, setClauses []str
 	return s.GetByID(ctx, restaurantID, id)
 }
 
-func joinClauses(clauses []string) string {
-	result := ""
-	for i, c := range clauses {
-		if i > 0 {
-			result += ", "
-		}
-		result += c
-	}
-	return result
-}
+// UpdateFrom applies an update only while the order still has the status and
+// payment status it was read with. It reports false, without the order, when
+// another change got there first.
+func (s *Order) UpdateFrom(ctx *gofr.Context, restaurantID, id int, status, paymentStatus string, setClauses []string, args []interface{}) (*model.Order, bool, error) {
+	now := time.Now()
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, now)
 
-func (s *Order) GetChefLoads(ctx *gofr.Context, restaurantID int) ([]model.ChefLoad, error) {
-	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT assigned_chef_id, COUNT(*) FROM orders WHERE restaurant_id = ? AND status IN ('pending','preparing') AND assigned_chef_id IS NOT NULL GROUP BY assigned_chef_id",
-		restaurantID)
+	query := "UPDATE orders SET " + joinClauses(setClauses) + " WHERE id = ? AND restaurant_id = ? AND status = ? AND payment_status = ?"
+	args = append(args, id, restaurantID, status, paymentStatus)
+
+	result, err := ctx.SQL.ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	defer rows.Close()
 
-	var loads []model.ChefLoad
-	for rows.Next() {
-		var cl model.ChefLoad
-		if err := rows.Scan(&cl.ChefID, &cl.OrderCount); err != nil {
-			return nil, err
-		}
-		loads = append(loads, cl)
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, false, nil
+	}
+
+	o, err := s.GetByID(ctx, restaurantID, id)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return loads, nil
+	return o, true, nil
 }
 
-func (s *Order) GetLeastRecentlyAssignedChef(ctx *gofr.Context, restaurantID int, chefIDs []int) (*int, error) {
-	if len(chefIDs) == 0 {
-		return nil, nil
+// SetTab puts the order on a tab while the tab is still open. It reports
+// false when the tab was closed first.
+func (s *Order) SetTab(ctx *gofr.Context, restaurantID, id, tabID int) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE orders SET tab_id = ?, updated_at = ? WHERE id = ? AND restaurant_id = ? AND EXISTS (SELECT 1 FROM table_tabs WHERE id = ? AND status = ?)",
+		tabID, time.Now(), id, restaurantID, tabID, model.TabOpen)
+	if err != nil {
+		return false, err
 	}
 
-	placeholders := ""
-	args := []interface{}{restaurantID}
-	for i, id := range chefIDs {
-		if i > 0 {
-			placeholders += ","
-		}
-		placeholders += "?"
-		args = append(args, id)
-	}
+	n, _ := result.RowsAffected()
 
-	query := `SELECT s.id FROM staff s
-LEFT JOIN (
-  SELECT assigned_chef_id, MAX(created_at) as last_assigned
-  FROM orders WHERE restaurant_id = ? AND assigned_chef_id IS NOT NULL
-  GROUP BY assigned_chef_id
-) o ON s.id = o.assigned_chef_id
-WHERE s.id IN (` + placeholders + `)
-ORDER BY o.last_assigned ASC, s.id ASC LIMIT 1`
+	return n > 0, nil
+}
 
-	var chefID int
-	err := ctx.SQL.QueryRowContext(ctx, query, args...).Scan(&chefID)
-	if err != nil {
-		return nil, err
+func joinClauses(clauses []string) string {
+	result := ""
+	for i, c := range clauses {
+		if i > 0 {
+			result += ", "
+		}
+		result += c
 	}
-
-	return &chefID, nil
+	return result
 }
 
 func (s *Order) GetChefActiveOrderCount(ctx *gofr.Context, chefID int) (int, error) {
@@ -207,29 +266,75 @@ func (s *Order) Delete(ctx *gofr.Context, restaurantID, id int) error {
 	return err
 }
 
-type orderRows interface {
+// backfillItems gives items stored before per-item tracking a line ID and a
+// status consistent with their order
+func backfillItems(o *model.Order) {
+	for i := range o.Items {
+		if o.Items[i].LineID == 0 {
+			o.Items[i].LineID = i + 1
+		}
+
+		if o.Items[i].Status != "" {
+			continue
+		}
+
+		switch o.Status {
+		case "preparing":
+			o.Items[i].Status = model.ItemCooking
+		case "completed":
+			o.Items[i].Status = model.ItemServed
+		case "cancelled":
+			o.Items[i].Status = model.ItemVoided
+		default:
+			o.Items[i].Status = model.ItemQueued
+		}
+	}
+}
+
+// rowScanner is the part of *sql.Rows the scan helpers use
+type rowScanner interface {
 	Next() bool
 	Scan(dest ...interface{}) error
 }
 
-func scanOrders(rows orderRows) ([]model.Order, error) {
+func scanOrders(rows rowScanner) ([]model.Order, error) {
 	var list []model.Order
 
 	for rows.Next() {
 		var o model.Order
 		var itemsJSON []byte
+		var billJSON []byte
+		var cancellationJSON []byte
+		var tableID sql.NullInt64
 		var tableNumber sql.NullString
+		var tabID sql.NullInt64
+		var customerID sql.NullInt64
 		var chefID sql.NullInt64
 		var estimatedReadyAt sql.NullTime
 
-		if err := rows.Scan(&o.ID, &o.RestaurantID, &tableNumber, &o.CustomerMobile, &o.CustomerName, &itemsJSON, &o.Status, &o.SpecialInstructions, &o.Total, &chefID, &estimatedReadyAt, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		if err := rows.Scan(&o.ID, &o.RestaurantID, &tableID, &tableNumber, &tabID, &o.CustomerMobile, &o.CustomerName, &customerID, &itemsJSON, &o.Status, &o.PaymentStatus, &o.SpecialInstructions, &o.Total, &billJSON, &chefID, &estimatedReadyAt, &cancellationJSON, &o.CreatedAt, &o.UpdatedAt); err != nil {
 			return nil, err
 		}
 
+		if tableID.Valid {
+			id := int(tableID.Int64)
+			o.TableID = &id
+		}
+
 		if tableNumber.Valid {
 			o.TableNumber = &tableNumber.String
 		}
 
+		if tabID.Valid {
+			id := int(tabID.Int64)
+			o.TabID = &id
+		}
+
+		if customerID.Valid {
+			id := int(customerID.Int64)
+			o.CustomerID = &id
+		}
+
 		if chefID.Valid {
 			id := int(chefID.Int64)
 			o.AssignedChefID = &id
@@ -242,6 +347,19 @@ func scanOrders(rows orderRows) ([]model.Order, error) {
 		if err := json.Unmarshal(itemsJSON, &o.Items); err != nil {
 			return nil, err
 		}
+		backfillItems(&o)
+
+		if len(billJSON) > 0 {
+			if err := json.Unmarshal(billJSON, &o.Bill); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(cancellationJSON) > 0 {
+			if err := json.Unmarshal(cancellationJSON, &o.Cancellation); err != nil {
+				return nil, err
+			}
+		}
 
 		list = append(list, o)
 	}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Payment struct{}
+
+func NewPayment() *Payment {
+	return &Payment{}
+}
+
+const paymentColumns = "id, restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at"
+
+func (s *Payment) GetByOrder(ctx *gofr.Context, restaurantID, orderID int) ([]model.Payment, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE restaurant_id = ? AND order_id = ? ORDER BY created_at DESC, id DESC",
+		restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Payment
+	for rows.Next() {
+		var p model.Payment
+		if err := rows.Scan(&p.ID, &p.RestaurantID, &p.OrderID, &p.Provider, &p.ProviderOrderID, &p.ProviderPaymentID, &p.Amount, &p.RefundedAmount, &p.Currency, &p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+
+	if list == nil {
+		list = []model.Payment{}
+	}
+
+	return list, nil
+}
+
+func (s *Payment) GetByID(ctx *gofr.Context, id int) (*model.Payment, error) {
+	var p model.Payment
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE id = ?", id).
+		Scan(&p.ID, &p.RestaurantID, &p.OrderID, &p.Provider, &p.ProviderOrderID, &p.ProviderPaymentID, &p.Amount, &p.RefundedAmount, &p.Currency, &p.Status, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s *Payment) GetByProviderOrderID(ctx *gofr.Context, provider, providerOrderID string) (*model.Payment, error) {
+	var p model.Payment
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE provider = ? AND provider_order_id = ?",
+		provider, providerOrderID).
+		Scan(&p.ID, &p.RestaurantID, &p.OrderID, &p.Provider, &p.ProviderOrderID, &p.ProviderPaymentID, &p.Amount, &p.RefundedAmount, &p.Currency, &p.Status, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s *Payment) Create(ctx *gofr.Context, p *model.Payment) (*model.Payment, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO payments (restaurant_id, order_id, provider, provider_order_id, provider_payment_id, amount, refunded_amount, currency, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.RestaurantID, p.OrderID, p.Provider, p.ProviderOrderID, p.ProviderPaymentID, p.Amount, p.RefundedAmount, p.Currency, p.Status, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	p.ID = int(id)
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	return p, nil
+}
+
+// SetProviderOrderID records the gateway's order reference once the intent exists
+func (s *Payment) SetProviderOrderID(ctx *gofr.Context, id int, providerOrderID string) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE payments SET provider_order_id = ?, updated_at = ? WHERE id = ?",
+		providerOrderID, time.Now(), id)
+	return err
+}
+
+// UpdateStatus moves a payment from one status to another. It reports false
+// when the payment was no longer in the expected status (e.g. a replayed webhook).
+func (s *Payment) UpdateStatus(ctx *gofr.Context, id int, from, to, providerPaymentID string) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE payments SET status = ?, provider_payment_id = ?, updated_at = ? WHERE id = ? AND status = ?",
+		to, providerPaymentID, time.Now(), id, from)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+// Capture records the amount the gateway captured on a payment still in
+// status from. It reports false when the payment had already moved on.
+func (s *Payment) Capture(ctx *gofr.Context, id int, from, providerPaymentID string, amount float64) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE payments SET status = ?, provider_payment_id = ?, amount = ?, updated_at = ? WHERE id = ? AND status = ?",
+		model.PaymentRecordCaptured, providerPaymentID, amount, time.Now(), id, from)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+// AddRefund increases the refunded amount, refusing to exceed the captured amount
+func (s *Payment) AddRefund(ctx *gofr.Context, id int, amount float64) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE payments SET refunded_amount = refunded_amount + ?, updated_at = ? WHERE id = ? AND status = ? AND refunded_amount + ? <= amount",
+		amount, time.Now(), id, model.PaymentRecordCaptured, amount)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type PlatformUser struct{}
+
+func NewPlatformUser() *PlatformUser {
+	return &PlatformUser{}
+}
+
+func (s *PlatformUser) GetAll(ctx *gofr.Context) ([]model.PlatformUser, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, username, totp_enabled, active, created_at, updated_at FROM platform_users ORDER BY username ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PlatformUser
+	for rows.Next() {
+		var u model.PlatformUser
+		if err := rows.Scan(&u.ID, &u.Username, &u.TOTPEnabled, &u.Active, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+
+	if list == nil {
+		list = []model.PlatformUser{}
+	}
+
+	return list, nil
+}
+
+func (s *PlatformUser) GetByID(ctx *gofr.Context, id int) (*model.PlatformUser, error) {
+	var u model.PlatformUser
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT id, username, password_hash, totp_secret, totp_enabled, active, created_at, updated_at FROM platform_users WHERE id = ?",
+		id).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.TOTPSecret, &u.TOTPEnabled, &u.Active, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (s *PlatformUser) GetByUsername(ctx *gofr.Context, username string) (*model.PlatformUser, error) {
+	var u model.PlatformUser
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT id, username, password_hash, totp_secret, totp_enabled, active, created_at, updated_at FROM platform_users WHERE username = ?",
+		username).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.TOTPSecret, &u.TOTPEnabled, &u.Active, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (s *PlatformUser) Count(ctx *gofr.Context) (int, error) {
+	var count int
+	err := ctx.SQL.QueryRowContext(ctx, "SELECT COUNT(*) FROM platform_users").Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Create inserts a user; u.PasswordHash must already be set
+func (s *PlatformUser) Create(ctx *gofr.Context, u *model.PlatformUser) (*model.PlatformUser, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO platform_users (username, password_hash, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		u.Username, u.PasswordHash, u.Active, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	u.ID = int(id)
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	u.Password = "" // Don't return password
+
+	return u, nil
+}
+
+// Update saves username and active flag, and the password hash when set
+func (s *PlatformUser) Update(ctx *gofr.Context, id int, u *model.PlatformUser) (*model.PlatformUser, error) {
+	now := time.Now()
+
+	if u.PasswordHash != "" {
+		_, err := ctx.SQL.ExecContext(ctx,
+			"UPDATE platform_users SET username = ?, password_hash = ?, active = ?, updated_at = ? WHERE id = ?",
+			u.Username, u.PasswordHash, u.Active, now, id)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, err := ctx.SQL.ExecContext(ctx,
+			"UPDATE platform_users SET username = ?, active = ?, updated_at = ? WHERE id = ?",
+			u.Username, u.Active, now, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// SetTOTP stores the shared secret and whether it is required at login
+func (s *PlatformUser) SetTOTP(ctx *gofr.Context, id int, secret string, enabled bool) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE platform_users SET totp_secret = ?, totp_enabled = ?, updated_at = ? WHERE id = ?",
+		secret, enabled, time.Now(), id)
+	return err
+}
+
+func (s *PlatformUser) Delete(ctx *gofr.Context, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM platform_users WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type PrepTime struct{}
+
+func NewPrepTime() *PrepTime {
+	return &PrepTime{}
+}
+
+func (s *PrepTime) AddSample(ctx *gofr.Context, p *model.PrepSample) error {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO prep_samples (restaurant_id, order_id, line_id, product_id, chef_id, quantity, estimated_seconds, actual_seconds, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.RestaurantID, p.OrderID, p.LineID, p.ProductID, p.ChefID, p.Quantity, p.EstimatedSeconds, p.ActualSeconds, now)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	p.ID = int(id)
+	p.CreatedAt = now
+
+	return nil
+}
+
+// GetRecentDurations returns the actual seconds of the product's latest
+// samples, newest first, optionally only those cooked by chefID
+func (s *PrepTime) GetRecentDurations(ctx *gofr.Context, restaurantID, productID int, chefID *int, limit int) ([]int, error) {
+	query := "SELECT actual_seconds FROM prep_samples WHERE restaurant_id = ? AND product_id = ?"
+	args := []interface{}{restaurantID, productID}
+
+	if chefID != nil {
+		query += " AND chef_id = ?"
+		args = append(args, *chefID)
+	}
+
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := ctx.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var durations []int
+	for rows.Next() {
+		var d int
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+
+	return durations, nil
+}
+
+// GetSamples returns the samples recorded between from and to
+func (s *PrepTime) GetSamples(ctx *gofr.Context, restaurantID int, from, to time.Time) ([]model.PrepSample, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, order_id, line_id, product_id, chef_id, quantity, estimated_seconds, actual_seconds, created_at FROM prep_samples WHERE restaurant_id = ? AND created_at >= ? AND created_at < ? ORDER BY created_at ASC",
+		restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PrepSample
+	for rows.Next() {
+		var p model.PrepSample
+		var chefID sql.NullInt64
+		if err := rows.Scan(&p.ID, &p.RestaurantID, &p.OrderID, &p.LineID, &p.ProductID, &chefID, &p.Quantity, &p.EstimatedSeconds, &p.ActualSeconds, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if chefID.Valid {
+			id := int(chefID.Int64)
+			p.ChefID = &id
+		}
+
+		list = append(list, p)
+	}
+
+	if list == nil {
+		list = []model.PrepSample{}
+	}
+
+	return list, nil
+}
+
+// SaveStat stores a product's rolling statistics, replacing the previous ones
+func (s *PrepTime) SaveStat(ctx *gofr.Context, restaurantID int, st model.PrepStat) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO prep_stats (restaurant_id, product_id, chef_id, samples, median_seconds, p90_seconds, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE samples = VALUES(samples), median_seconds = VALUES(median_seconds), p90_seconds = VALUES(p90_seconds), updated_at = VALUES(updated_at)",
+		restaurantID, st.ProductID, st.ChefID, st.Samples, st.MedianSeconds, st.P90Seconds, time.Now())
+	return err
+}
+
+// GetStats returns the statistics of the given products, overall and per chef
+func (s *PrepTime) GetStats(ctx *gofr.Context, restaurantID int, productIDs []int) ([]model.PrepStat, error) {
+	if len(productIDs) == 0 {
+		return []model.PrepStat{}, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range productIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT product_id, chef_id, samples, median_seconds, p90_seconds FROM prep_stats WHERE restaurant_id = ? AND product_id IN ("+placeholders+")",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PrepStat
+	for rows.Next() {
+		var st model.PrepStat
+		if err := rows.Scan(&st.ProductID, &st.ChefID, &st.Samples, &st.MedianSeconds, &st.P90Seconds); err != nil {
+			return nil, err
+		}
+		list = append(list, st)
+	}
+
+	if list == nil {
+		list = []model.PrepStat{}
+	}
+
+	return list, nil
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Print struct{}
+
+func NewPrint() *Print {
+	return &Print{}
+}
+
+const printJobColumns = "id, restaurant_id, order_id, station_id, kind, format, width, status, attempts, last_error, agent_id, next_attempt_at, claimed_at, printed_at, created_at, updated_at"
+
+func (s *Print) CreateJob(ctx *gofr.Context, j *model.PrintJob) error {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO print_jobs (restaurant_id, order_id, station_id, kind, format, width, status, next_attempt_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		j.RestaurantID, j.OrderID, j.StationID, j.Kind, j.Format, j.Width, model.PrintPending, now, now, now)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	j.ID = int(id)
+	j.Status = model.PrintPending
+	j.NextAttemptAt = now
+	j.CreatedAt = now
+	j.UpdatedAt = now
+
+	return nil
+}
+
+// GetJobs returns the restaurant's latest jobs, optionally only those in status
+func (s *Print) GetJobs(ctx *gofr.Context, restaurantID int, status string, limit int) ([]model.PrintJob, error) {
+	query := "SELECT " + printJobColumns + " FROM print_jobs WHERE restaurant_id = ?"
+	args := []interface{}{restaurantID}
+
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := ctx.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPrintJobs(rows)
+}
+
+func (s *Print) GetJob(ctx *gofr.Context, restaurantID, id int) (*model.PrintJob, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+printJobColumns+" FROM print_jobs WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanPrintJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+// ExpireClaims fails jobs whose agent stopped responding after their last
+// allowed attempt. Jobs with attempts left are picked up again by ClaimJobs.
+func (s *Print) ExpireClaims(ctx *gofr.Context, restaurantID int, claimedBefore time.Time, maxAttempts int) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE print_jobs SET status = ?, last_error = ?, claim_token = NULL WHERE restaurant_id = ? AND status = ? AND claimed_at < ? AND attempts >= ?",
+		model.PrintFailed, "print agent did not report back", restaurantID, model.PrintClaimed, claimedBefore, maxAttempts)
+
+	return err
+}
+
+// ClaimJobs hands up to limit jobs that are due, or whose previous claim
+// went unanswered since claimedBefore, to an agent. The claim is a single
+// UPDATE so concurrent agents never receive the same job; token identifies
+// the claimed rows afterwards.
+func (s *Print) ClaimJobs(ctx *gofr.Context, restaurantID, agentID int, token string, claimedBefore time.Time, limit int) ([]model.PrintJob, error) {
+	now := time.Now()
+
+	_, err := ctx.SQL.ExecContext(ctx,
+		`UPDATE print_jobs SET status = ?, agent_id = ?, claim_token = ?, claimed_at = ?, attempts = attempts + 1
+		WHERE restaurant_id = ? AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND claimed_at < ?))
+		ORDER BY id LIMIT ?`,
+		model.PrintClaimed, agentID, token, now, restaurantID, model.PrintPending, now, model.PrintClaimed, claimedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+printJobColumns+" FROM print_jobs WHERE claim_token = ? ORDER BY id", token)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPrintJobs(rows)
+}
+
+// FinishAttempt records the outcome of a claimed job. It only applies to
+// jobs still claimed by agentID and reports false otherwise.
+func (s *Print) FinishAttempt(ctx *gofr.Context, restaurantID, id, agentID int, status, lastError string, nextAttemptAt time.Time, printedAt *time.Time) (bool, error) {
+	var errValue interface{}
+	if lastError != "" {
+		errValue = lastError
+	}
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE print_jobs SET status = ?, last_error = ?, next_attempt_at = ?, printed_at = ?, claim_token = NULL WHERE id = ? AND restaurant_id = ? AND agent_id = ? AND status = ?",
+		status, errValue, nextAttemptAt, printedAt, id, restaurantID, agentID, model.PrintClaimed)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+// Requeue puts a failed job back in the queue with a fresh set of attempts
+func (s *Print) Requeue(ctx *gofr.Context, restaurantID, id int) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE print_jobs SET status = ?, attempts = 0, agent_id = NULL, next_attempt_at = ? WHERE id = ? AND restaurant_id = ? AND status = ?",
+		model.PrintPending, time.Now(), id, restaurantID, model.PrintFailed)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+func scanPrintJobs(rows rowScanner) ([]model.PrintJob, error) {
+	var list []model.PrintJob
+	for rows.Next() {
+		var j model.PrintJob
+		var stationID, agentID sql.NullInt64
+		var lastError sql.NullString
+		var claimedAt, printedAt sql.NullTime
+		if err := rows.Scan(&j.ID, &j.RestaurantID, &j.OrderID, &stationID, &j.Kind, &j.Format, &j.Width, &j.Status, &j.Attempts,
+			&lastError, &agentID, &j.NextAttemptAt, &claimedAt, &printedAt, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if stationID.Valid {
+			id := int(stationID.Int64)
+			j.StationID = &id
+		}
+
+		if agentID.Valid {
+			id := int(agentID.Int64)
+			j.AgentID = &id
+		}
+
+		if claimedAt.Valid {
+			j.ClaimedAt = &claimedAt.Time
+		}
+
+		if printedAt.Valid {
+			j.PrintedAt = &printedAt.Time
+		}
+
+		j.LastError = lastError.String
+		list = append(list, j)
+	}
+
+	if list == nil {
+		list = []model.PrintJob{}
+	}
+
+	return list, nil
+}
+
+func (s *Print) CreateAgent(ctx *gofr.Context, a *model.PrintAgent, keyHash string) error {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO print_agents (restaurant_id, name, key_hash, active, created_at) VALUES (?, ?, ?, ?, ?)",
+		a.RestaurantID, a.Name, keyHash, true, now)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	a.ID = int(id)
+	a.Active = true
+	a.CreatedAt = now
+
+	return nil
+}
+
+const printAgentColumns = "id, restaurant_id, name, active, last_seen_at, created_at"
+
+func (s *Print) GetAgents(ctx *gofr.Context, restaurantID int) ([]model.PrintAgent, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+printAgentColumns+" FROM print_agents WHERE restaurant_id = ? ORDER BY id",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPrintAgents(rows)
+}
+
+func (s *Print) GetAgentByKeyHash(ctx *gofr.Context, keyHash string) (*model.PrintAgent, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+printAgentColumns+" FROM print_agents WHERE key_hash = ?", keyHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanPrintAgents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+// HasActiveAgent reports whether anyone is listening for the restaurant's jobs
+func (s *Print) HasActiveAgent(ctx *gofr.Context, restaurantID int) (bool, error) {
+	var n int
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM print_agents WHERE restaurant_id = ? AND active = TRUE", restaurantID).Scan(&n)
+
+	return n > 0, err
+}
+
+func (s *Print) TouchAgent(ctx *gofr.Context, id int, at time.Time) error {
+	_, err := ctx.SQL.ExecContext(ctx, "UPDATE print_agents SET last_seen_at = ? WHERE id = ?", at, id)
+	return err
+}
+
+func (s *Print) DeleteAgent(ctx *gofr.Context, restaurantID, id int) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx, "DELETE FROM print_agents WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+func scanPrintAgents(rows rowScanner) ([]model.PrintAgent, error) {
+	list := []model.PrintAgent{}
+	for rows.Next() {
+		var a model.PrintAgent
+		var lastSeenAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.RestaurantID, &a.Name, &a.Active, &lastSeenAt, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if lastSeenAt.Valid {
+			a.LastSeenAt = &lastSeenAt.Time
+		}
+
+		list = append(list, a)
+	}
+
+	return list, nil
+}
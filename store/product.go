@@ -1,6 +1,8 @@
 package store
 
 import (
+	"database/sql"
+	"encoding/json"
 	"qr-dinein-backend/model"
 	"time"
 
@@ -15,7 +17,7 @@ func NewProduct() *Product {
 
 func (s *Product) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Product, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, created_at, updated_at FROM products WHERE restaurant_id = ? ORDER BY id ASC",
+		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, station_id, skill_tags, created_at, updated_at FROM products WHERE restaurant_id = ? ORDER BY id ASC",
 		restaurantID)
 	if err != nil {
 		return nil, err
@@ -27,7 +29,7 @@ func (s *Product) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Product,
 
 func (s *Product) GetByCategory(ctx *gofr.Context, restaurantID, categoryID int) ([]model.Product, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, created_at, updated_at FROM products WHERE restaurant_id = ? AND category_id = ? ORDER BY id ASC",
+		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, station_id, skill_tags, created_at, updated_at FROM products WHERE restaurant_id = ? AND category_id = ? ORDER BY id ASC",
 		restaurantID, categoryID)
 	if err != nil {
 		return nil, err
@@ -39,23 +41,39 @@ func (s *Product) GetByCategory(ctx *gofr.Context, restaurantID, categoryID int)
 
 func (s *Product) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Product, error) {
 	var p model.Product
+	var stationID sql.NullInt64
+	var skillTags []byte
 	err := ctx.SQL.QueryRowContext(ctx,
-		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, created_at, updated_at FROM products WHERE id = ? AND restaurant_id = ?",
+		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, station_id, skill_tags, created_at, updated_at FROM products WHERE id = ? AND restaurant_id = ?",
 		id, restaurantID).
-		Scan(&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description, &p.Price, &p.Image, &p.Veg, &p.Available, &p.PrepTime, &p.CreatedAt, &p.UpdatedAt)
+		Scan(&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description, &p.Price, &p.Image, &p.Veg, &p.Available, &p.PrepTime, &stationID, &skillTags, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if stationID.Valid {
+		id := int(stationID.Int64)
+		p.StationID = &id
+	}
+
+	if p.SkillTags, err = decodeTags(skillTags); err != nil {
+		return nil, err
+	}
+
 	return &p, nil
 }
 
 func (s *Product) Create(ctx *gofr.Context, p *model.Product) (*model.Product, error) {
 	now := time.Now()
 
+	skillTags, err := json.Marshal(p.SkillTags)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := ctx.SQL.ExecContext(ctx,
-		"INSERT INTO products (restaurant_id, category_id, name, description, price, image, veg, available, prep_time, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		p.RestaurantID, p.CategoryID, p.Name, p.Description, p.Price, p.Image, p.Veg, p.Available, p.PrepTime, now, now)
+		"INSERT INTO products (restaurant_id, category_id, name, description, price, image, veg, available, prep_time, station_id, skill_tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.RestaurantID, p.CategoryID, p.Name, p.Description, p.Price, p.Image, p.Veg, p.Available, p.PrepTime, p.StationID, string(skillTags), now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -71,9 +89,14 @@ func (s *Product) Create(ctx *gofr.Context, p *model.Product) (*model.Product, e
 func (s *Product) Update(ctx *gofr.Context, restaurantID, id int, p *model.Product) (*model.Product, error) {
 	now := time.Now()
 
-	_, err := ctx.SQL.ExecContext(ctx,
-		"UPDATE products SET category_id = ?, name = ?, description = ?, price = ?, image = ?, veg = ?, available = ?, prep_time = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
-		p.CategoryID, p.Name, p.Description, p.Price, p.Image, p.Veg, p.Available, p.PrepTime, now, id, restaurantID)
+	skillTags, err := json.Marshal(p.SkillTags)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ctx.SQL.ExecContext(ctx,
+		"UPDATE products SET category_id = ?, name = ?, description = ?, price = ?, image = ?, veg = ?, available = ?, prep_time = ?, station_id = ?, skill_tags = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+		p.CategoryID, p.Name, p.Description, p.Price, p.Image, p.Veg, p.Available, p.PrepTime, p.StationID, string(skillTags), now, id, restaurantID)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +108,42 @@ func (s *Product) Update(ctx *gofr.Context, restaurantID, id int, p *model.Produ
 	return p, nil
 }
 
+func (s *Product) GetByIDs(ctx *gofr.Context, restaurantID int, productIDs []int) (map[int]model.Product, error) {
+	if len(productIDs) == 0 {
+		return map[int]model.Product{}, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range productIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, category_id, name, description, price, image, veg, available, prep_time, station_id, skill_tags, created_at, updated_at FROM products WHERE restaurant_id = ? AND id IN ("+placeholders+")",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanProducts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]model.Product, len(list))
+	for _, p := range list {
+		result[p.ID] = p
+	}
+
+	return result, nil
+}
+
 func (s *Product) GetPrepTimes(ctx *gofr.Context, restaurantID int, productIDs []int) (map[int]int, error) {
 	if len(productIDs) == 0 {
 		return map[int]int{}, nil
@@ -120,6 +179,89 @@ func (s *Product) GetPrepTimes(ctx *gofr.Context, restaurantID int, productIDs [
 	return result, nil
 }
 
+// GetSkillTags returns the skill tags of each product that has any
+func (s *Product) GetSkillTags(ctx *gofr.Context, restaurantID int, productIDs []int) (map[int][]string, error) {
+	if len(productIDs) == 0 {
+		return map[int][]string{}, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range productIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, skill_tags FROM products WHERE restaurant_id = ? AND id IN ("+placeholders+") AND skill_tags IS NOT NULL",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+
+		tags, err := decodeTags(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tags) > 0 {
+			result[id] = tags
+		}
+	}
+
+	return result, nil
+}
+
+// GetStationRoutes returns the station each product is prepared at: its own
+// station, else its category's. Products with neither, or whose station is
+// inactive or belongs to another restaurant, are left out.
+func (s *Product) GetStationRoutes(ctx *gofr.Context, restaurantID int, productIDs []int) (map[int]int, error) {
+	if len(productIDs) == 0 {
+		return map[int]int{}, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range productIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT p.id, st.id FROM products p LEFT JOIN categories c ON c.id = p.category_id JOIN stations st ON st.id = COALESCE(p.station_id, c.station_id) AND st.restaurant_id = p.restaurant_id AND st.active = TRUE WHERE p.restaurant_id = ? AND p.id IN ("+placeholders+")",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int]int)
+	for rows.Next() {
+		var id, stationID int
+		if err := rows.Scan(&id, &stationID); err != nil {
+			return nil, err
+		}
+		result[id] = stationID
+	}
+
+	return result, nil
+}
+
 func (s *Product) Delete(ctx *gofr.Context, restaurantID, id int) error {
 	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM products WHERE id = ? AND restaurant_id = ?", id, restaurantID)
 	return err
@@ -134,9 +276,22 @@ func scanProducts(rows productRows) ([]model.Product, error) {
 	var list []model.Product
 	for rows.Next() {
 		var p model.Product
-		if err := rows.Scan(&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description, &p.Price, &p.Image, &p.Veg, &p.Available, &p.PrepTime, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		var stationID sql.NullInt64
+		var skillTags []byte
+		if err := rows.Scan(&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description, &p.Price, &p.Image, &p.Veg, &p.Available, &p.PrepTime, &stationID, &skillTags, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
+
+		if stationID.Valid {
+			id := int(stationID.Int64)
+			p.StationID = &id
+		}
+
+		var err error
+		if p.SkillTags, err = decodeTags(skillTags); err != nil {
+			return nil, err
+		}
+
 		list = append(list, p)
 	}
 
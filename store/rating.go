@@ -1,6 +1,7 @@
 package store
 
 import (
+	"math"
 	"qr-dinein-backend/model"
 	"time"
 
@@ -51,6 +52,37 @@ func (s *Rating) GetAllByRestaurant(ctx *gofr.Context, restaurantID int) ([]mode
 	return list, nil
 }
 
+// GetSummary counts the restaurant's ratings per star value, leaving out
+// ratings on orders that were cancelled
+func (s *Rating) GetSummary(ctx *gofr.Context, restaurantID int) (*model.RatingSummary, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT r.rating, COUNT(*) FROM order_ratings r JOIN orders o ON o.id = r.order_id WHERE r.restaurant_id = ? AND o.status <> 'cancelled' GROUP BY r.rating",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &model.RatingSummary{Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
+	total := 0
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return nil, err
+		}
+
+		summary.Distribution[rating] = count
+		summary.Count += count
+		total += rating * count
+	}
+
+	if summary.Count > 0 {
+		summary.Average = math.Round(float64(total)/float64(summary.Count)*100) / 100
+	}
+
+	return summary, nil
+}
+
 func (s *Rating) Create(ctx *gofr.Context, r *model.Rating) (*model.Rating, error) {
 	now := time.Now()
 
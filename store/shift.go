@@ -0,0 +1,81 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Shift struct{}
+
+func NewShift() *Shift {
+	return &Shift{}
+}
+
+func (s *Shift) GetByStaff(ctx *gofr.Context, restaurantID, staffID int) ([]model.Shift, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, staff_id, weekday, start_time, end_time FROM staff_shifts WHERE restaurant_id = ? AND staff_id = ? ORDER BY weekday ASC, start_time ASC",
+		restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanShifts(rows)
+}
+
+func (s *Shift) GetByRestaurant(ctx *gofr.Context, restaurantID int) ([]model.Shift, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, staff_id, weekday, start_time, end_time FROM staff_shifts WHERE restaurant_id = ? ORDER BY staff_id ASC, weekday ASC, start_time ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanShifts(rows)
+}
+
+// ReplaceForStaff swaps a staff member's weekly schedule for shifts
+func (s *Shift) ReplaceForStaff(ctx *gofr.Context, restaurantID, staffID int, shifts []model.Shift) ([]model.Shift, error) {
+	if _, err := ctx.SQL.ExecContext(ctx,
+		"DELETE FROM staff_shifts WHERE restaurant_id = ? AND staff_id = ?",
+		restaurantID, staffID); err != nil {
+		return nil, err
+	}
+
+	for i := range shifts {
+		sh := &shifts[i]
+
+		result, err := ctx.SQL.ExecContext(ctx,
+			"INSERT INTO staff_shifts (restaurant_id, staff_id, weekday, start_time, end_time) VALUES (?, ?, ?, ?, ?)",
+			restaurantID, staffID, sh.Weekday, sh.StartTime, sh.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := result.LastInsertId()
+		sh.ID = int(id)
+		sh.RestaurantID = restaurantID
+		sh.StaffID = staffID
+	}
+
+	return shifts, nil
+}
+
+func scanShifts(rows rowScanner) ([]model.Shift, error) {
+	var list []model.Shift
+	for rows.Next() {
+		var sh model.Shift
+		if err := rows.Scan(&sh.ID, &sh.RestaurantID, &sh.StaffID, &sh.Weekday, &sh.StartTime, &sh.EndTime); err != nil {
+			return nil, err
+		}
+		list = append(list, sh)
+	}
+
+	if list == nil {
+		list = []model.Shift{}
+	}
+
+	return list, nil
+}
@@ -1,6 +1,9 @@
 package store
 
 import (
+	"encoding/json"
+	"fmt"
+	"qr-dinein-backend/auth"
 	"qr-dinein-backend/model"
 	"time"
 
@@ -13,41 +16,35 @@ func NewStaff() *Staff {
 	return &Staff{}
 }
 
+const staffColumns = "s.id, s.restaurant_id, s.username, s.role, s.active, s.skills, s.max_tickets, s.created_at, s.updated_at"
+
 func (s *Staff) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Staff, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, username, role, active, created_at, updated_at FROM staff WHERE restaurant_id = ? ORDER BY username ASC",
+		"SELECT "+staffColumns+" FROM staff s WHERE s.restaurant_id = ? ORDER BY s.username ASC",
 		restaurantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var list []model.Staff
-	for rows.Next() {
-		var st model.Staff
-		if err := rows.Scan(&st.ID, &st.RestaurantID, &st.Username, &st.Role, &st.Active, &st.CreatedAt, &st.UpdatedAt); err != nil {
-			return nil, err
-		}
-		list = append(list, st)
-	}
-
-	if list == nil {
-		list = []model.Staff{}
-	}
-
-	return list, nil
+	return scanStaff(rows)
 }
 
 func (s *Staff) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Staff, error) {
 	var st model.Staff
+	var skills []byte
 	err := ctx.SQL.QueryRowContext(ctx,
-		"SELECT id, restaurant_id, username, role, active, created_at, updated_at FROM staff WHERE id = ? AND restaurant_id = ?",
+		"SELECT "+staffColumns+" FROM staff s WHERE s.id = ? AND s.restaurant_id = ?",
 		id, restaurantID).
-		Scan(&st.ID, &st.RestaurantID, &st.Username, &st.Role, &st.Active, &st.CreatedAt, &st.UpdatedAt)
+		Scan(&st.ID, &st.RestaurantID, &st.Username, &st.Role, &st.Active, &skills, &st.MaxTickets, &st.CreatedAt, &st.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if st.Skills, err = decodeTags(skills); err != nil {
+		return nil, err
+	}
+
 	return &st, nil
 }
 
@@ -71,9 +68,19 @@ func (s *Staff) GetByUsername(ctx *gofr.Context, username string) (*model.Staff,
 func (s *Staff) Create(ctx *gofr.Context, st *model.Staff) (*model.Staff, error) {
 	now := time.Now()
 
+	pinHash, err := auth.HashPin(st.Pin)
+	if err != nil {
+		return nil, err
+	}
+
+	skills, err := json.Marshal(st.Skills)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := ctx.SQL.ExecContext(ctx,
-		"INSERT INTO staff (restaurant_id, username, pin, role, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		st.RestaurantID, st.Username, st.Pin, st.Role, st.Active, now, now)
+		"INSERT INTO staff (restaurant_id, username, pin, role, active, skills, max_tickets, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		st.RestaurantID, st.Username, pinHash, st.Role, st.Active, string(skills), st.MaxTickets, now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -90,17 +97,27 @@ func (s *Staff) Create(ctx *gofr.Context, st *model.Staff) (*model.Staff, error)
 func (s *Staff) Update(ctx *gofr.Context, restaurantID, id int, st *model.Staff) (*model.Staff, error) {
 	now := time.Now()
 
+	skills, err := json.Marshal(st.Skills)
+	if err != nil {
+		return nil, err
+	}
+
 	if st.Pin != "" {
-		_, err := ctx.SQL.ExecContext(ctx,
-			"UPDATE staff SET username = ?, pin = ?, role = ?, active = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
-			st.Username, st.Pin, st.Role, st.Active, now, id, restaurantID)
+		pinHash, err := auth.HashPin(st.Pin)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = ctx.SQL.ExecContext(ctx,
+			"UPDATE staff SET username = ?, pin = ?, role = ?, active = ?, skills = ?, max_tickets = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+			st.Username, pinHash, st.Role, st.Active, string(skills), st.MaxTickets, now, id, restaurantID)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		_, err := ctx.SQL.ExecContext(ctx,
-			"UPDATE staff SET username = ?, role = ?, active = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
-			st.Username, st.Role, st.Active, now, id, restaurantID)
+			"UPDATE staff SET username = ?, role = ?, active = ?, skills = ?, max_tickets = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+			st.Username, st.Role, st.Active, string(skills), st.MaxTickets, now, id, restaurantID)
 		if err != nil {
 			return nil, err
 		}
@@ -114,28 +131,113 @@ func (s *Staff) Update(ctx *gofr.Context, restaurantID, id int, st *model.Staff)
 	return st, nil
 }
 
+// UpdatePinHash replaces a legacy plaintext PIN with its hash
+func (s *Staff) UpdatePinHash(ctx *gofr.Context, id int, pinHash string) error {
+	if !auth.IsPinHashed(pinHash) {
+		return fmt.Errorf("refusing to store an unhashed pin")
+	}
+
+	_, err := ctx.SQL.ExecContext(ctx, "UPDATE staff SET pin = ? WHERE id = ?", pinHash, id)
+	return err
+}
+
 func (s *Staff) GetActiveChefs(ctx *gofr.Context, restaurantID int) ([]model.Staff, error) {
 	rows, err := ctx.SQL.QueryContext(ctx,
-		"SELECT id, restaurant_id, username, role, active, created_at, updated_at FROM staff WHERE restaurant_id = ? AND role = 'chef' AND active = TRUE ORDER BY id ASC",
+		"SELECT "+staffColumns+" FROM staff s WHERE s.restaurant_id = ? AND s.role = 'chef' AND s.active = TRUE ORDER BY s.id ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStaff(rows)
+}
+
+// GetActiveChefsByStation returns the active chefs working the given station
+func (s *Staff) GetActiveChefsByStation(ctx *gofr.Context, restaurantID, stationID int) ([]model.Staff, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+staffColumns+" FROM staff s JOIN station_staff ss ON ss.staff_id = s.id WHERE s.restaurant_id = ? AND ss.station_id = ? AND s.role = 'chef' AND s.active = TRUE ORDER BY s.id ASC",
+		restaurantID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStaff(rows)
+}
+
+// onDutyJoin keeps chefs who are clocked in and not on a break
+const onDutyJoin = " JOIN staff_time_entries e ON e.staff_id = s.id AND e.clock_out_at IS NULL AND e.break_started_at IS NULL"
+
+// GetOnDutyChefs returns the active chefs currently clocked in and not on a break
+func (s *Staff) GetOnDutyChefs(ctx *gofr.Context, restaurantID int) ([]model.Staff, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+staffColumns+" FROM staff s"+onDutyJoin+" WHERE s.restaurant_id = ? AND s.role = 'chef' AND s.active = TRUE ORDER BY s.id ASC",
 		restaurantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanStaff(rows)
+}
+
+// GetOnDutyChefsByStation returns the station's active chefs currently clocked in and not on a break
+func (s *Staff) GetOnDutyChefsByStation(ctx *gofr.Context, restaurantID, stationID int) ([]model.Staff, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+staffColumns+" FROM staff s JOIN station_staff ss ON ss.staff_id = s.id"+onDutyJoin+" WHERE s.restaurant_id = ? AND ss.station_id = ? AND s.role = 'chef' AND s.active = TRUE ORDER BY s.id ASC",
+		restaurantID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStaff(rows)
+}
+
+func (s *Staff) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM staff WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	return err
+}
+
+func scanStaff(rows rowScanner) ([]model.Staff, error) {
 	var list []model.Staff
 	for rows.Next() {
 		var st model.Staff
-		if err := rows.Scan(&st.ID, &st.RestaurantID, &st.Username, &st.Role, &st.Active, &st.CreatedAt, &st.UpdatedAt); err != nil {
+		var skills []byte
+		if err := rows.Scan(&st.ID, &st.RestaurantID, &st.Username, &st.Role, &st.Active, &skills, &st.MaxTickets, &st.CreatedAt, &st.UpdatedAt); err != nil {
 			return nil, err
 		}
+
+		var err error
+		if st.Skills, err = decodeTags(skills); err != nil {
+			return nil, err
+		}
+
 		list = append(list, st)
 	}
 
+	if list == nil {
+		list = []model.Staff{}
+	}
+
 	return list, nil
 }
 
-func (s *Staff) Delete(ctx *gofr.Context, restaurantID, id int) error {
-	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM staff WHERE id = ? AND restaurant_id = ?", id, restaurantID)
-	return err
+// decodeTags reads a nullable JSON array of tags, treating NULL as no tags
+func decodeTags(data []byte) ([]string, error) {
+	tags := []string{}
+	if len(data) == 0 {
+		return tags, nil
+	}
+
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return tags, nil
 }
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Station struct{}
+
+func NewStation() *Station {
+	return &Station{}
+}
+
+func (s *Station) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Station, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, name, active, created_at, updated_at FROM stations WHERE restaurant_id = ? ORDER BY name ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Station
+	for rows.Next() {
+		var st model.Station
+		if err := rows.Scan(&st.ID, &st.RestaurantID, &st.Name, &st.Active, &st.CreatedAt, &st.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, st)
+	}
+
+	if list == nil {
+		list = []model.Station{}
+	}
+
+	chefs, err := s.getChefIDs(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		list[i].ChefIDs = chefs[list[i].ID]
+		if list[i].ChefIDs == nil {
+			list[i].ChefIDs = []int{}
+		}
+	}
+
+	return list, nil
+}
+
+func (s *Station) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Station, error) {
+	var st model.Station
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT id, restaurant_id, name, active, created_at, updated_at FROM stations WHERE id = ? AND restaurant_id = ?",
+		id, restaurantID).
+		Scan(&st.ID, &st.RestaurantID, &st.Name, &st.Active, &st.CreatedAt, &st.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	chefs, err := s.getChefIDs(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	st.ChefIDs = chefs[st.ID]
+	if st.ChefIDs == nil {
+		st.ChefIDs = []int{}
+	}
+
+	return &st, nil
+}
+
+func (s *Station) Create(ctx *gofr.Context, st *model.Station) (*model.Station, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO stations (restaurant_id, name, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		st.RestaurantID, st.Name, st.Active, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	st.ID = int(id)
+	st.ChefIDs = []int{}
+	st.CreatedAt = now
+	st.UpdatedAt = now
+
+	return st, nil
+}
+
+func (s *Station) Update(ctx *gofr.Context, restaurantID, id int, st *model.Station) (*model.Station, error) {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE stations SET name = ?, active = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+		st.Name, st.Active, time.Now(), id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, restaurantID, id)
+}
+
+func (s *Station) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM stations WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	return err
+}
+
+// SetChefs replaces the chefs working a station
+func (s *Station) SetChefs(ctx *gofr.Context, stationID int, chefIDs []int) error {
+	if _, err := ctx.SQL.ExecContext(ctx, "DELETE FROM station_staff WHERE station_id = ?", stationID); err != nil {
+		return err
+	}
+
+	for _, chefID := range chefIDs {
+		if _, err := ctx.SQL.ExecContext(ctx,
+			"INSERT INTO station_staff (station_id, staff_id) VALUES (?, ?)",
+			stationID, chefID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getChefIDs returns the chefs of every station in the restaurant keyed by station ID
+func (s *Station) getChefIDs(ctx *gofr.Context, restaurantID int) (map[int][]int, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT ss.station_id, ss.staff_id FROM station_staff ss JOIN stations st ON st.id = ss.station_id WHERE st.restaurant_id = ? ORDER BY ss.staff_id ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int][]int)
+	for rows.Next() {
+		var stationID, staffID int
+		if err := rows.Scan(&stationID, &staffID); err != nil {
+			return nil, err
+		}
+		result[stationID] = append(result[stationID], staffID)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"strconv"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Tab struct{}
+
+func NewTab() *Tab {
+	return &Tab{}
+}
+
+const tabColumns = "id, restaurant_id, table_id, table_number, status, opened_at, closed_at"
+
+func (s *Tab) GetAll(ctx *gofr.Context, restaurantID int, status string) ([]model.Tab, error) {
+	query := "SELECT " + tabColumns + " FROM table_tabs WHERE restaurant_id = ?"
+	args := []interface{}{restaurantID}
+
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	rows, err := ctx.SQL.QueryContext(ctx, query+" ORDER BY opened_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTabs(rows)
+}
+
+func (s *Tab) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Tab, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+tabColumns+" FROM table_tabs WHERE id = ? AND restaurant_id = ?",
+		id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanTabs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+// Open returns the table's open tab, opening one when there is none. Only
+// one tab per table can be open: open_table holds the table while the tab is
+// open and is cleared on close.
+func (s *Tab) Open(ctx *gofr.Context, restaurantID int, tableID *int, tableNumber string) (*model.Tab, error) {
+	key := "number:" + tableNumber
+	if tableID != nil {
+		key = "id:" + strconv.Itoa(*tableID)
+	}
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO table_tabs (restaurant_id, table_id, table_number, status, open_table, opened_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)",
+		restaurantID, tableID, tableNumber, model.TabOpen, key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+
+	return s.GetByID(ctx, restaurantID, int(id))
+}
+
+// Close closes an open tab that still has the orders it was checked with. It
+// reports false when the tab was already closed or an order joined it since.
+func (s *Tab) Close(ctx *gofr.Context, restaurantID, id, orders int, at time.Time) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE table_tabs SET status = ?, open_table = NULL, closed_at = ? WHERE id = ? AND restaurant_id = ? AND status = ? AND (SELECT COUNT(*) FROM orders WHERE tab_id = ?) = ?",
+		model.TabClosed, at, id, restaurantID, model.TabOpen, id, orders)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+
+	return n > 0, nil
+}
+
+func scanTabs(rows rowScanner) ([]model.Tab, error) {
+	var list []model.Tab
+	for rows.Next() {
+		var t model.Tab
+		var tableID sql.NullInt64
+		var closedAt sql.NullTime
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &tableID, &t.TableNumber, &t.Status, &t.OpenedAt, &closedAt); err != nil {
+			return nil, err
+		}
+
+		if tableID.Valid {
+			id := int(tableID.Int64)
+			t.TableID = &id
+		}
+
+		if closedAt.Valid {
+			t.ClosedAt = &closedAt.Time
+		}
+
+		list = append(list, t)
+	}
+
+	if list == nil {
+		list = []model.Tab{}
+	}
+
+	return list, nil
+}
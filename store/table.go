@@ -0,0 +1,92 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Table struct{}
+
+func NewTable() *Table {
+	return &Table{}
+}
+
+func (s *Table) GetAll(ctx *gofr.Context, restaurantID int) ([]model.Table, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, number, capacity, zone, active, created_at, updated_at FROM dining_tables WHERE restaurant_id = ? ORDER BY zone ASC, number ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Table
+	for rows.Next() {
+		var t model.Table
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.Number, &t.Capacity, &t.Zone, &t.Active, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	if list == nil {
+		list = []model.Table{}
+	}
+
+	return list, nil
+}
+
+func (s *Table) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.Table, error) {
+	var t model.Table
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT id, restaurant_id, number, capacity, zone, active, created_at, updated_at FROM dining_tables WHERE id = ? AND restaurant_id = ?",
+		id, restaurantID).
+		Scan(&t.ID, &t.RestaurantID, &t.Number, &t.Capacity, &t.Zone, &t.Active, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *Table) Create(ctx *gofr.Context, t *model.Table) (*model.Table, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO dining_tables (restaurant_id, number, capacity, zone, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		t.RestaurantID, t.Number, t.Capacity, t.Zone, t.Active, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	t.ID = int(id)
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, nil
+}
+
+func (s *Table) Update(ctx *gofr.Context, restaurantID, id int, t *model.Table) (*model.Table, error) {
+	now := time.Now()
+
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE dining_tables SET number = ?, capacity = ?, zone = ?, active = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+		t.Number, t.Capacity, t.Zone, t.Active, now, id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = id
+	t.RestaurantID = restaurantID
+	t.UpdatedAt = now
+
+	return t, nil
+}
+
+func (s *Table) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM dining_tables WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	return err
+}
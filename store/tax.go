@@ -0,0 +1,92 @@
+package store
+
+import (
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Tax struct{}
+
+func NewTax() *Tax {
+	return &Tax{}
+}
+
+func (s *Tax) GetAll(ctx *gofr.Context, restaurantID int) ([]model.TaxComponent, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT id, restaurant_id, name, rate, created_at, updated_at FROM tax_components WHERE restaurant_id = ? ORDER BY id ASC",
+		restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.TaxComponent
+	for rows.Next() {
+		var t model.TaxComponent
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.Name, &t.Rate, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	if list == nil {
+		list = []model.TaxComponent{}
+	}
+
+	return list, nil
+}
+
+func (s *Tax) GetByID(ctx *gofr.Context, restaurantID, id int) (*model.TaxComponent, error) {
+	var t model.TaxComponent
+	err := ctx.SQL.QueryRowContext(ctx,
+		"SELECT id, restaurant_id, name, rate, created_at, updated_at FROM tax_components WHERE id = ? AND restaurant_id = ?",
+		id, restaurantID).
+		Scan(&t.ID, &t.RestaurantID, &t.Name, &t.Rate, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *Tax) Create(ctx *gofr.Context, t *model.TaxComponent) (*model.TaxComponent, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO tax_components (restaurant_id, name, rate, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		t.RestaurantID, t.Name, t.Rate, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	t.ID = int(id)
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, nil
+}
+
+func (s *Tax) Update(ctx *gofr.Context, restaurantID, id int, t *model.TaxComponent) (*model.TaxComponent, error) {
+	now := time.Now()
+
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE tax_components SET name = ?, rate = ?, updated_at = ? WHERE id = ? AND restaurant_id = ?",
+		t.Name, t.Rate, now, id, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = id
+	t.RestaurantID = restaurantID
+	t.UpdatedAt = now
+
+	return t, nil
+}
+
+func (s *Tax) Delete(ctx *gofr.Context, restaurantID, id int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM tax_components WHERE id = ? AND restaurant_id = ?", id, restaurantID)
+	return err
+}
@@ -0,0 +1,273 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type Ticket struct{}
+
+func NewTicket() *Ticket {
+	return &Ticket{}
+}
+
+const ticketColumns = "id, restaurant_id, order_id, station_id, assigned_chef_id, status, line_ids, created_at, updated_at"
+
+// ticketItemColumns adds the order's items so a ticket can carry its own lines
+const ticketItemColumns = "t.id, t.restaurant_id, t.order_id, t.station_id, t.assigned_chef_id, t.status, t.line_ids, t.created_at, t.updated_at, o.items"
+
+func (s *Ticket) GetByOrder(ctx *gofr.Context, restaurantID, orderID int) ([]model.Ticket, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+ticketColumns+" FROM order_tickets WHERE restaurant_id = ? AND order_id = ? ORDER BY id ASC",
+		restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTickets(rows)
+}
+
+// GetOpenByStation returns the station's open tickets with their items, oldest first
+func (s *Ticket) GetOpenByStation(ctx *gofr.Context, restaurantID, stationID int) ([]model.Ticket, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+ticketItemColumns+" FROM order_tickets t JOIN orders o ON o.id = t.order_id WHERE t.restaurant_id = ? AND t.station_id = ? AND t.status = ? ORDER BY t.created_at ASC, t.id ASC",
+		restaurantID, stationID, model.TicketOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTicketsWithItems(rows)
+}
+
+// GetOpen returns every open ticket in the restaurant with its items, oldest first
+func (s *Ticket) GetOpen(ctx *gofr.Context, restaurantID int) ([]model.Ticket, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+ticketItemColumns+" FROM order_tickets t JOIN orders o ON o.id = t.order_id WHERE t.restaurant_id = ? AND t.status = ? ORDER BY t.created_at ASC, t.id ASC",
+		restaurantID, model.TicketOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTicketsWithItems(rows)
+}
+
+// GetOpenAssigned returns every open ticket held by a chef, with its items
+func (s *Ticket) GetOpenAssigned(ctx *gofr.Context, restaurantID int) ([]model.Ticket, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+ticketItemColumns+" FROM order_tickets t JOIN orders o ON o.id = t.order_id WHERE t.restaurant_id = ? AND t.status = ? AND t.assigned_chef_id IS NOT NULL",
+		restaurantID, model.TicketOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTicketsWithItems(rows)
+}
+
+func (s *Ticket) CreateAll(ctx *gofr.Context, orderID int, tickets []model.Ticket) ([]model.Ticket, error) {
+	now := time.Now()
+
+	for i := range tickets {
+		t := &tickets[i]
+
+		lineIDs, err := json.Marshal(t.LineIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := ctx.SQL.ExecContext(ctx,
+			"INSERT INTO order_tickets (restaurant_id, order_id, station_id, assigned_chef_id, status, line_ids, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			t.RestaurantID, orderID, t.StationID, t.AssignedChefID, t.Status, string(lineIDs), now, now)
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := result.LastInsertId()
+		t.ID = int(id)
+		t.OrderID = orderID
+		t.CreatedAt = now
+		t.UpdatedAt = now
+	}
+
+	return tickets, nil
+}
+
+func (s *Ticket) UpdateStatus(ctx *gofr.Context, id int, status string) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE order_tickets SET status = ?, updated_at = ? WHERE id = ?",
+		status, time.Now(), id)
+	return err
+}
+
+// SetChef reassigns an open ticket, returning false when it was closed meanwhile
+func (s *Ticket) SetChef(ctx *gofr.Context, id int, chefID *int) (bool, error) {
+	result, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE order_tickets SET assigned_chef_id = ?, updated_at = ? WHERE id = ? AND status = ?",
+		chefID, time.Now(), id, model.TicketOpen)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// AssignUnassigned gives every open ticket of the order without a chef to chefID
+func (s *Ticket) AssignUnassigned(ctx *gofr.Context, orderID, chefID int) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE order_tickets SET assigned_chef_id = ?, updated_at = ? WHERE order_id = ? AND assigned_chef_id IS NULL AND status = ?",
+		chefID, time.Now(), orderID, model.TicketOpen)
+	return err
+}
+
+func (s *Ticket) DeleteByOrder(ctx *gofr.Context, orderID int) error {
+	_, err := ctx.SQL.ExecContext(ctx, "DELETE FROM order_tickets WHERE order_id = ?", orderID)
+	return err
+}
+
+func (s *Ticket) GetChefLoads(ctx *gofr.Context, restaurantID int) ([]model.ChefLoad, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT assigned_chef_id, COUNT(*) FROM order_tickets WHERE restaurant_id = ? AND status = ? AND assigned_chef_id IS NOT NULL GROUP BY assigned_chef_id",
+		restaurantID, model.TicketOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loads []model.ChefLoad
+	for rows.Next() {
+		var cl model.ChefLoad
+		if err := rows.Scan(&cl.ChefID, &cl.OpenTickets); err != nil {
+			return nil, err
+		}
+		loads = append(loads, cl)
+	}
+
+	return loads, nil
+}
+
+func (s *Ticket) GetLeastRecentlyAssignedChef(ctx *gofr.Context, restaurantID int, chefIDs []int) (*int, error) {
+	if len(chefIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := ""
+	args := []interface{}{restaurantID}
+	for i, id := range chefIDs {
+		if i > 0 {
+			placeholders += ","
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+
+	query := `SELECT s.id FROM staff s
+LEFT JOIN (
+  SELECT assigned_chef_id, MAX(created_at) as last_assigned
+  FROM order_tickets WHERE restaurant_id = ? AND assigned_chef_id IS NOT NULL
+  GROUP BY assigned_chef_id
+) t ON s.id = t.assigned_chef_id
+WHERE s.id IN (` + placeholders + `)
+ORDER BY t.last_assigned ASC, s.id ASC LIMIT 1`
+
+	var chefID int
+	err := ctx.SQL.QueryRowContext(ctx, query, args...).Scan(&chefID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chefID, nil
+}
+
+func scanTickets(rows rowScanner) ([]model.Ticket, error) {
+	var list []model.Ticket
+	for rows.Next() {
+		var t model.Ticket
+		var stationID, chefID sql.NullInt64
+		var lineIDs []byte
+
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.OrderID, &stationID, &chefID, &t.Status, &lineIDs, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if stationID.Valid {
+			id := int(stationID.Int64)
+			t.StationID = &id
+		}
+
+		if chefID.Valid {
+			id := int(chefID.Int64)
+			t.AssignedChefID = &id
+		}
+
+		if err := json.Unmarshal(lineIDs, &t.LineIDs); err != nil {
+			return nil, err
+		}
+
+		list = append(list, t)
+	}
+
+	if list == nil {
+		list = []model.Ticket{}
+	}
+
+	return list, nil
+}
+
+func scanTicketsWithItems(rows rowScanner) ([]model.Ticket, error) {
+	var list []model.Ticket
+	for rows.Next() {
+		var t model.Ticket
+		var stationID, chefID sql.NullInt64
+		var lineIDs, itemsJSON []byte
+
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.OrderID, &stationID, &chefID, &t.Status, &lineIDs, &t.CreatedAt, &t.UpdatedAt, &itemsJSON); err != nil {
+			return nil, err
+		}
+
+		if stationID.Valid {
+			id := int(stationID.Int64)
+			t.StationID = &id
+		}
+
+		if chefID.Valid {
+			id := int(chefID.Int64)
+			t.AssignedChefID = &id
+		}
+
+		if err := json.Unmarshal(lineIDs, &t.LineIDs); err != nil {
+			return nil, err
+		}
+
+		var items []model.OrderItem
+		if err := json.Unmarshal(itemsJSON, &items); err != nil {
+			return nil, err
+		}
+
+		onTicket := make(map[int]bool, len(t.LineIDs))
+		for _, id := range t.LineIDs {
+			onTicket[id] = true
+		}
+
+		for _, item := range items {
+			if onTicket[item.LineID] {
+				t.Items = append(t.Items, item)
+			}
+		}
+
+		list = append(list, t)
+	}
+
+	if list == nil {
+		list = []model.Ticket{}
+	}
+
+	return list, nil
+}
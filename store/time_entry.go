@@ -0,0 +1,115 @@
+package store
+
+import (
+	"database/sql"
+	"qr-dinein-backend/model"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type TimeEntry struct{}
+
+func NewTimeEntry() *TimeEntry {
+	return &TimeEntry{}
+}
+
+const timeEntryColumns = "id, restaurant_id, staff_id, clock_in_at, clock_out_at, break_started_at, break_minutes"
+
+// GetOpen returns the staff member's current clocked-in period, or sql.ErrNoRows
+func (s *TimeEntry) GetOpen(ctx *gofr.Context, restaurantID, staffID int) (*model.TimeEntry, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+timeEntryColumns+" FROM staff_time_entries WHERE restaurant_id = ? AND staff_id = ? AND clock_out_at IS NULL ORDER BY clock_in_at DESC LIMIT 1",
+		restaurantID, staffID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanTimeEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &list[0], nil
+}
+
+// GetByRange returns the entries clocked in between from and to
+func (s *TimeEntry) GetByRange(ctx *gofr.Context, restaurantID int, from, to time.Time) ([]model.TimeEntry, error) {
+	rows, err := ctx.SQL.QueryContext(ctx,
+		"SELECT "+timeEntryColumns+" FROM staff_time_entries WHERE restaurant_id = ? AND clock_in_at >= ? AND clock_in_at < ? ORDER BY staff_id ASC, clock_in_at ASC",
+		restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTimeEntries(rows)
+}
+
+func (s *TimeEntry) ClockIn(ctx *gofr.Context, restaurantID, staffID int) (*model.TimeEntry, error) {
+	now := time.Now()
+
+	result, err := ctx.SQL.ExecContext(ctx,
+		"INSERT INTO staff_time_entries (restaurant_id, staff_id, clock_in_at, break_minutes) VALUES (?, ?, ?, 0)",
+		restaurantID, staffID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+
+	return &model.TimeEntry{ID: int(id), RestaurantID: restaurantID, StaffID: staffID, ClockInAt: now}, nil
+}
+
+func (s *TimeEntry) ClockOut(ctx *gofr.Context, id int, at time.Time, breakMinutes int) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE staff_time_entries SET clock_out_at = ?, break_started_at = NULL, break_minutes = ? WHERE id = ? AND clock_out_at IS NULL",
+		at, breakMinutes, id)
+	return err
+}
+
+func (s *TimeEntry) StartBreak(ctx *gofr.Context, id int, at time.Time) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE staff_time_entries SET break_started_at = ? WHERE id = ? AND clock_out_at IS NULL AND break_started_at IS NULL",
+		at, id)
+	return err
+}
+
+func (s *TimeEntry) EndBreak(ctx *gofr.Context, id int, breakMinutes int) error {
+	_, err := ctx.SQL.ExecContext(ctx,
+		"UPDATE staff_time_entries SET break_started_at = NULL, break_minutes = ? WHERE id = ? AND clock_out_at IS NULL",
+		breakMinutes, id)
+	return err
+}
+
+func scanTimeEntries(rows rowScanner) ([]model.TimeEntry, error) {
+	var list []model.TimeEntry
+	for rows.Next() {
+		var e model.TimeEntry
+		var clockOutAt, breakStartedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.RestaurantID, &e.StaffID, &e.ClockInAt, &clockOutAt, &breakStartedAt, &e.BreakMinutes); err != nil {
+			return nil, err
+		}
+
+		if clockOutAt.Valid {
+			e.ClockOutAt = &clockOutAt.Time
+		}
+
+		if breakStartedAt.Valid {
+			e.BreakStartedAt = &breakStartedAt.Time
+		}
+
+		list = append(list, e)
+	}
+
+	if list == nil {
+		list = []model.TimeEntry{}
+	}
+
+	return list, nil
+}
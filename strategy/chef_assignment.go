@@ -1,6 +1,11 @@
 package strategy
 
-import "gofr.dev/pkg/gofr"
+import (
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+
+	"gofr.dev/pkg/gofr"
+)
 
 const (
 	StrategyManual               = "manual"
@@ -8,10 +13,65 @@ const (
 	StrategyLeastLoaded          = "least_loaded"
 	StrategyRandom               = "random"
 	StrategyLeastRecentlyAssigned = "least_recently_assigned"
+	StrategySkillCapacity        = "skill_capacity"
 )
 
-// ChefAssigner assigns a chef to an order for a given restaurant.
+// AssignRequest describes the kitchen ticket a chef is being chosen for
+type AssignRequest struct {
+	RestaurantID int
+	StationID    *int              // nil when the items aren't routed to a station
+	Items        []model.OrderItem // the lines on the ticket
+	ExcludeChefs []int             // chefs not to assign, e.g. the one a stuck ticket is taken from
+}
+
+// ChefAssigner assigns a chef to a kitchen ticket.
 // Returns a pointer to the chef ID, or nil if no chef is available.
 type ChefAssigner interface {
-	Assign(ctx *gofr.Context, restaurantID int) (*int, error)
+	Assign(ctx *gofr.Context, req AssignRequest) (*int, error)
+}
+
+// ChefPool finds the chefs who may take a ticket: those working its station,
+// or every chef in the restaurant for unrouted items. With duty tracking on,
+// only chefs clocked in and not on a break are considered.
+type ChefPool struct {
+	staffStore   *store.Staff
+	dutyTracking bool
+}
+
+func NewChefPool(staffStore *store.Staff, dutyTracking bool) *ChefPool {
+	return &ChefPool{staffStore: staffStore, dutyTracking: dutyTracking}
+}
+
+func (p *ChefPool) Candidates(ctx *gofr.Context, req AssignRequest) ([]model.Staff, error) {
+	var chefs []model.Staff
+	var err error
+
+	switch {
+	case p.dutyTracking && req.StationID != nil:
+		chefs, err = p.staffStore.GetOnDutyChefsByStation(ctx, req.RestaurantID, *req.StationID)
+	case p.dutyTracking:
+		chefs, err = p.staffStore.GetOnDutyChefs(ctx, req.RestaurantID)
+	case req.StationID != nil:
+		chefs, err = p.staffStore.GetActiveChefsByStation(ctx, req.RestaurantID, *req.StationID)
+	default:
+		chefs, err = p.staffStore.GetActiveChefs(ctx, req.RestaurantID)
+	}
+
+	if err != nil || len(req.ExcludeChefs) == 0 {
+		return chefs, err
+	}
+
+	excluded := make(map[int]bool, len(req.ExcludeChefs))
+	for _, id := range req.ExcludeChefs {
+		excluded[id] = true
+	}
+
+	kept := make([]model.Staff, 0, len(chefs))
+	for _, chef := range chefs {
+		if !excluded[chef.ID] {
+			kept = append(kept, chef)
+		}
+	}
+
+	return kept, nil
 }
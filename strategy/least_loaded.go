@@ -8,16 +8,16 @@ import (
 )
 
 type LeastLoadedStrategy struct {
-	staffStore *store.Staff
-	orderStore *store.Order
+	chefs       *ChefPool
+	ticketStore *store.Ticket
 }
 
-func NewLeastLoaded(staffStore *store.Staff, orderStore *store.Order) *LeastLoadedStrategy {
-	return &LeastLoadedStrategy{staffStore: staffStore, orderStore: orderStore}
+func NewLeastLoaded(chefs *ChefPool, ticketStore *store.Ticket) *LeastLoadedStrategy {
+	return &LeastLoadedStrategy{chefs: chefs, ticketStore: ticketStore}
 }
 
-func (s *LeastLoadedStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int, error) {
-	chefs, err := s.staffStore.GetActiveChefs(ctx, restaurantID)
+func (s *LeastLoadedStrategy) Assign(ctx *gofr.Context, req AssignRequest) (*int, error) {
+	chefs, err := s.chefs.Candidates(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active chefs: %w", err)
 	}
@@ -26,7 +26,7 @@ func (s *LeastLoadedStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int,
 		return nil, nil
 	}
 
-	loads, err := s.orderStore.GetChefLoads(ctx, restaurantID)
+	loads, err := s.ticketStore.GetChefLoads(ctx, req.RestaurantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chef loads: %w", err)
 	}
@@ -34,7 +34,7 @@ func (s *LeastLoadedStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int,
 	// Build load map
 	loadMap := make(map[int]int)
 	for _, l := range loads {
-		loadMap[l.ChefID] = l.OrderCount
+		loadMap[l.ChefID] = l.OpenTickets
 	}
 
 	// Find chef with minimum load
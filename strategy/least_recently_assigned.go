@@ -8,16 +8,16 @@ import (
 )
 
 type LeastRecentlyAssignedStrategy struct {
-	staffStore *store.Staff
-	orderStore *store.Order
+	chefs       *ChefPool
+	ticketStore *store.Ticket
 }
 
-func NewLeastRecentlyAssigned(staffStore *store.Staff, orderStore *store.Order) *LeastRecentlyAssignedStrategy {
-	return &LeastRecentlyAssignedStrategy{staffStore: staffStore, orderStore: orderStore}
+func NewLeastRecentlyAssigned(chefs *ChefPool, ticketStore *store.Ticket) *LeastRecentlyAssignedStrategy {
+	return &LeastRecentlyAssignedStrategy{chefs: chefs, ticketStore: ticketStore}
 }
 
-func (s *LeastRecentlyAssignedStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int, error) {
-	chefs, err := s.staffStore.GetActiveChefs(ctx, restaurantID)
+func (s *LeastRecentlyAssignedStrategy) Assign(ctx *gofr.Context, req AssignRequest) (*int, error) {
+	chefs, err := s.chefs.Candidates(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active chefs: %w", err)
 	}
@@ -31,5 +31,5 @@ func (s *LeastRecentlyAssignedStrategy) Assign(ctx *gofr.Context, restaurantID i
 		chefIDs[i] = c.ID
 	}
 
-	return s.orderStore.GetLeastRecentlyAssignedChef(ctx, restaurantID, chefIDs)
+	return s.ticketStore.GetLeastRecentlyAssignedChef(ctx, req.RestaurantID, chefIDs)
 }
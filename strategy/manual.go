@@ -4,6 +4,6 @@ import "gofr.dev/pkg/gofr"
 
 type ManualStrategy struct{}
 
-func (s *ManualStrategy) Assign(_ *gofr.Context, _ int) (*int, error) {
+func (s *ManualStrategy) Assign(_ *gofr.Context, _ AssignRequest) (*int, error) {
 	return nil, nil
 }
@@ -3,21 +3,20 @@ package strategy
 import (
 	"fmt"
 	"math/rand"
-	"qr-dinein-backend/store"
 
 	"gofr.dev/pkg/gofr"
 )
 
 type RandomStrategy struct {
-	staffStore *store.Staff
+	chefs *ChefPool
 }
 
-func NewRandom(staffStore *store.Staff) *RandomStrategy {
-	return &RandomStrategy{staffStore: staffStore}
+func NewRandom(chefs *ChefPool) *RandomStrategy {
+	return &RandomStrategy{chefs: chefs}
 }
 
-func (s *RandomStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int, error) {
-	chefs, err := s.staffStore.GetActiveChefs(ctx, restaurantID)
+func (s *RandomStrategy) Assign(ctx *gofr.Context, req AssignRequest) (*int, error) {
+	chefs, err := s.chefs.Candidates(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active chefs: %w", err)
 	}
@@ -9,32 +9,49 @@ import (
 type Resolver struct {
 	settingsStore *store.Settings
 	staffStore    *store.Staff
-	orderStore    *store.Order
+	ticketStore   *store.Ticket
+	productStore  *store.Product
 }
 
-func NewResolver(settingsStore *store.Settings, staffStore *store.Staff, orderStore *store.Order) *Resolver {
+func NewResolver(settingsStore *store.Settings, staffStore *store.Staff, ticketStore *store.Ticket, productStore *store.Product) *Resolver {
 	return &Resolver{
 		settingsStore: settingsStore,
 		staffStore:    staffStore,
-		orderStore:    orderStore,
+		ticketStore:   ticketStore,
+		productStore:  productStore,
 	}
 }
 
+// ChefPool returns the pool of chefs the restaurant assigns from, honouring
+// its duty tracking setting
+func (r *Resolver) ChefPool(ctx *gofr.Context, restaurantID int) *ChefPool {
+	dutyTracking := false
+	if duty, err := r.settingsStore.GetByKey(ctx, restaurantID, "duty_tracking"); err == nil && duty.Value == "true" {
+		dutyTracking = true
+	}
+
+	return NewChefPool(r.staffStore, dutyTracking)
+}
+
 func (r *Resolver) Resolve(ctx *gofr.Context, restaurantID int) ChefAssigner {
 	setting, err := r.settingsStore.GetByKey(ctx, restaurantID, "chef_assignment_strategy")
 	if err != nil {
 		return &ManualStrategy{}
 	}
 
+	chefs := r.ChefPool(ctx, restaurantID)
+
 	switch setting.Value {
 	case StrategyRoundRobin:
-		return NewRoundRobin(r.staffStore)
+		return NewRoundRobin(chefs)
 	case StrategyLeastLoaded:
-		return NewLeastLoaded(r.staffStore, r.orderStore)
+		return NewLeastLoaded(chefs, r.ticketStore)
 	case StrategyRandom:
-		return NewRandom(r.staffStore)
+		return NewRandom(chefs)
 	case StrategyLeastRecentlyAssigned:
-		return NewLeastRecentlyAssigned(r.staffStore, r.orderStore)
+		return NewLeastRecentlyAssigned(chefs, r.ticketStore)
+	case StrategySkillCapacity:
+		return NewSkillCapacity(chefs, r.ticketStore, r.productStore)
 	default:
 		return &ManualStrategy{}
 	}
@@ -2,21 +2,20 @@ package strategy
 
 import (
 	"fmt"
-	"qr-dinein-backend/store"
 
 	"gofr.dev/pkg/gofr"
 )
 
 type RoundRobinStrategy struct {
-	staffStore *store.Staff
+	chefs *ChefPool
 }
 
-func NewRoundRobin(staffStore *store.Staff) *RoundRobinStrategy {
-	return &RoundRobinStrategy{staffStore: staffStore}
+func NewRoundRobin(chefs *ChefPool) *RoundRobinStrategy {
+	return &RoundRobinStrategy{chefs: chefs}
 }
 
-func (s *RoundRobinStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int, error) {
-	chefs, err := s.staffStore.GetActiveChefs(ctx, restaurantID)
+func (s *RoundRobinStrategy) Assign(ctx *gofr.Context, req AssignRequest) (*int, error) {
+	chefs, err := s.chefs.Candidates(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active chefs: %w", err)
 	}
@@ -25,7 +24,11 @@ func (s *RoundRobinStrategy) Assign(ctx *gofr.Context, restaurantID int) (*int,
 		return nil, nil
 	}
 
-	key := fmt.Sprintf("chef_rr:%d", restaurantID)
+	// Each station rotates through its own chefs
+	key := fmt.Sprintf("chef_rr:%d", req.RestaurantID)
+	if req.StationID != nil {
+		key = fmt.Sprintf("chef_rr:%d:%d", req.RestaurantID, *req.StationID)
+	}
 
 	counter, err := ctx.Redis.Incr(ctx, key).Result()
 	if err != nil {
@@ -0,0 +1,159 @@
+package strategy
+
+import (
+	"fmt"
+	"qr-dinein-backend/model"
+	"qr-dinein-backend/store"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const defaultPrepMinutes = 5
+
+// SkillCapacityStrategy assigns the ticket to the qualified chef with the
+// least queued work. A chef qualifies when they have every skill tag of the
+// ticket's products and are below their ticket limit; work is the summed
+// prep time of the unfinished items on their open tickets. The ticket is
+// left unassigned when no chef qualifies.
+type SkillCapacityStrategy struct {
+	chefs        *ChefPool
+	ticketStore  *store.Ticket
+	productStore *store.Product
+}
+
+func NewSkillCapacity(chefs *ChefPool, ticketStore *store.Ticket, productStore *store.Product) *SkillCapacityStrategy {
+	return &SkillCapacityStrategy{chefs: chefs, ticketStore: ticketStore, productStore: productStore}
+}
+
+func (s *SkillCapacityStrategy) Assign(ctx *gofr.Context, req AssignRequest) (*int, error) {
+	chefs, err := s.chefs.Candidates(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active chefs: %w", err)
+	}
+
+	if len(chefs) == 0 {
+		return nil, nil
+	}
+
+	required, err := s.requiredSkills(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.ticketStore.GetOpenAssigned(ctx, req.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open tickets: %w", err)
+	}
+
+	minutes, tickets, err := s.chefWork(ctx, req.RestaurantID, open)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *model.Staff
+	for i := range chefs {
+		chef := &chefs[i]
+
+		if !hasSkills(chef.Skills, required) {
+			continue
+		}
+
+		if chef.MaxTickets > 0 && tickets[chef.ID] >= chef.MaxTickets {
+			continue
+		}
+
+		// Least queued prep time wins; fewer open tickets breaks ties
+		if best == nil || minutes[chef.ID] < minutes[best.ID] ||
+			(minutes[chef.ID] == minutes[best.ID] && tickets[chef.ID] < tickets[best.ID]) {
+			best = chef
+		}
+	}
+
+	if best == nil {
+		return nil, nil
+	}
+
+	return &best.ID, nil
+}
+
+// requiredSkills collects the skill tags of every product on the ticket
+func (s *SkillCapacityStrategy) requiredSkills(ctx *gofr.Context, req AssignRequest) ([]string, error) {
+	productIDs := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	tags, err := s.productStore.GetSkillTags(ctx, req.RestaurantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product skill tags: %w", err)
+	}
+
+	var required []string
+	seen := make(map[string]bool)
+	for _, id := range productIDs {
+		for _, tag := range tags[id] {
+			if !seen[tag] {
+				required = append(required, tag)
+				seen[tag] = true
+			}
+		}
+	}
+
+	return required, nil
+}
+
+// chefWork returns each chef's queued prep minutes and open ticket count
+func (s *SkillCapacityStrategy) chefWork(ctx *gofr.Context, restaurantID int, open []model.Ticket) (map[int]int, map[int]int, error) {
+	var productIDs []int
+	seen := make(map[int]bool)
+	for _, t := range open {
+		for _, item := range t.Items {
+			if !seen[item.ProductID] {
+				productIDs = append(productIDs, item.ProductID)
+				seen[item.ProductID] = true
+			}
+		}
+	}
+
+	prepTimes, err := s.productStore.GetPrepTimes(ctx, restaurantID, productIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get prep times: %w", err)
+	}
+
+	minutes := make(map[int]int)
+	tickets := make(map[int]int)
+	for _, t := range open {
+		chefID := *t.AssignedChefID
+		tickets[chefID]++
+
+		for _, item := range t.Items {
+			if item.Status != model.ItemQueued && item.Status != model.ItemCooking {
+				continue
+			}
+
+			pt := defaultPrepMinutes
+			if p, ok := prepTimes[item.ProductID]; ok && p > 0 {
+				pt = p
+			}
+
+			minutes[chefID] += pt * item.Quantity
+		}
+	}
+
+	return minutes, tickets, nil
+}
+
+func hasSkills(skills, required []string) bool {
+	have := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		have[skill] = true
+	}
+
+	for _, tag := range required {
+		if !have[tag] {
+			return false
+		}
+	}
+
+	return true
+}